@@ -0,0 +1,172 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/midbel/sweet/internal/config"
+	"github.com/midbel/sweet/internal/lang"
+	"github.com/midbel/sweet/internal/lang/format"
+	"github.com/midbel/sweet/internal/lang/lint"
+)
+
+// settings resolves the configuration of a file: the .sweet.conf files of
+// its project, then the configuration file and the flags given on the command
+// line. The macros of the file are applied last by the formatter and the
+// linter.
+type settings struct {
+	finder config.Finder
+
+	file  string
+	extra *config.Config
+	flags *config.Config
+}
+
+// Load loads the configuration file given on the command line
+func (s *settings) Load(file string) error {
+	r, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	cfg, err := config.Load(r)
+	if err != nil {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+	s.file, s.extra = file, cfg
+	return nil
+}
+
+// Flags records the settings given by the flags set on the command line. The
+// names map the flags to the keys of the given section.
+func (s *settings) Flags(set *flag.FlagSet, section string, names map[string]string) {
+	sub := config.Make()
+	set.Visit(func(f *flag.Flag) {
+		key, ok := names[f.Name]
+		if !ok {
+			return
+		}
+		g, ok := f.Value.(flag.Getter)
+		if !ok {
+			return
+		}
+		switch v := g.Get().(type) {
+		case int:
+			sub.Set(key, int64(v))
+		default:
+			sub.Set(key, v)
+		}
+	})
+	if sub.Empty() {
+		return
+	}
+	s.flags = config.Make()
+	s.flags.Set(section, sub)
+}
+
+// Resolve merges into res the settings that apply to the given file
+func (s *settings) Resolve(file string, res *config.Resolved) error {
+	dir := "."
+	if file != stdinFile {
+		dir = filepath.Dir(file)
+	}
+	if err := s.finder.Find(dir, res); err != nil {
+		return err
+	}
+	if s.extra != nil {
+		res.Merge(s.extra, s.file)
+	}
+	if s.flags != nil {
+		res.Merge(s.flags, "command line")
+	}
+	return nil
+}
+
+func runConfig(args []string) error {
+	if len(args) == 0 || args[0] != "show" {
+		return fmt.Errorf("config: show expected")
+	}
+	var (
+		set     = flag.NewFlagSet("show", flag.ExitOnError)
+		dialect string
+		file    string
+		files   = addFileFlags(set)
+		opts    settings
+	)
+	set.StringVar(&dialect, "dialect", "", "SQL dialect (detected from input when not set)")
+	set.StringVar(&file, "config", "", "configuration file")
+	if err := set.Parse(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	if file != "" {
+		if err := opts.Load(file); err != nil {
+			return err
+		}
+	}
+	list, err := files.Files(set.Args())
+	if err != nil {
+		return err
+	}
+	for i, f := range list {
+		if i > 0 {
+			fmt.Println()
+		}
+		res, err := showSettings(&opts, f, dialect)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("# %s", fileName(f))
+		fmt.Println()
+		for _, s := range res.Settings() {
+			fmt.Printf("%s = %v (%s)", s.Key, s.Value, s.Source)
+			fmt.Println()
+		}
+	}
+	return nil
+}
+
+// showSettings gives the effective settings of a file: the default settings
+// of the formatter and of the linter, the settings resolved for the file and
+// the settings of its macros
+func showSettings(opts *settings, file, dialect string) (*config.Resolved, error) {
+	var (
+		res    = config.Resolve()
+		writer = format.NewWriter(os.Stdout)
+		linter = lint.NewLinter()
+	)
+	r, name, err := openDetect(file)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	if dialect != "" {
+		name = dialect
+	}
+	if err := linter.SetDialect(name); err != nil {
+		return nil, err
+	}
+	res.Merge(writer.Settings(), "default")
+	res.Merge(linter.Settings(), "default")
+	if err := opts.Resolve(file, res); err != nil {
+		return nil, err
+	}
+	d, err := lang.GetDialect(name)
+	if err != nil {
+		return nil, err
+	}
+	p, err := d.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	if c, ok := p.(lang.Configurable); ok {
+		res.Merge(c.Settings(), "macros of "+fileName(file))
+	}
+	return res, nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"path/filepath"
+	"testing"
+)
+
+func TestShowSettings(t *testing.T) {
+	chdirTemp(t)
+	writeFiles(t, map[string]string{
+		".sweet.conf":    "format {\nindent = 2\nquote = true\nas = true\n}\n",
+		"db/.sweet.conf": "format {\nindent = 4\n}\nlint {\nsafety = false\n}\n",
+		"extra.conf":     "format {\nquote = false\n}\n",
+		"db/query.sql":   "@format upperize keyword;\n@lint 'null' on;\nselect 1;\n",
+	})
+	var opts settings
+	if err := opts.Load("extra.conf"); err != nil {
+		t.Fatalf("fail to load configuration: %s", err)
+	}
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	set.Int("indent", 0, "")
+	if err := set.Parse([]string{"-indent", "6"}); err != nil {
+		t.Fatal(err)
+	}
+	opts.Flags(set, "format", map[string]string{"indent": "indent"})
+
+	file := filepath.Join("db", "query.sql")
+	res, err := showSettings(&opts, file, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	root, _ := filepath.Abs(".sweet.conf")
+	tests := []struct {
+		Key    string
+		Value  any
+		Source string
+	}{
+		{Key: "format.as", Value: true, Source: root},
+		{Key: "format.quote", Value: false, Source: "extra.conf"},
+		{Key: "format.indent", Value: int64(6), Source: "command line"},
+		{Key: "format.space", Value: true, Source: "default"},
+		{Key: "lint.safety", Value: false, Source: filepath.Join(filepath.Dir(root), "db", ".sweet.conf")},
+		{Key: "lint.null", Value: true, Source: "macros of " + file},
+		{Key: "format.upperize", Source: "macros of " + file},
+	}
+	for _, c := range tests {
+		var found bool
+		for _, s := range res.Settings() {
+			if s.Key != c.Key {
+				continue
+			}
+			found = true
+			if c.Value != nil && s.Value != c.Value {
+				t.Errorf("%s: value mismatched! want %v, got %v", c.Key, c.Value, s.Value)
+			}
+			if s.Source != c.Source {
+				t.Errorf("%s: source mismatched! want %s, got %s", c.Key, c.Source, s.Source)
+			}
+		}
+		if !found {
+			t.Errorf("%s: setting not found", c.Key)
+		}
+	}
+}
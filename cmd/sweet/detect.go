@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/midbel/sweet/internal/lang/detect"
+)
+
+func runDetect(args []string) error {
+	var (
+		set   = flag.NewFlagSet("detect", flag.ExitOnError)
+		all   bool
+		files = addFileFlags(set)
+	)
+	set.BoolVar(&all, "all", false, "show all candidate dialects")
+	if err := set.Parse(args); err != nil {
+		return err
+	}
+	run := func(f string) ([]detect.Guess, error) {
+		r, err := openFile(f)
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return detect.Detect(r)
+	}
+	names, err := files.Files(set.Args())
+	if err != nil {
+		return err
+	}
+	for _, f := range names {
+		list, err := run(f)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			continue
+		}
+		if !all {
+			list = list[:1]
+		}
+		for _, g := range list {
+			fmt.Printf("%s: %s (%.2f)", fileName(f), g.Dialect, g.Confidence)
+			if len(g.Signals) > 0 {
+				fmt.Printf(" %s", strings.Join(g.Signals, ", "))
+			}
+			fmt.Println()
+		}
+	}
+	return nil
+}
+
+// detectSize is the size of the start of a file used to detect its dialect
+const detectSize = 256 << 10
+
+// namedReader keeps the name of the file it is reading
+type namedReader struct {
+	io.Reader
+	name   string
+	closer io.Closer
+}
+
+func (r namedReader) Name() string {
+	return r.name
+}
+
+func (r namedReader) Close() error {
+	if r.closer == nil {
+		return nil
+	}
+	return r.closer.Close()
+}
+
+// openDetect opens the given file and gives the name of the dialect it is
+// most likely written in. Only the start of the file is used to detect the
+// dialect so that large files are not loaded in memory.
+func openDetect(file string) (io.ReadCloser, string, error) {
+	f, err := openFile(file)
+	if err != nil {
+		return nil, "", err
+	}
+	buf := make([]byte, detectSize)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		f.Close()
+		return nil, "", err
+	}
+	buf = buf[:n]
+	list := detect.DetectString(string(buf))
+	r := namedReader{
+		Reader: io.MultiReader(bytes.NewReader(buf), f),
+		name:   fileName(file),
+		closer: f,
+	}
+	return r, list[0].Dialect, nil
+}
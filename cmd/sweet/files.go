@@ -0,0 +1,338 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	// stdinFile is the argument used to read SQL from the standard input
+	stdinFile = "-"
+	stdinName = "<stdin>"
+
+	ignoreFile = ".sweetignore"
+)
+
+// sqlExtensions are the extensions of the files selected when walking a
+// directory or listing the files changed since a git revision
+var sqlExtensions = []string{".sql", ".ddl", ".pgsql"}
+
+// fileSet expands the arguments of a command into the list of files to process
+type fileSet struct {
+	since   string
+	ignores map[string][]ignoreRule
+}
+
+func addFileFlags(set *flag.FlagSet) *fileSet {
+	var files fileSet
+	set.StringVar(&files.since, "changed-since", "", "only process the files changed since the given git revision")
+	return &files
+}
+
+// Files gives the files to process from the arguments of a command. An
+// argument can be a file, a directory walked recursively, a glob pattern
+// where ** matches any number of directories or - for the standard input.
+// Files matched by a .sweetignore are skipped except when given explicitly.
+func (s *fileSet) Files(args []string) ([]string, error) {
+	var (
+		files []string
+		seen  = make(map[string]struct{})
+	)
+	add := func(file string) {
+		if _, ok := seen[file]; ok {
+			return
+		}
+		seen[file] = struct{}{}
+		files = append(files, file)
+	}
+	for _, a := range args {
+		list, err := s.expand(a)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range list {
+			add(f)
+		}
+	}
+	if s.since == "" {
+		return files, nil
+	}
+	changed, err := s.changedSince(s.since)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 {
+		return changed, nil
+	}
+	return slices.DeleteFunc(files, func(f string) bool {
+		return f != stdinFile && !slices.Contains(changed, f)
+	}), nil
+}
+
+func (s *fileSet) expand(arg string) ([]string, error) {
+	if arg == stdinFile {
+		return []string{arg}, nil
+	}
+	if isGlob(arg) {
+		return s.glob(arg)
+	}
+	i, err := os.Stat(arg)
+	if err != nil {
+		return nil, err
+	}
+	if !i.IsDir() {
+		return []string{filepath.Clean(arg)}, nil
+	}
+	return s.walk(arg, func(file string) bool {
+		return hasSqlExtension(file)
+	})
+}
+
+func (s *fileSet) glob(pattern string) ([]string, error) {
+	pattern = filepath.ToSlash(filepath.Clean(pattern))
+	var (
+		parts = strings.Split(pattern, "/")
+		root  []string
+	)
+	for len(parts) > 1 && !isGlob(parts[0]) {
+		root = append(root, parts[0])
+		parts = parts[1:]
+	}
+	dir := strings.Join(root, "/")
+	if len(root) == 0 {
+		dir = "."
+	} else if dir == "" {
+		dir = "/"
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("%s: %w", pattern, err)
+	}
+	list, err := s.walk(filepath.FromSlash(dir), func(file string) bool {
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return false
+		}
+		return matchPath(parts, strings.Split(filepath.ToSlash(rel), "/"))
+	})
+	if err == nil && len(list) == 0 {
+		err = fmt.Errorf("%s: no matching files", pattern)
+	}
+	return list, err
+}
+
+func (s *fileSet) walk(dir string, keep func(string) bool) ([]string, error) {
+	var list []string
+	err := filepath.WalkDir(dir, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if file != dir && s.Ignored(file, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() && keep(file) {
+			list = append(list, file)
+		}
+		return nil
+	})
+	return list, err
+}
+
+// changedSince gives the files with a SQL extension that have been added or
+// modified since the given revision. Only the local repository is used.
+func (s *fileSet) changedSince(rev string) ([]string, error) {
+	if strings.HasPrefix(rev, "-") {
+		return nil, fmt.Errorf("%s: invalid git revision", rev)
+	}
+	cmd := exec.Command("git", "diff", "--name-only", "--relative", "--diff-filter=d", rev, "--")
+	var errs bytes.Buffer
+	cmd.Stderr = &errs
+	out, err := cmd.Output()
+	if err != nil {
+		if msg, _, _ := strings.Cut(strings.TrimSpace(errs.String()), "\n"); msg != "" {
+			return nil, fmt.Errorf("git diff %s: %s", rev, msg)
+		}
+		return nil, fmt.Errorf("git diff %s: %w", rev, err)
+	}
+	var list []string
+	for _, f := range strings.Split(string(out), "\n") {
+		f = filepath.FromSlash(strings.TrimSpace(f))
+		if f == "" || !hasSqlExtension(f) || s.Ignored(f, false) {
+			continue
+		}
+		list = append(list, f)
+	}
+	return list, nil
+}
+
+// Ignored reports whether the given file is matched by one of the
+// .sweetignore files found in the current directory and in the directories
+// containing the file. As with git, the last matching rule wins and a file
+// inside an ignored directory is always ignored.
+func (s *fileSet) Ignored(file string, dir bool) bool {
+	rel, err := filepath.Rel(".", file)
+	if err != nil || filepath.IsAbs(rel) || strings.HasPrefix(rel, "..") {
+		return false
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	for i := 1; i < len(parts); i++ {
+		if s.match(parts[:i], true) {
+			return true
+		}
+	}
+	return s.match(parts, dir)
+}
+
+func (s *fileSet) match(parts []string, dir bool) bool {
+	var ignored bool
+	for i := range parts {
+		base := strings.Join(parts[:i], "/")
+		for _, r := range s.rulesOf(base) {
+			if r.Match(parts[i:], dir) {
+				ignored = !r.negate
+			}
+		}
+	}
+	return ignored
+}
+
+func (s *fileSet) rulesOf(dir string) []ignoreRule {
+	if rs, ok := s.ignores[dir]; ok {
+		return rs
+	}
+	if s.ignores == nil {
+		s.ignores = make(map[string][]ignoreRule)
+	}
+	rs, _ := loadIgnore(filepath.Join(filepath.FromSlash(dir), ignoreFile))
+	s.ignores[dir] = rs
+	return rs
+}
+
+// ignoreRule is a pattern of a .sweetignore file. Its syntax is the one of
+// gitignore: blank lines and lines starting with # are skipped, ! negates the
+// pattern, a trailing / only matches directories and a pattern containing a /
+// is relative to the directory of the file.
+type ignoreRule struct {
+	parts    []string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+func (r ignoreRule) Match(parts []string, dir bool) bool {
+	if r.dirOnly && !dir {
+		return false
+	}
+	if r.anchored {
+		return matchPath(r.parts, parts)
+	}
+	return matchPath(r.parts, parts[len(parts)-1:])
+}
+
+func loadIgnore(file string) ([]ignoreRule, error) {
+	r, err := os.Open(file)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			err = nil
+		}
+		return nil, err
+	}
+	defer r.Close()
+	return parseIgnore(r)
+}
+
+func parseIgnore(r io.Reader) ([]ignoreRule, error) {
+	var (
+		list []ignoreRule
+		scan = bufio.NewScanner(r)
+	)
+	for scan.Scan() {
+		line := strings.TrimRight(scan.Text(), " \t\r")
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		var rule ignoreRule
+		if line[0] == '!' {
+			rule.negate = true
+			line = line[1:]
+		} else if line[0] == '\\' {
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		rule.anchored = strings.Contains(line, "/")
+		line = strings.TrimPrefix(line, "/")
+		if line == "" {
+			continue
+		}
+		rule.parts = strings.Split(line, "/")
+		list = append(list, rule)
+	}
+	return list, scan.Err()
+}
+
+// matchPath matches a path split on / against a pattern split the same way.
+// A ** element matches zero or more elements of the path.
+func matchPath(pattern, parts []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := len(parts); i >= 0; i-- {
+				if matchPath(pattern[1:], parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], parts[0]); !ok {
+			return false
+		}
+		pattern, parts = pattern[1:], parts[1:]
+	}
+	return len(parts) == 0
+}
+
+func isGlob(str string) bool {
+	return strings.ContainsAny(str, "*?[")
+}
+
+func hasSqlExtension(file string) bool {
+	return slices.Contains(sqlExtensions, strings.ToLower(filepath.Ext(file)))
+}
+
+// fileName gives the name of a file as shown in the messages
+func fileName(file string) string {
+	if file == stdinFile {
+		return stdinName
+	}
+	return file
+}
+
+// openFile opens the given file or the standard input for -
+func openFile(file string) (io.ReadCloser, error) {
+	if file == stdinFile {
+		r := namedReader{
+			Reader: os.Stdin,
+			name:   stdinName,
+		}
+		return r, nil
+	}
+	return os.Open(file)
+}
@@ -0,0 +1,294 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseIgnore(t *testing.T) {
+	tests := []struct {
+		Line string
+		Want []ignoreRule
+	}{
+		{
+			Line: "",
+		},
+		{
+			Line: "# comment",
+		},
+		{
+			Line: "*.sql",
+			Want: []ignoreRule{{parts: []string{"*.sql"}}},
+		},
+		{
+			Line: "!keep.sql",
+			Want: []ignoreRule{{parts: []string{"keep.sql"}, negate: true}},
+		},
+		{
+			Line: "\\!name.sql",
+			Want: []ignoreRule{{parts: []string{"!name.sql"}}},
+		},
+		{
+			Line: "build/",
+			Want: []ignoreRule{{parts: []string{"build"}, dirOnly: true}},
+		},
+		{
+			Line: "/root.sql",
+			Want: []ignoreRule{{parts: []string{"root.sql"}, anchored: true}},
+		},
+		{
+			Line: "db/migrations/*.sql  ",
+			Want: []ignoreRule{{parts: []string{"db", "migrations", "*.sql"}, anchored: true}},
+		},
+		{
+			Line: "**/tmp/",
+			Want: []ignoreRule{{parts: []string{"**", "tmp"}, dirOnly: true, anchored: true}},
+		},
+	}
+	for _, c := range tests {
+		got, err := parseIgnore(strings.NewReader(c.Line))
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", c.Line, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, c.Want) {
+			t.Errorf("%q: rules mismatched! want %+v, got %+v", c.Line, c.Want, got)
+		}
+	}
+}
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		Pattern string
+		Path    string
+		Want    bool
+	}{
+		{Pattern: "*.sql", Path: "a.sql", Want: true},
+		{Pattern: "*.sql", Path: "db/a.sql", Want: false},
+		{Pattern: "db/*.sql", Path: "db/a.sql", Want: true},
+		{Pattern: "db/*.sql", Path: "db/sub/a.sql", Want: false},
+		{Pattern: "**/*.sql", Path: "a.sql", Want: true},
+		{Pattern: "**/*.sql", Path: "db/sub/a.sql", Want: true},
+		{Pattern: "db/**/*.sql", Path: "db/a.sql", Want: true},
+		{Pattern: "db/**/*.sql", Path: "db/x/y/a.sql", Want: true},
+		{Pattern: "db/**/*.sql", Path: "other/a.sql", Want: false},
+		{Pattern: "db/**", Path: "db/x/a.sql", Want: true},
+		{Pattern: "**/tmp/**", Path: "a/tmp/b/c.sql", Want: true},
+		{Pattern: "**/tmp/**", Path: "a/tmpx/c.sql", Want: false},
+		{Pattern: "q?.sql", Path: "q1.sql", Want: true},
+		{Pattern: "q[0-9].sql", Path: "qa.sql", Want: false},
+	}
+	for _, c := range tests {
+		got := matchPath(strings.Split(c.Pattern, "/"), strings.Split(c.Path, "/"))
+		if got != c.Want {
+			t.Errorf("%s - %s: match mismatched! want %t, got %t", c.Pattern, c.Path, c.Want, got)
+		}
+	}
+}
+
+func TestIgnored(t *testing.T) {
+	chdirTemp(t)
+	writeFiles(t, map[string]string{
+		".sweetignore":    "*.tmp.sql\n!keep.tmp.sql\nbuild/\n/root.sql\ndb/generated/*.sql\n",
+		"db/.sweetignore": "local.sql\n!/build/\n",
+	})
+	tests := []struct {
+		File string
+		Dir  bool
+		Want bool
+	}{
+		{File: "a.sql", Want: false},
+		{File: "a.tmp.sql", Want: true},
+		{File: "db/x/a.tmp.sql", Want: true},
+		{File: "keep.tmp.sql", Want: false},
+		{File: "db/keep.tmp.sql", Want: false},
+		{File: "build", Dir: true, Want: true},
+		{File: "build", Dir: false, Want: false},
+		{File: "build/a.sql", Want: true},
+		{File: "src/build/a.sql", Want: true},
+		{File: "root.sql", Want: true},
+		{File: "db/root.sql", Want: false},
+		{File: "db/generated/a.sql", Want: true},
+		{File: "db/generated/sub/a.sql", Want: false},
+		{File: "db/local.sql", Want: true},
+		{File: "db/sub/local.sql", Want: true},
+		{File: "local.sql", Want: false},
+		{File: "db/build", Dir: true, Want: false},
+		{File: "db/build/a.sql", Want: false},
+		{File: "../outside.sql", Want: false},
+	}
+	var files fileSet
+	for _, c := range tests {
+		if got := files.Ignored(filepath.FromSlash(c.File), c.Dir); got != c.Want {
+			t.Errorf("%s: ignored mismatched! want %t, got %t", c.File, c.Want, got)
+		}
+	}
+}
+
+func TestFiles(t *testing.T) {
+	chdirTemp(t)
+	writeFiles(t, map[string]string{
+		".sweetignore":       "skip/\n",
+		"a.sql":              "",
+		"notes.txt":          "",
+		"db/b.sql":           "",
+		"db/sub/c.ddl":       "",
+		"db/sub/d.sql":       "",
+		"skip/e.sql":         "",
+		"other/skip/f.sql":   "",
+		"other/g.pgsql":      "",
+		"other/explicit.txt": "",
+	})
+	tests := []struct {
+		Args []string
+		Want []string
+	}{
+		{
+			Args: []string{"."},
+			Want: []string{"a.sql", "db/b.sql", "db/sub/c.ddl", "db/sub/d.sql", "other/g.pgsql"},
+		},
+		{
+			Args: []string{"db/**/*.sql"},
+			Want: []string{"db/b.sql", "db/sub/d.sql"},
+		},
+		{
+			Args: []string{"**/*.sql"},
+			Want: []string{"a.sql", "db/b.sql", "db/sub/d.sql"},
+		},
+		{
+			Args: []string{"skip/e.sql", "other/explicit.txt", "a.sql", "-"},
+			Want: []string{"skip/e.sql", "other/explicit.txt", "a.sql", "-"},
+		},
+		{
+			Args: []string{"a.sql", "db", "a.sql"},
+			Want: []string{"a.sql", "db/b.sql", "db/sub/c.ddl", "db/sub/d.sql"},
+		},
+	}
+	for _, c := range tests {
+		var files fileSet
+		got, err := files.Files(c.Args)
+		if err != nil {
+			t.Errorf("%v: unexpected error: %s", c.Args, err)
+			continue
+		}
+		if want := fromSlash(c.Want); !slices.Equal(got, want) {
+			t.Errorf("%v: files mismatched! want %v, got %v", c.Args, want, got)
+		}
+	}
+	var files fileSet
+	if _, err := files.Files([]string{"missing.sql"}); err == nil {
+		t.Errorf("missing file should give an error")
+	}
+	if _, err := files.Files([]string{"**/*.none"}); err == nil {
+		t.Errorf("glob without match should give an error")
+	}
+}
+
+func TestChangedSince(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	chdirTemp(t)
+	writeFiles(t, map[string]string{
+		".sweetignore": "ignored.sql\n",
+		"a.sql":        "select 1;",
+		"b.sql":        "select 2;",
+		"db/c.sql":     "select 3;",
+		"ignored.sql":  "select 4;",
+		"notes.txt":    "",
+	})
+	git(t, "init", "-q")
+	git(t, "add", ".")
+	git(t, "commit", "-q", "-m", "initial")
+	writeFiles(t, map[string]string{
+		"a.sql":       "select 10;",
+		"db/c.sql":    "select 30;",
+		"db/new.sql":  "select 50;",
+		"ignored.sql": "select 40;",
+		"notes.txt":   "changed",
+	})
+	git(t, "rm", "-q", "b.sql")
+	git(t, "add", "db/new.sql")
+
+	tests := []struct {
+		Args []string
+		Want []string
+	}{
+		{
+			Want: []string{"a.sql", "db/c.sql", "db/new.sql"},
+		},
+		{
+			Args: []string{"db"},
+			Want: []string{"db/c.sql", "db/new.sql"},
+		},
+		{
+			Args: []string{"-", "a.sql", "ignored.sql"},
+			Want: []string{"-", "a.sql"},
+		},
+	}
+	for _, c := range tests {
+		files := fileSet{since: "HEAD"}
+		got, err := files.Files(c.Args)
+		if err != nil {
+			t.Errorf("%v: unexpected error: %s", c.Args, err)
+			continue
+		}
+		if want := fromSlash(c.Want); !slices.Equal(got, want) {
+			t.Errorf("%v: files mismatched! want %v, got %v", c.Args, want, got)
+		}
+	}
+	files := fileSet{since: "--output=x"}
+	if _, err := files.Files(nil); err == nil {
+		t.Errorf("revision starting with - should be rejected")
+	}
+}
+
+// chdirTemp moves to a temporary directory for the time of the test since the
+// .sweetignore files are looked up from the current directory
+func chdirTemp(t *testing.T) {
+	t.Helper()
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		os.Chdir(cwd)
+	})
+}
+
+func writeFiles(t *testing.T, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		name = filepath.FromSlash(name)
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func git(t *testing.T, args ...string) {
+	t.Helper()
+	args = append([]string{"-c", "user.name=sweet", "-c", "user.email=sweet@example.com"}, args...)
+	if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+		t.Fatalf("git %s: %s\n%s", strings.Join(args, " "), err, out)
+	}
+}
+
+func fromSlash(list []string) []string {
+	var res []string
+	for _, f := range list {
+		res = append(res, filepath.FromSlash(f))
+	}
+	return res
+}
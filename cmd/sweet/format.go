@@ -3,22 +3,36 @@ package main
 import (
 	"errors"
 	"flag"
-	"fmt"
+	"io"
 	"os"
-	"strings"
 
 	"github.com/midbel/sweet/internal/config"
-	"github.com/midbel/sweet/internal/lang"
+	"github.com/midbel/sweet/internal/lang/ast"
 	"github.com/midbel/sweet/internal/lang/format"
-	"github.com/midbel/sweet/internal/ms"
-	"github.com/midbel/sweet/internal/my"
-	// "github.com/midbel/sweet/internal/db2"
 )
 
+// formatFlags maps the flags of the format command to the settings of the
+// format section they override
+var formatFlags = map[string]string{
+	"compact":       "compact",
+	"use-as":        "as",
+	"use-quote":     "quote",
+	"use-indent":    "indent",
+	"use-space":     "space",
+	"use-crlf":      "crlf",
+	"prepend-comma": "comma",
+	"keep-comment":  "comment",
+}
+
 func runFormat(args []string) error {
 	var (
-		set    = flag.NewFlagSet("format", flag.ExitOnError)
-		writer = format.NewWriter(os.Stdout)
+		set     = flag.NewFlagSet("format", flag.ExitOnError)
+		writer  = format.NewWriter(os.Stdout)
+		dialect string
+		decode  bool
+		jobs    int
+		files   = addFileFlags(set)
+		opts    settings
 	)
 	set.BoolVar(&writer.Compact, "compact", writer.Compact, "produces compact SQL queries")
 	set.BoolVar(&writer.UseAs, "use-as", writer.UseAs, "always use as to define alias")
@@ -30,16 +44,12 @@ func runFormat(args []string) error {
 	set.BoolVar(&writer.PrependComma, "prepend-comma", writer.PrependComma, "write comma before expressions")
 	set.BoolVar(&writer.KeepComment, "keep-comment", writer.KeepComment, "keep comments")
 
-	set.Func("dialect", "SQL dialect", func(value string) error {
-		formatter, err := getFormatterForDialect(value)
-		if err == nil {
-			writer.Formatter = formatter
-		}
-		return err
-	})
+	set.StringVar(&dialect, "dialect", "", "SQL dialect (detected from input when not set)")
+	set.BoolVar(&decode, "from-json", false, "read statements from a JSON document produced by parse -json")
+	set.IntVar(&jobs, "j", 0, "number of files formatted in parallel (GOMAXPROCS by default)")
 	set.Func("rewrite", "rewrite rules to apply", rewriteRules(writer))
 	set.Func("upper", "upperize mode", upperizeRules(writer))
-	set.Func("config", "formatter configuration file", configureRules(writer))
+	set.Func("config", "formatter configuration file", opts.Load)
 
 	if err := set.Parse(args); err != nil {
 		if errors.Is(err, flag.ErrHelp) {
@@ -47,102 +57,79 @@ func runFormat(args []string) error {
 		}
 		return err
 	}
-	process := func(file string) error {
-		r, err := os.Open(file)
-		if err != nil {
+	opts.Flags(set, "format", formatFlags)
+	if dialect != "" {
+		if err := writer.SetDialect(dialect); err != nil {
 			return err
 		}
-		defer r.Close()
-		return writer.Format(r)
-	}
-	for _, f := range set.Args() {
-		if err := process(f); err != nil {
-			fmt.Fprintln(os.Stderr, err)
-		}
 	}
-	return nil
-}
-
-func configureRules(writer *format.Writer) func(string) error {
-	var (
-		rewrite  = rewriteRules(writer)
-		upperize = upperizeRules(writer)
-		setComma = func(value any) error {
-			switch value.(string) {
-			case "before", "prepend":
-				writer.PrependComma = true
-			case "after", "":
-			default:
-			}
-			return nil
+	process := func(file string, out, _ io.Writer) error {
+		writer := writer.Clone(out)
+		res := config.Resolve()
+		if err := opts.Resolve(file, res); err != nil {
+			return err
 		}
-		setCrlf = func(value any) error {
-			switch value.(string) {
-			case "crlf":
-				writer.UseCrlf = true
-			case "nl", "lf", "":
-			default:
-			}
-			return nil
+		writer.Configure(res.Config)
+		if decode {
+			return formatJSON(writer, file)
 		}
-		setComment = func(value any) error {
-			switch value.(string) {
-			case "keep":
-				writer.KeepComment = true
-			case "discard", "":
-			default:
+		if dialect != "" {
+			r, err := openFile(file)
+			if err != nil {
+				return err
 			}
-			return nil
+			defer r.Close()
+			return writer.Format(r)
 		}
-	)
-	return func(file string) error {
-		r, err := os.Open(file)
+		r, name, err := openDetect(file)
 		if err != nil {
 			return err
 		}
-		cfg, err := config.Load(r)
-		if err != nil {
+		defer r.Close()
+		if err := writer.SetDialect(name); err != nil {
 			return err
 		}
-		cfg = cfg.Sub("format")
-		var (
-			syntax = cfg.Sub("syntax")
-			indent = cfg.Sub("indent")
-		)
-		writer.Compact = cfg.GetBool("compact")
-		writer.UseQuote = syntax.GetBool("quote")
-		writer.UseAs = syntax.GetBool("as")
-		writer.UseIndent = int(indent.GetInt("count"))
-		writer.UseSpace = indent.GetBool("space")
-		cfg.Apply("comma", setComma)
-		cfg.Apply("comment", setComment)
-		cfg.Apply("newline", setCrlf)
-		for _, r := range cfg.GetStrings("upperize") {
-			upperize(strings.ReplaceAll(r, "_", "-"))
-		}
-		for _, r := range cfg.GetStrings("rewrite") {
-			rewrite(strings.ReplaceAll(r, "_", "-"))
+		return writer.Format(r)
+	}
+	list, err := files.Files(set.Args())
+	if err != nil {
+		return err
+	}
+	return processFiles(list, jobs, process)
+}
+
+func formatJSON(writer *format.Writer, file string) error {
+	r, err := openFile(file)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	list, err := ast.Decode(r)
+	if err != nil {
+		return err
+	}
+	for i := range list {
+		if err := writer.WriteStatement(list[i]); err != nil {
+			return err
 		}
-		return nil
 	}
+	return nil
 }
 
 func upperizeRules(writer *format.Writer) func(string) error {
 	return func(value string) error {
-		switch value {
-		case "all", "":
-			writer.Upperize |= format.UpperId | format.UpperKw | format.UpperFn | format.UpperType
-		case "keyword", "kw":
-			writer.Upperize |= format.UpperKw
-		case "function", "fn":
-			writer.Upperize |= format.UpperFn
-		case "identifier", "ident", "id":
-			writer.Upperize |= format.UpperId
-		case "type":
-			writer.Upperize |= format.UpperType
-		case "none":
-			writer.Upperize = format.UpperNone
-		default:
+		if value == "" {
+			value = "all"
+		}
+		mode, ok := format.GetUpperMode(value)
+		if !ok {
+			return nil
+		}
+		if mode == format.UpperNone {
+			writer.Upperize = mode
+		} else {
+			writer.Upperize |= mode
 		}
 		return nil
 	}
@@ -150,46 +137,12 @@ func upperizeRules(writer *format.Writer) func(string) error {
 
 func rewriteRules(writer *format.Writer) func(string) error {
 	return func(value string) error {
-		switch value {
-		case "all", "":
-			writer.Rules |= format.RewriteAll
-		case "use-std-op":
-			writer.Rules |= format.RewriteStdOp
-		case "use-std-expr":
-			writer.Rules |= format.RewriteStdExpr
-		case "missing-cte-alias":
-			writer.Rules |= format.RewriteMissCteAlias
-		case "missing-view-alias":
-			writer.Rules |= format.RewriteMissViewAlias
-		case "subquery-as-cte":
-			writer.Rules |= format.RewriteWithCte
-		case "cte-as-subquery":
-			writer.Rules |= format.RewriteWithSubqueries
-		case "join-as-subquery":
-			writer.Rules |= format.RewriteJoinSubquery
-		case "join-without-literal":
-			writer.Rules |= format.RewriteJoinPredicate
-		case "groupby-group":
-			writer.Rules |= format.RewriteGroupByGroup
-		case "groupby-aggr":
-			writer.Rules |= format.RewriteGroupByAggr
-		default:
+		if value == "" {
+			value = "all"
+		}
+		if rule, ok := format.GetRewriteRule(value); ok {
+			writer.Rules |= rule
 		}
 		return nil
 	}
 }
-
-func getFormatterForDialect(name string) (lang.Formatter, error) {
-	switch name {
-	case "my", "mysql":
-		return my.GetFormatter(), nil
-	case "mssql":
-		return ms.GetFormatter(), nil
-	case "ansi", "pg", "postgres", "sqlite", "lite":
-		return format.GetFormatter(), nil
-	case "db2":
-		return format.GetFormatter(), nil
-	default:
-		return nil, fmt.Errorf("%s unsupported dialect", name)
-	}
-}
@@ -4,8 +4,12 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
+	"sync/atomic"
 
+	"github.com/midbel/sweet/internal/config"
 	"github.com/midbel/sweet/internal/lang/lint"
 	"github.com/midbel/sweet/internal/rules"
 )
@@ -16,42 +20,168 @@ func runLint(args []string) error {
 		linter   = lint.NewLinter()
 		showList bool
 		dialect  string
-		config   string
+		baseline string
+		update   bool
+		format   string
+		minLevel string
+		failOn   string
+		jobs     int
+		files    = addFileFlags(set)
+		opts     settings
 	)
-	set.StringVar(&config, "config", "", "linter configuration")
-	set.StringVar(&dialect, "dialect", "", "SQL dialect")
+	set.Func("config", "linter configuration file", opts.Load)
+	set.StringVar(&dialect, "dialect", "", "SQL dialect (detected from input when not set)")
 	set.BoolVar(&showList, "list", false, "show list of supported rules")
+	set.BoolVar(&linter.SkipRows, "skip-rows", false, "do not parse the rows given to INSERT statements")
+	set.StringVar(&linter.Env, "env", os.Getenv("SWEET_ENV"), "environment whose lint settings apply after the default ones")
+	set.StringVar(&baseline, "baseline", "", "file with the known messages: only the new ones are reported (recorded when the file does not exist)")
+	set.BoolVar(&update, "update-baseline", false, "record the current messages in the baseline file")
+	set.StringVar(&format, "format", rules.FormatText, "output format (text, json, sarif, checkstyle, junit, github)")
+	set.StringVar(&minLevel, "min-level", "info", "lowest level of the messages reported (debug, info, warning, error)")
+	set.StringVar(&failOn, "fail-on", "", "exit with status 1 when a message of this level or above is reported")
+	set.IntVar(&linter.Max, "max", 0, "maximum number of messages reported by file (0 for no limit)")
+	set.BoolVar(&linter.AbortOnErr, "abort", false, "stop linting a file at its first syntax error")
+	set.IntVar(&jobs, "j", 0, "number of files linted in parallel (GOMAXPROCS by default)")
 	if err := set.Parse(args); err != nil {
 		if errors.Is(err, flag.ErrHelp) {
 
 		}
 		return err
 	}
+	if err := rules.Render(io.Discard, format, nil); err != nil {
+		return exitError{code: exitFailure, err: err}
+	}
+	if linter.MinLevel = rules.GetLevelFromName(minLevel); linter.MinLevel == rules.Unknown {
+		return exitError{code: exitFailure, err: fmt.Errorf("unknown level %q", minLevel)}
+	}
+	threshold := rules.GetLevelFromName(failOn)
+	if threshold == rules.Unknown {
+		return exitError{code: exitFailure, err: fmt.Errorf("unknown level %q", failOn)}
+	}
 
+	if err := linter.SetDialect(dialect); err != nil {
+		return exitError{code: exitFailure, err: err}
+	}
 	if showList {
 		printRules(linter.Rules())
 		return nil
 	}
+	base, record, err := loadBaseline(baseline, update)
+	if err != nil {
+		return exitError{code: exitFailure, err: err}
+	}
 
-	process := func(file string) ([]rules.LintMessage, error) {
-		r, err := os.Open(file)
+	lintFile := func(linter *lint.Linter, file string, cfg *config.Config) ([]rules.LintMessage, error) {
+		var (
+			r    io.ReadCloser
+			name string
+			err  error
+		)
+		if dialect != "" {
+			r, err = openFile(file)
+		} else {
+			r, name, err = openDetect(file)
+		}
 		if err != nil {
 			return nil, err
 		}
+		defer r.Close()
+		if name != "" {
+			if err := linter.SetDialect(name); err != nil {
+				return nil, err
+			}
+		}
+		if err := linter.Configure(cfg); err != nil {
+			return nil, err
+		}
 		return linter.Lint(r)
 	}
-	for _, f := range set.Args() {
-		list, err := process(f)
-		if err != nil {
-			fmt.Fprintln(os.Stderr, err)
-			continue
+	var (
+		reports []rules.Report
+		index   = make(map[string]int)
+		failed  atomic.Int64
+	)
+	process := func(file string, out, _ io.Writer) error {
+		res := config.Resolve()
+		if err := opts.Resolve(file, res); err != nil {
+			return err
+		}
+		list, err := lintFile(linter.Clone(), file, res.Config)
+		for i := range list {
+			if list[i].File == "" {
+				list[i].File = fileName(file)
+			}
+		}
+		if record {
+			base.Record(list)
+			return err
+		}
+		if base != nil {
+			list = base.Filter(list)
+		}
+		if threshold != rules.Default {
+			for _, m := range list {
+				if m.Severity >= threshold {
+					failed.Add(1)
+				}
+			}
 		}
-		for _, m := range list {
-			fmt.Fprintf(os.Stdout, "%s (%s): %s", m.Rule, m.Severity, m.Message)
-			fmt.Fprintln(os.Stdout)
+		rp := rules.Report{
+			File:     fileName(file),
+			Messages: list,
 		}
+		if !rules.Streaming(format) {
+			reports[index[file]] = rp
+			return err
+		}
+		if e := rules.Render(out, format, []rules.Report{rp}); e != nil {
+			return errors.Join(err, e)
+		}
+		return err
+	}
+	list, err := files.Files(set.Args())
+	if err != nil {
+		return exitError{code: exitFailure, err: err}
+	}
+	reports = make([]rules.Report, len(list))
+	for i, f := range list {
+		index[f] = i
+		reports[i].File = fileName(f)
+	}
+	if err = processFiles(list, jobs, process); err != nil {
+		err = exitError{code: exitFailure, err: err}
+	}
+	if record {
+		return errors.Join(err, base.Save(baseline))
+	}
+	if !rules.Streaming(format) {
+		if e := rules.Render(os.Stdout, format, reports); e != nil {
+			return errors.Join(err, e)
+		}
+	}
+	if err == nil && failed.Load() > 0 {
+		err = exitError{
+			code: exitFindings,
+			err:  fmt.Errorf("%d message(s) of level %s or above", failed.Load(), threshold),
+		}
+	}
+	return err
+}
+
+// loadBaseline gives the baseline saved in file and whether the messages have
+// to be recorded in it instead of being reported
+func loadBaseline(file string, update bool) (*rules.Baseline, bool, error) {
+	if file == "" {
+		return nil, false, nil
+	}
+	if update {
+		return rules.NewBaseline(), true, nil
+	}
+	base, err := rules.LoadBaseline(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return rules.NewBaseline(), true, nil
 	}
-	return nil
+	return base, false, err
 }
 
 func printRules(infos []rules.LintInfo) {
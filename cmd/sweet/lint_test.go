@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestLintExitCode(t *testing.T) {
+	chdirTemp(t)
+	writeFiles(t, map[string]string{
+		"query.sql": "delete from employees;\n",
+		"bad.json":  "{",
+	})
+	tests := []struct {
+		Args []string
+		Code int
+	}{
+		{
+			Args: []string{"query.sql"},
+			Code: 0,
+		},
+		{
+			Args: []string{"-fail-on", "error", "query.sql"},
+			Code: exitFindings,
+		},
+		{
+			Args: []string{"missing.sql"},
+			Code: exitFailure,
+		},
+		{
+			Args: []string{"-format", "xml", "query.sql"},
+			Code: exitFailure,
+		},
+		{
+			Args: []string{"-min-level", "none", "query.sql"},
+			Code: exitFailure,
+		},
+		{
+			Args: []string{"-fail-on", "none", "query.sql"},
+			Code: exitFailure,
+		},
+		{
+			Args: []string{"-baseline", "bad.json", "query.sql"},
+			Code: exitFailure,
+		},
+		{
+			Args: []string{"-dialect", "none", "query.sql"},
+			Code: exitFailure,
+		},
+	}
+	stdout := os.Stdout
+	defer func() {
+		os.Stdout = stdout
+	}()
+	os.Stdout, _ = os.Open(os.DevNull)
+
+	for _, c := range tests {
+		var (
+			err  = runLint(c.Args)
+			code int
+			exit exitError
+		)
+		if errors.As(err, &exit) {
+			code = exit.code
+		} else if err != nil {
+			t.Errorf("%q: unexpected error: %s", c.Args, err)
+			continue
+		}
+		if code != c.Code {
+			t.Errorf("%q: exit code mismatched! want %d, got %d (%v)", c.Args, c.Code, code, err)
+		}
+	}
+}
@@ -7,11 +7,20 @@ import (
 	"io"
 	"os"
 
+	_ "github.com/midbel/sweet/internal/db2"
+	"github.com/midbel/sweet/internal/diag"
+	"github.com/midbel/sweet/internal/lang"
 	"github.com/midbel/sweet/internal/lang/complexity"
 	"github.com/midbel/sweet/internal/lang/parser"
+	_ "github.com/midbel/sweet/internal/ms"
+	_ "github.com/midbel/sweet/internal/my"
+	_ "github.com/midbel/sweet/internal/ora"
 )
 
+var diagFormat = diag.FormatText
+
 func main() {
+	flag.StringVar(&diagFormat, "diagnostics", diagFormat, "format of diagnostics (text, json)")
 	flag.Parse()
 
 	var (
@@ -31,41 +40,102 @@ func main() {
 		cmd = runDebug
 	case "cyclo", "measure":
 		cmd = runCyclo
+	case "detect", "sniff":
+		cmd = runDetect
+	case "config":
+		cmd = runConfig
 	default:
 		err = fmt.Errorf("unknown command %s", n)
 	}
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
+		os.Exit(exitFailure)
 	}
 	args := flag.Args()
 	if err = cmd(args[1:]); err != nil {
 		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		code := exitFailure
+		var exit exitError
+		if errors.As(err, &exit) {
+			code = exit.code
+		}
+		os.Exit(code)
 	}
 }
 
-func runCyclo(files []string) error {
-	run := func(f string) (int, error) {
-		r, err := os.Open(f)
+const (
+	exitFindings = 1
+	exitFailure  = 2
+)
+
+// exitError is an error ending the program with a specific status code
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e exitError) Unwrap() error {
+	return e.err
+}
+
+func runCyclo(args []string) error {
+	var (
+		set     = flag.NewFlagSet("cyclo", flag.ExitOnError)
+		dialect string
+		each    bool
+		jobs    int
+		files   = addFileFlags(set)
+	)
+	set.StringVar(&dialect, "dialect", "", "SQL dialect")
+	set.BoolVar(&each, "statements", false, "report complexity of each statement")
+	set.IntVar(&jobs, "j", 0, "number of files measured in parallel (GOMAXPROCS by default)")
+	if err := set.Parse(args); err != nil {
+		return err
+	}
+	run := func(f string) ([]complexity.Measure, error) {
+		r, err := openFile(f)
 		if err != nil {
-			return 0, err
+			return nil, err
 		}
 		defer r.Close()
-		return complexity.Complexity(r)
+		return complexity.Measures(r, dialect)
 	}
-	for _, f := range files {
-		n, err := run(f)
-		if err != nil {
+	process := func(file string, out, _ io.Writer) error {
+		list, err := run(file)
+		if err != nil && !errors.As(err, new(lang.ParseErrors)) {
 			return err
 		}
-		fmt.Printf("%s: %d", f, n)
-		fmt.Println()
+		var total int
+		for _, m := range list {
+			total += m.Score
+			if !each {
+				continue
+			}
+			fmt.Fprintf(out, "%s: %d", m.Pos(), m.Score)
+			fmt.Fprintln(out)
+		}
+		fmt.Fprintf(out, "%s: %d", fileName(file), total)
+		fmt.Fprintln(out)
+		return err
 	}
-	return nil
+	list, err := files.Files(set.Args())
+	if err != nil {
+		return err
+	}
+	return processFiles(list, jobs, process)
 }
 
-func runDebug(files []string) error {
-	for _, f := range files {
+func runDebug(args []string) error {
+	var files fileSet
+	list, err := files.Files(args)
+	if err != nil {
+		return err
+	}
+	for _, f := range list {
 		if err := printTree(f); err != nil {
 			return err
 		}
@@ -74,7 +144,7 @@ func runDebug(files []string) error {
 }
 
 func printTree(file string) error {
-	r, err := os.Open(file)
+	r, err := openFile(file)
 	if err != nil {
 		return err
 	}
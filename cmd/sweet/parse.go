@@ -6,11 +6,10 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"strings"
 
+	"github.com/midbel/sweet/internal/diag"
 	"github.com/midbel/sweet/internal/lang"
-	"github.com/midbel/sweet/internal/lang/parser"
-	"github.com/midbel/sweet/internal/scanner"
+	"github.com/midbel/sweet/internal/lang/ast"
 	"github.com/midbel/sweet/internal/token"
 )
 
@@ -18,78 +17,103 @@ func runParse(args []string) error {
 	var (
 		set     = flag.NewFlagSet("parse", flag.ExitOnError)
 		dialect string
+		asJson  bool
+		recover bool
+		files   = addFileFlags(set)
 	)
 	set.StringVar(&dialect, "dialect", "", "SQL dialect")
+	set.BoolVar(&asJson, "json", false, "write the parsed statements as JSON")
+	set.BoolVar(&recover, "recover", false, "keep parsing after syntax errors and give the statements with their invalid parts")
 	if err := set.Parse(args); err != nil {
 		if errors.Is(err, flag.ErrHelp) {
 			return nil
 		}
 		return err
 	}
-	r, err := os.Open(set.Arg(0))
+	d, err := lang.GetDialect(dialect)
 	if err != nil {
 		return err
 	}
+	all, err := files.Files(set.Args())
+	if err != nil {
+		return err
+	}
+	var list []ast.Statement
+	for _, f := range all {
+		stmts, err := parseFile(d, f, !asJson, recover)
+		if err != nil {
+			return err
+		}
+		list = append(list, stmts...)
+	}
+	if asJson {
+		return ast.Encode(os.Stdout, list)
+	}
+	return nil
+}
+
+// parseFile parses the given file. The statements are printed as they are
+// parsed when print is set, otherwise they are returned. In recovery mode, the
+// statements with syntax errors are given as ast.Invalid and their errors are
+// reported once the file has been parsed.
+func parseFile(d lang.Dialect, file string, print, recover bool) ([]ast.Statement, error) {
+	r, err := openFile(file)
+	if err != nil {
+		return nil, err
+	}
 	defer r.Close()
 
-	ps, err := parser.NewParser(r)
+	ps, err := d.Parse(r)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	out := os.Stderr
+	if print {
+		out = os.Stdout
 	}
+	rp, ok := ps.(lang.Recoverable)
+	if recover = recover && ok; recover {
+		rp.SetRecover(true)
+	}
+	var list []ast.Statement
 	for {
 		stmt, err := ps.Parse()
 		if errors.Is(err, io.EOF) {
 			break
 		}
 		if err != nil {
-			reportError(err)
+			var perr lang.ParseError
+			if !errors.As(err, &perr) {
+				return nil, err
+			}
+			reportError(out, fileName(file), err)
+			continue
+		}
+		if !print {
+			list = append(list, stmt)
 			continue
 		}
 		fmt.Printf("%+v\n", stmt)
 	}
-	return nil
-}
-
-func reportError(err error) {
-	var pserr parser.ParseError
-	if !errors.As(err, &pserr) {
-		fmt.Println(err)
-		return
-	}
-	var (
-		parts = strings.Split(pserr.Query, "\n")
-		pos   = pserr.Position()
-		first = pos.Line - 3
-	)
-	if pos.Line < len(parts) {
-		parts = parts[:pos.Line]
-	}
-
-	for i := range parts {
-		var (
-			lino = pos.Line - len(parts) + i + 1
-			line = strings.TrimSpace(parts[i])
-		)
-		if lino < first {
-			continue
+	if recover {
+		if errs := rp.Errors(); len(errs) > 0 {
+			reportError(out, fileName(file), lang.ParseErrors(errs))
 		}
-		fmt.Printf("%03d | %s", lino, line)
-		fmt.Println()
 	}
-	fmt.Print(strings.Repeat(" ", 6+pos.Column-1))
-	if str := pserr.Literal(); len(str) > 0 {
-		fmt.Println(strings.Repeat("^", len(str)))
-	} else {
-		fmt.Println("^")
+	return list, nil
+}
+
+func reportError(w io.Writer, file string, err error) {
+	if err := diag.Render(w, diagFormat, diag.FromError(file, err)); err != nil {
+		fmt.Fprintln(os.Stderr, err)
 	}
-	fmt.Println(pserr)
-	fmt.Println()
 }
 
 func runScan(args []string) error {
 	var (
 		set     = flag.NewFlagSet("scan", flag.ExitOnError)
 		dialect string
+		files   = addFileFlags(set)
 	)
 	set.StringVar(&dialect, "dialect", "", "SQL dialect")
 	if err := set.Parse(args); err != nil {
@@ -98,13 +122,30 @@ func runScan(args []string) error {
 		}
 		return err
 	}
-	r, err := os.Open(set.Arg(0))
+	d, err := lang.GetDialect(dialect)
+	if err != nil {
+		return err
+	}
+	list, err := files.Files(set.Args())
+	if err != nil {
+		return err
+	}
+	for _, f := range list {
+		if err := scanFile(d, f, len(list) > 1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func scanFile(d lang.Dialect, file string, withName bool) error {
+	r, err := openFile(file)
 	if err != nil {
 		return err
 	}
 	defer r.Close()
 
-	scan, err := scanner.Scan(r, lang.GetKeywords())
+	scan, err := d.Scan(r)
 	if err != nil {
 		return err
 	}
@@ -114,8 +155,11 @@ func runScan(args []string) error {
 			break
 		}
 		pos := tok.Position
+		if withName {
+			fmt.Printf("%s:", fileName(file))
+		}
 		fmt.Printf("%d:%d, %s", pos.Line, pos.Column, tok)
 		fmt.Println()
 	}
-	return nil
+	return scan.Err()
 }
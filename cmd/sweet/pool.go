@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sync"
+)
+
+// fileFunc processes a single file writing its results to out and its
+// problems to errs
+type fileFunc func(file string, out, errs io.Writer) error
+
+type result struct {
+	out  bytes.Buffer
+	errs bytes.Buffer
+	err  error
+}
+
+// processFiles runs fn on every file with a pool of n workers (GOMAXPROCS when
+// n is not positive). The outputs of the files are written in the order the
+// files are given, whatever the order in which they are processed. A failing
+// file does not stop the processing of the others: the errors are reported
+// and counted in the returned error.
+func processFiles(files []string, n int, fn fileFunc) error {
+	return processFilesTo(os.Stdout, os.Stderr, files, n, fn)
+}
+
+// processFilesTo is processFiles writing the outputs of the files to stdout
+// and their problems to stderr
+func processFilesTo(stdout, stderr io.Writer, files []string, n int, fn fileFunc) error {
+	if n <= 0 {
+		n = runtime.GOMAXPROCS(0)
+	}
+	var (
+		queue   = make(chan int)
+		results = make([]chan *result, len(files))
+		wg      sync.WaitGroup
+	)
+	for i := range results {
+		results[i] = make(chan *result, 1)
+	}
+	for j := 0; j < min(n, len(files)); j++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				var res result
+				res.err = fn(files[i], &res.out, &res.errs)
+				results[i] <- &res
+			}
+		}()
+	}
+	go func() {
+		defer close(queue)
+		for i := range files {
+			queue <- i
+		}
+	}()
+
+	var failed int
+	for i := range results {
+		res := <-results[i]
+		io.Copy(stdout, &res.out)
+		io.Copy(stderr, &res.errs)
+		if res.err != nil {
+			reportError(stderr, fileName(files[i]), res.err)
+			failed++
+		}
+	}
+	wg.Wait()
+	if failed > 0 {
+		return fmt.Errorf("%d/%d file(s) failed", failed, len(files))
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/midbel/sweet/internal/lang/format"
+)
+
+func TestProcessFiles(t *testing.T) {
+	var (
+		dir    = t.TempDir()
+		files  []string
+		index  = make(map[string]int)
+		failed []string
+	)
+	for i := 0; i < 20; i++ {
+		query := fmt.Sprintf("select col%d from t;", i)
+		if i%7 == 3 {
+			query = "select from;"
+		}
+		file := filepath.Join(dir, fmt.Sprintf("query%02d.sql", i))
+		if err := os.WriteFile(file, []byte(query), 0o644); err != nil {
+			t.Fatalf("fail to write %s: %s", file, err)
+		}
+		if i%7 == 3 {
+			failed = append(failed, file)
+		}
+		index[file] = i
+		files = append(files, file)
+	}
+	var (
+		writer = format.NewWriter(io.Discard)
+		stdout bytes.Buffer
+		stderr bytes.Buffer
+	)
+	// the first files are the slowest so that they end after the other ones
+	err := processFilesTo(&stdout, &stderr, files, 4, func(file string, out, _ io.Writer) error {
+		time.Sleep(time.Duration(len(files)-index[file]) * time.Millisecond)
+		r, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+		return writer.Clone(out).Format(r)
+	})
+	if want := fmt.Sprintf("%d/%d file(s) failed", len(failed), len(files)); err == nil || err.Error() != want {
+		t.Errorf("error mismatched! want %q, got %v", want, err)
+	}
+
+	var (
+		output = stdout.String()
+		offset int
+	)
+	for i := range files {
+		if i%7 == 3 {
+			continue
+		}
+		col := fmt.Sprintf("col%d\n", i)
+		x := strings.Index(output, col)
+		if x < offset {
+			t.Fatalf("output of %s not in argument order:\n%s", files[i], output)
+		}
+		offset = x
+	}
+
+	var (
+		problems = stderr.String()
+		last     int
+	)
+	for _, f := range failed {
+		x := strings.Index(problems, f)
+		if x < last {
+			t.Fatalf("error of %s not reported in argument order:\n%s", f, problems)
+		}
+		last = x
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"slices"
 	"strconv"
 	"time"
 	"unicode/utf8"
@@ -46,6 +47,7 @@ func (c *Config) Keys() []string {
 	for k := range c.values {
 		keys = append(keys, k)
 	}
+	slices.Sort(keys)
 	return keys
 }
 
@@ -247,13 +249,24 @@ func (p *Parser) parseLiteral() (any, error) {
 		return nil, p.unexpected()
 	}
 	p.next()
-	switch last.Type {
+	return literalValue(last)
+}
+
+func literalValue(tok Token) (any, error) {
+	switch tok.Type {
 	case String, Ident:
-		return last.Literal, nil
+		return tok.Literal, nil
 	case Bool:
-		return strconv.ParseBool(last.Literal)
+		switch tok.Literal {
+		case "on":
+			return true, nil
+		case "off":
+			return false, nil
+		default:
+			return strconv.ParseBool(tok.Literal)
+		}
 	case Number:
-		return strconv.ParseFloat(last.Literal, 64)
+		return strconv.ParseFloat(tok.Literal, 64)
 	default:
 		return nil, fmt.Errorf("invalid literal type")
 	}
@@ -300,7 +313,7 @@ func (p *Parser) parseArray(ident Token) (any, error) {
 		if !p.isValue() {
 			return nil, p.unexpected()
 		}
-		a, err := p.parseLiteral()
+		a, err := literalValue(p.curr)
 		if err != nil {
 			return nil, err
 		}
@@ -491,7 +504,7 @@ func (s *Scanner) scanComment(tok *Token) {
 }
 
 func (s *Scanner) scanIdent(tok *Token) {
-	for !s.done() && isAlpha(s.char) {
+	for !s.done() && (isAlpha(s.char) || s.char == dot) {
 		s.write()
 		s.read()
 	}
@@ -0,0 +1,170 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileName is the name of the project configuration files
+const FileName = ".sweet.conf"
+
+// Setting is a value of a resolved configuration with the source it comes from
+type Setting struct {
+	Key    string
+	Value  any
+	Source string
+}
+
+// Resolved is a configuration built by merging several sources. The values of
+// a source override the values of the sources merged before it. Nested
+// configurations are merged key by key.
+type Resolved struct {
+	*Config
+	sources map[string]string
+}
+
+func Resolve() *Resolved {
+	return &Resolved{
+		Config:  Make(),
+		sources: make(map[string]string),
+	}
+}
+
+// Merge merges the values of cfg into r. The given source is recorded for
+// every value of cfg.
+func (r *Resolved) Merge(cfg *Config, source string) {
+	if cfg == nil {
+		return
+	}
+	r.merge(r.Config, cfg, "", source)
+}
+
+func (r *Resolved) merge(dst, src *Config, prefix, source string) {
+	for _, k := range src.Keys() {
+		key := prefix + k
+		if sub, ok := src.values[k].(*Config); ok {
+			other, ok := dst.values[k].(*Config)
+			if !ok {
+				delete(r.sources, key)
+				other = Make()
+				dst.values[k] = other
+			}
+			r.merge(other, sub, key+".", source)
+			continue
+		}
+		if old, ok := dst.values[k].(*Config); ok {
+			r.forget(old, key+".")
+		}
+		dst.values[k] = src.values[k]
+		r.sources[key] = source
+	}
+}
+
+// forget removes the sources of the values of a nested configuration replaced
+// by a single value. Keys containing a dot are left untouched since they are
+// not the keys of a nested configuration.
+func (r *Resolved) forget(cfg *Config, prefix string) {
+	for _, k := range cfg.Keys() {
+		key := prefix + k
+		if sub, ok := cfg.values[k].(*Config); ok {
+			r.forget(sub, key+".")
+			continue
+		}
+		delete(r.sources, key)
+	}
+}
+
+// Source gives the source of the value found under the given key. Keys of
+// nested configurations are joined with a dot.
+func (r *Resolved) Source(key string) string {
+	return r.sources[key]
+}
+
+// Settings gives every value of r sorted by key
+func (r *Resolved) Settings() []Setting {
+	var list []Setting
+	var walk func(*Config, string)
+	walk = func(cfg *Config, prefix string) {
+		for _, k := range cfg.Keys() {
+			key := prefix + k
+			if sub, ok := cfg.values[k].(*Config); ok {
+				walk(sub, key+".")
+				continue
+			}
+			s := Setting{
+				Key:    key,
+				Value:  cfg.values[k],
+				Source: r.sources[key],
+			}
+			list = append(list, s)
+		}
+	}
+	walk(r.Config, "")
+	return list
+}
+
+// Finder discovers the project configurations that apply to a file. The
+// configuration files are only loaded once. A Finder can be used by several
+// goroutines.
+type Finder struct {
+	mu    sync.Mutex
+	files map[string]*Config
+}
+
+// Find merges into res the project configuration of the files found in dir:
+// the .sweet.conf files of dir and of its parents are merged from the top most
+// directory, so that a sub directory overrides its parents.
+func (f *Finder) Find(dir string, res *Resolved) error {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	var list []string
+	for {
+		list = append(list, filepath.Join(dir, FileName))
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	for i := len(list) - 1; i >= 0; i-- {
+		cfg, err := f.load(list[i])
+		if err != nil {
+			return err
+		}
+		res.Merge(cfg, list[i])
+	}
+	return nil
+}
+
+func (f *Finder) load(file string) (*Config, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if cfg, ok := f.files[file]; ok {
+		return cfg, nil
+	}
+	if f.files == nil {
+		f.files = make(map[string]*Config)
+	}
+	r, err := os.Open(file)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			f.files[file] = nil
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer r.Close()
+
+	cfg, err := Load(r)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	f.files[file] = cfg
+	return cfg, nil
+}
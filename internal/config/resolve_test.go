@@ -0,0 +1,167 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/midbel/sweet/internal/config"
+)
+
+func TestResolvedMerge(t *testing.T) {
+	type source struct {
+		Name   string
+		Config string
+	}
+	tests := []struct {
+		Name    string
+		Sources []source
+		Want    []config.Setting
+	}{
+		{
+			Name: "override",
+			Sources: []source{
+				{Name: "a", Config: "format {\nindent = 2\nquote = true\n}\n"},
+				{Name: "b", Config: "format {\nindent = 4\n}\n"},
+			},
+			Want: []config.Setting{
+				{Key: "format.indent", Value: float64(4), Source: "b"},
+				{Key: "format.quote", Value: true, Source: "a"},
+			},
+		},
+		{
+			Name: "nested",
+			Sources: []source{
+				{Name: "a", Config: "lint {\nnaming.table {\nstyle = snake_case\nlevel = error\n}\n}\n"},
+				{Name: "b", Config: "lint {\nnaming.table {\nlevel = warning\n}\n}\n"},
+			},
+			Want: []config.Setting{
+				{Key: "lint.naming.table.level", Value: "warning", Source: "b"},
+				{Key: "lint.naming.table.style", Value: "snake_case", Source: "a"},
+			},
+		},
+		{
+			Name: "value replacing configuration",
+			Sources: []source{
+				{Name: "a", Config: "lint {\nnaming.table {\nstyle = snake_case\n}\n}\n"},
+				{Name: "b", Config: "lint {\nnaming.table = false\n}\n"},
+			},
+			Want: []config.Setting{
+				{Key: "lint.naming.table", Value: false, Source: "b"},
+			},
+		},
+		{
+			Name: "configuration replacing value",
+			Sources: []source{
+				{Name: "a", Config: "lint {\nnaming.table = false\n}\n"},
+				{Name: "b", Config: "lint {\nnaming.table {\nstyle = camel\n}\n}\n"},
+			},
+			Want: []config.Setting{
+				{Key: "lint.naming.table.style", Value: "camel", Source: "b"},
+			},
+		},
+		{
+			Name: "group and rules",
+			Sources: []source{
+				{Name: "a", Config: "lint {\nsafety.where.missing = true\n}\n"},
+				{Name: "b", Config: "lint {\nsafety = false\n}\n"},
+			},
+			Want: []config.Setting{
+				{Key: "lint.safety", Value: false, Source: "b"},
+				{Key: "lint.safety.where.missing", Value: true, Source: "a"},
+			},
+		},
+	}
+	for _, c := range tests {
+		res := config.Resolve()
+		for _, s := range c.Sources {
+			cfg, err := config.Load(strings.NewReader(s.Config))
+			if err != nil {
+				t.Fatalf("%s: fail to load %s: %s", c.Name, s.Name, err)
+			}
+			res.Merge(cfg, s.Name)
+		}
+		if got := res.Settings(); !reflect.DeepEqual(got, c.Want) {
+			t.Errorf("%s: settings mismatched!\nwant %#v\ngot  %#v", c.Name, c.Want, got)
+		}
+	}
+}
+
+func TestFinderFind(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"":       "format {\nindent = 2\nquote = true\n}\nlint {\nsafety = true\n}\n",
+		"db":     "format {\nindent = 8\n}\n",
+		"db/sub": "",
+		"other":  "lint {\nsafety = false\nnull = true\n}\n",
+	}
+	for dir, content := range files {
+		dir = filepath.Join(root, filepath.FromSlash(dir))
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if content == "" {
+			continue
+		}
+		if err := os.WriteFile(filepath.Join(dir, config.FileName), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	source := func(dir string) string {
+		return filepath.Join(root, filepath.FromSlash(dir), config.FileName)
+	}
+	tests := []struct {
+		Dir  string
+		Want []config.Setting
+	}{
+		{
+			Dir: "",
+			Want: []config.Setting{
+				{Key: "format.indent", Value: float64(2), Source: source("")},
+				{Key: "format.quote", Value: true, Source: source("")},
+				{Key: "lint.safety", Value: true, Source: source("")},
+			},
+		},
+		{
+			Dir: "db/sub",
+			Want: []config.Setting{
+				{Key: "format.indent", Value: float64(8), Source: source("db")},
+				{Key: "format.quote", Value: true, Source: source("")},
+				{Key: "lint.safety", Value: true, Source: source("")},
+			},
+		},
+		{
+			Dir: "other",
+			Want: []config.Setting{
+				{Key: "format.indent", Value: float64(2), Source: source("")},
+				{Key: "format.quote", Value: true, Source: source("")},
+				{Key: "lint.null", Value: true, Source: source("other")},
+				{Key: "lint.safety", Value: false, Source: source("other")},
+			},
+		},
+	}
+	var finder config.Finder
+	for _, c := range tests {
+		res := config.Resolve()
+		if err := finder.Find(filepath.Join(root, filepath.FromSlash(c.Dir)), res); err != nil {
+			t.Errorf("%s: unexpected error: %s", c.Dir, err)
+			continue
+		}
+		if got := res.Settings(); !reflect.DeepEqual(got, c.Want) {
+			t.Errorf("%s: settings mismatched!\nwant %#v\ngot  %#v", c.Dir, c.Want, got)
+		}
+	}
+
+	bad := filepath.Join(root, "bad")
+	if err := os.MkdirAll(bad, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(bad, config.FileName), []byte("format {\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := finder.Find(bad, config.Resolve()); err == nil || !strings.Contains(err.Error(), bad) {
+		t.Errorf("invalid configuration should give an error naming its file! got %v", err)
+	}
+}
@@ -13,6 +13,7 @@ const (
 )
 
 type CreateProcedureStatement struct {
+	ast.Span
 	ast.CreateProcedureStatement
 	Specific      string
 	Deterministic bool
@@ -30,6 +31,7 @@ const (
 )
 
 type Handler struct {
+	ast.Span
 	Type      HandlerType
 	Condition ast.Statement
 	ast.Statement
@@ -0,0 +1,21 @@
+package db2
+
+import (
+	"github.com/midbel/sweet/internal/lang"
+	"github.com/midbel/sweet/internal/lang/ast"
+)
+
+func init() {
+	lang.Register(lang.Dialect{
+		Name:       "db2",
+		Keywords:   GetKeywords(),
+		Tokenizers: tokenizers(),
+		Formatter:  lang.GetFormatter(),
+		Folding:    lang.FoldUpper,
+		NewParser:  ParseWithScanner,
+	})
+	ast.RegisterType(
+		CreateProcedureStatement{},
+		Handler{},
+	)
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/midbel/sweet/internal/lang"
 	"github.com/midbel/sweet/internal/lang/ast"
 	"github.com/midbel/sweet/internal/lang/parser"
+	"github.com/midbel/sweet/internal/scanner"
 	"github.com/midbel/sweet/internal/token"
 )
 
@@ -18,7 +19,14 @@ func Parse(r io.Reader) (lang.Parser, error) {
 	if err != nil {
 		return nil, err
 	}
-	var ps Parser
+	return ParseWithScanner(scan)
+}
+
+func ParseWithScanner(scan *scanner.Scanner) (lang.Parser, error) {
+	var (
+		ps  Parser
+		err error
+	)
 	ps.Parser, err = parser.ParseWithScanner(scan)
 	if err != nil {
 		return nil, err
@@ -61,12 +69,12 @@ func (p *Parser) ParseDeclare() (ast.Statement, error) {
 	} else if p.IsKeyword("UNDO HANDLER FOR") {
 		stmt.Type = UndoHandler
 	} else {
-		return nil, p.Unexpected("declare")
+		return nil, p.Unexpected("declare", "")
 	}
 	p.Next()
 
 	if !p.Is(token.Ident) {
-		return nil, p.Unexpected("declare")
+		return nil, p.Unexpected("declare", "")
 	}
 	stmt.Condition = ast.Value{
 		Literal: p.GetCurrLiteral(),
@@ -134,14 +142,14 @@ func (p *Parser) ParseProcedureOptions() (ast.Statement, error) {
 	var list ast.List
 	for !p.Done() && p.PeekIs(token.Eq) {
 		if !p.Is(token.Ident) && !p.Is(token.Keyword) {
-			return nil, p.Unexpected("set option")
+			return nil, p.Unexpected("set option", "")
 		}
 		key := ast.Name{
 			Parts: []string{p.GetCurrLiteral()},
 		}
 		p.Next()
 		if !p.Is(token.Eq) {
-			return nil, p.Unexpected("set option")
+			return nil, p.Unexpected("set option", "")
 		}
 		p.Next()
 		val := ast.Name{
@@ -12,10 +12,18 @@ func Scan(r io.Reader) (*scanner.Scanner, error) {
 	if err != nil {
 		return nil, err
 	}
-	scan.Register(starIdent{})
+	for _, t := range tokenizers() {
+		scan.Register(t)
+	}
 	return scan, err
 }
 
+func tokenizers() []scanner.Tokenizer {
+	return []scanner.Tokenizer{
+		starIdent{},
+	}
+}
+
 type starIdent struct{}
 
 func (_ starIdent) Can(curr, peek rune) bool {
@@ -0,0 +1,167 @@
+package diag
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/midbel/sweet/internal/lang"
+	"github.com/midbel/sweet/internal/token"
+)
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+)
+
+// Diagnostic is a problem found in an input, whatever the command that
+// detected it.
+type Diagnostic struct {
+	File     string         `json:"file,omitempty"`
+	Code     string         `json:"code,omitempty"`
+	Severity string         `json:"severity"`
+	Message  string         `json:"message"`
+	Context  string         `json:"context,omitempty"`
+	Start    token.Position `json:"start"`
+	End      token.Position `json:"end"`
+	Expected []string       `json:"expected,omitempty"`
+	Hint     string         `json:"hint,omitempty"`
+	// Source is the input used to print the lines around the problem and
+	// SourceLine the line number of its first line
+	Source     string `json:"-"`
+	SourceLine int    `json:"-"`
+}
+
+func (d Diagnostic) Location() string {
+	var parts []string
+	if d.File != "" {
+		parts = append(parts, d.File)
+	}
+	if d.Start.Line > 0 {
+		parts = append(parts, fmt.Sprint(d.Start.Line), fmt.Sprint(d.Start.Column))
+	}
+	return strings.Join(parts, ":")
+}
+
+// FromError converts an error into diagnostics. Syntax errors keep their
+// position, code and hints, other errors only their message.
+func FromError(file string, err error) []Diagnostic {
+	var list lang.ParseErrors
+	if errors.As(err, &list) {
+		var all []Diagnostic
+		for i := range list {
+			all = append(all, fromParseError(file, list[i]))
+		}
+		return all
+	}
+	var perr lang.ParseError
+	if errors.As(err, &perr) {
+		return []Diagnostic{fromParseError(file, perr)}
+	}
+	d := Diagnostic{
+		File:     file,
+		Severity: SeverityError,
+		Message:  err.Error(),
+	}
+	return []Diagnostic{d}
+}
+
+func fromParseError(file string, err lang.ParseError) Diagnostic {
+	if err.File != "" {
+		file = err.File
+	}
+	return Diagnostic{
+		File:       file,
+		Code:       err.Code,
+		Severity:   SeverityError,
+		Message:    err.Reason,
+		Context:    err.Context,
+		Start:      err.Position(),
+		End:        err.End,
+		Expected:   err.Expected,
+		Hint:       err.Hint,
+		Source:     err.Query,
+		SourceLine: err.QueryLine,
+	}
+}
+
+func Render(w io.Writer, format string, list []Diagnostic) error {
+	switch format {
+	case FormatText, "":
+		for i := range list {
+			renderText(w, list[i])
+		}
+		return nil
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		for i := range list {
+			if err := enc.Encode(list[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("%s: unsupported diagnostic format", format)
+	}
+}
+
+func renderText(w io.Writer, d Diagnostic) {
+	var header string
+	if loc := d.Location(); loc != "" {
+		header = loc + ": "
+	}
+	header += d.Severity
+	if d.Code != "" {
+		header += "[" + d.Code + "]"
+	}
+	if d.Context != "" {
+		header += " (" + d.Context + ")"
+	}
+	fmt.Fprintf(w, "%s: %s", header, d.Message)
+	fmt.Fprintln(w)
+
+	if d.Source != "" && d.Start.Line > 0 {
+		renderSnippet(w, d)
+	}
+	if len(d.Expected) > 0 {
+		fmt.Fprintf(w, "  = expected: %s", strings.Join(d.Expected, ", "))
+		fmt.Fprintln(w)
+	}
+	if d.Hint != "" {
+		fmt.Fprintf(w, "  = hint: %s", d.Hint)
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintln(w)
+}
+
+// renderSnippet prints the line of the problem and the two lines before it
+// when they are part of the source
+func renderSnippet(w io.Writer, d Diagnostic) {
+	var (
+		lines  = strings.Split(d.Source, "\n")
+		pos    = d.Start
+		offset = max(1, d.SourceLine)
+		first  = max(offset, pos.Line-2)
+	)
+	if pos.Line < offset || pos.Line-offset >= len(lines) {
+		return
+	}
+	for i := first; i <= pos.Line; i++ {
+		fmt.Fprintf(w, "%03d | %s", i, strings.TrimRight(lines[i-offset], "\r"))
+		fmt.Fprintln(w)
+	}
+	size := 1
+	if d.End.Line == pos.Line && d.End.Column > pos.Column {
+		size = d.End.Column - pos.Column
+	}
+	fmt.Fprint(w, strings.Repeat(" ", 6+pos.Column-1))
+	fmt.Fprintln(w, strings.Repeat("^", size))
+}
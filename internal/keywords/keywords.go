@@ -2,7 +2,6 @@ package keywords
 
 import (
 	"errors"
-	"slices"
 	"sort"
 	"strings"
 )
@@ -47,16 +46,16 @@ func (ks Set) Is(str []string) (string, bool, bool) {
 		got  = strings.ToLower(strings.Join(str, " "))
 		want string
 	)
-	for _, kw := range ks[i:] {
+	for j, kw := range ks[i:] {
 		if kw[0] != s {
 			break
 		}
 		want = strings.Join(kw, " ")
 		switch {
 		case want == got:
-			var final bool
-			if i+1 == n || !slices.Equal(str, ks[i+1]) {
-				final = true
+			final := true
+			if j := i + j + 1; j < n && strings.HasPrefix(strings.Join(ks[j], " "), got+" ") {
+				final = false
 			}
 			return got, final, true
 		case strings.HasPrefix(want, got):
@@ -67,21 +66,21 @@ func (ks Set) Is(str []string) (string, bool, bool) {
 	return "", false, false
 }
 
-func (ks Set) Prepare() {
-	seen := make(map[string]struct{})
+// Prepare gives a sorted copy of the set with all its keywords lowered. The
+// set itself is left unchanged so that it can be shared between scanners.
+func (ks Set) Prepare() Set {
+	tmp := make(Set, 0, len(ks))
 	for i := range ks {
-		str := strings.Join(ks[i], "")
-		if _, ok := seen[str]; ok {
-			continue
-		}
-		seen[str] = struct{}{}
+		kw := make([]string, len(ks[i]))
 		for j := range ks[i] {
-			ks[i][j] = strings.ToLower(ks[i][j])
+			kw[j] = strings.ToLower(ks[i][j])
 		}
+		tmp = append(tmp, kw)
 	}
-	sort.Slice(ks, func(i, j int) bool {
-		fst := strings.Join(ks[i], " ")
-		lst := strings.Join(ks[j], " ")
+	sort.Slice(tmp, func(i, j int) bool {
+		fst := strings.Join(tmp[i], " ")
+		lst := strings.Join(tmp[j], " ")
 		return fst < lst
 	})
+	return tmp
 }
@@ -1,13 +1,65 @@
 package lang
 
 import (
+	"fmt"
+	"strings"
+
+	"github.com/midbel/sweet/internal/config"
 	"github.com/midbel/sweet/internal/lang/ast"
+	"github.com/midbel/sweet/internal/token"
 )
 
 type Formatter interface {
 	Quote(string) string
+	Keyword(string) string
 }
 
 type Parser interface {
 	Parse() (ast.Statement, error)
 }
+
+// Configurable is implemented by parsers that collect settings from the macros
+// found at the top of the input. The settings of the format and lint macros are
+// stored in the format and lint sections.
+type Configurable interface {
+	Settings() *config.Config
+}
+
+// Recoverable is implemented by parsers that can continue after a syntax error.
+// In recovery mode, Parse gives the statements with ast.Invalid nodes in place
+// of the parts that could not be parsed and the errors are collected instead
+// of being returned.
+type Recoverable interface {
+	SetRecover(bool)
+	Errors() []ParseError
+}
+
+// Commenter is implemented by parsers that can keep the comments of the input
+// as they read it. The comments are only kept once KeepComments has been called
+// before the first statement is parsed. Comments gives the comments of the
+// parsed file read since its last call. The comments of the included files are
+// not given.
+type Commenter interface {
+	KeepComments(bool)
+	Comments() []token.Token
+}
+
+// RowSkipper is implemented by parsers that can skip the rows of INSERT
+// statements instead of building a node for each of them
+type RowSkipper interface {
+	SkipRows(bool)
+}
+
+type ansiFormatter struct{}
+
+func (_ ansiFormatter) Quote(str string) string {
+	return fmt.Sprintf("\"%s\"", str)
+}
+
+func (_ ansiFormatter) Keyword(str string) string {
+	return strings.ToLower(str)
+}
+
+func GetFormatter() Formatter {
+	return ansiFormatter{}
+}
@@ -28,11 +28,13 @@ func (n Node) GetNames() []string {
 type Statement interface{}
 
 type Limit struct {
+	Span
 	Count  int
 	Offset int
 }
 
 type Offset struct {
+	Span
 	Limit
 	Next bool
 }
@@ -45,23 +47,27 @@ const (
 )
 
 type Order struct {
+	Span
 	Statement
 	Dir   OrderDir
 	Nulls string
 }
 
 type Join struct {
+	Span
 	Type  string
 	Table Statement
 	Where Statement
 }
 
 type WindowDefinition struct {
+	Span
 	Ident  Statement
 	Window Statement
 }
 
 type Window struct {
+	Span
 	Ident      Statement
 	Partitions []Statement
 	Orders     []Statement
@@ -87,11 +93,13 @@ const (
 )
 
 type FrameSpec struct {
+	Span
 	Row  FrameRow
 	Expr Statement
 }
 
 type BetweenFrameSpec struct {
+	Span
 	Left    FrameSpec
 	Right   FrameSpec
 	Exclude FrameExclude
@@ -105,6 +113,7 @@ const (
 )
 
 type CteStatement struct {
+	Span
 	Ident        string
 	Materialized MaterializedMode
 	Columns      []string
@@ -112,6 +121,7 @@ type CteStatement struct {
 }
 
 type WithStatement struct {
+	Span
 	Recursive bool
 	Queries   []Statement
 	Statement
@@ -137,6 +147,7 @@ func (s WithStatement) Get() Statement {
 }
 
 type ValuesStatement struct {
+	Span
 	List   []Statement
 	Orders []Statement
 	Limit  Statement
@@ -147,6 +158,7 @@ func (s ValuesStatement) Keyword() (string, error) {
 }
 
 type SelectStatement struct {
+	Span
 	Distinct bool
 	Columns  []Statement
 	Tables   []Statement
@@ -205,6 +217,7 @@ func getCompoundKeyword(kw string, all, distinct bool) (string, error) {
 }
 
 type UnionStatement struct {
+	Span
 	Left     Statement
 	Right    Statement
 	All      bool
@@ -216,6 +229,7 @@ func (s UnionStatement) Keyword() (string, error) {
 }
 
 type IntersectStatement struct {
+	Span
 	Left     Statement
 	Right    Statement
 	All      bool
@@ -227,6 +241,7 @@ func (s IntersectStatement) Keyword() (string, error) {
 }
 
 type ExceptStatement struct {
+	Span
 	Left     Statement
 	Right    Statement
 	All      bool
@@ -238,11 +253,13 @@ func (s ExceptStatement) Keyword() (string, error) {
 }
 
 type MatchStatement struct {
+	Span
 	Condition Statement
 	Statement
 }
 
 type MergeStatement struct {
+	Span
 	Target  Statement
 	Source  Statement
 	Join    Statement
@@ -254,17 +271,20 @@ func (s MergeStatement) Keyword() (string, error) {
 }
 
 type Upsert struct {
+	Span
 	Columns []string
 	List    []Statement
 	Where   Statement
 }
 
 type Assignment struct {
+	Span
 	Field Statement
 	Value Statement
 }
 
 type InsertStatement struct {
+	Span
 	Table   Statement
 	Columns []string
 	Values  Statement
@@ -277,6 +297,7 @@ func (s InsertStatement) Keyword() (string, error) {
 }
 
 type UpdateStatement struct {
+	Span
 	Table  Statement
 	List   []Statement
 	Tables []Statement
@@ -289,6 +310,7 @@ func (s UpdateStatement) Keyword() (string, error) {
 }
 
 type TruncateStatement struct {
+	Span
 	Tables   []string
 	Cascade  CascadeMode
 	Identity IdentityMode
@@ -298,7 +320,33 @@ func (s TruncateStatement) Keyword() (string, error) {
 	return "TRUNCATE", nil
 }
 
+// CopyStatement is the COPY statement of PostgreSQL. The rows given after a
+// COPY FROM STDIN are skipped by the parser and are not part of the node.
+type CopyStatement struct {
+	Span
+	Table   Statement
+	Columns []string
+	To      bool
+	Target  Statement
+	Options []Statement
+	// Data holds the lines given after a COPY FROM STDIN. They are not kept
+	// when the parser skips the rows.
+	Data []string
+}
+
+func (s CopyStatement) Keyword() (string, error) {
+	return "COPY", nil
+}
+
+// SkippedRows stands for the VALUES of an INSERT whose rows have not been
+// parsed. Count is the number of rows found.
+type SkippedRows struct {
+	Span
+	Count int
+}
+
 type DeleteStatement struct {
+	Span
 	Table  string
 	Where  Statement
 	Return Statement
@@ -425,3 +473,12 @@ func GetIdentFromStatement(stmt Statement) (string, string) {
 		return "", ""
 	}
 }
+
+// Invalid marks the part of a statement that could not be parsed. At the top
+// level, it wraps the partial statement and keeps the query as written.
+type Invalid struct {
+	Span
+	Statement
+	Query string
+	Err   error
+}
@@ -1,6 +1,7 @@
 package ast
 
 type PrimaryKeyConstraint struct {
+	Span
 	Columns []string
 }
 
@@ -9,6 +10,7 @@ func (_ PrimaryKeyConstraint) Keyword() (string, error) {
 }
 
 type ForeignKeyConstraint struct {
+	Span
 	Locals   []string
 	Remotes  []string
 	Table    string
@@ -24,6 +26,7 @@ func (c ForeignKeyConstraint) Keyword() (string, error) {
 }
 
 type NotNullConstraint struct {
+	Span
 	Column string
 }
 
@@ -32,6 +35,7 @@ func (_ NotNullConstraint) Keyword() (string, error) {
 }
 
 type UniqueConstraint struct {
+	Span
 	Columns []string
 }
 
@@ -40,6 +44,7 @@ func (_ UniqueConstraint) Keyword() (string, error) {
 }
 
 type CheckConstraint struct {
+	Span
 	Expr Statement
 }
 
@@ -48,6 +53,7 @@ func (_ CheckConstraint) Keyword() (string, error) {
 }
 
 type DefaultConstraint struct {
+	Span
 	Expr Statement
 }
 
@@ -56,6 +62,7 @@ func (_ DefaultConstraint) Keyword() (string, error) {
 }
 
 type GeneratedConstraint struct {
+	Span
 	Expr Statement
 }
 
@@ -64,6 +71,7 @@ func (_ GeneratedConstraint) Keyword() (string, error) {
 }
 
 type Constraint struct {
+	Span
 	Name string
 	Statement
 }
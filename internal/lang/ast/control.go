@@ -1,43 +1,51 @@
 package ast
 
 type Return struct {
+	Span
 	Statement
 }
 
 type While struct {
+	Span
 	Cdt  Statement
 	Body Statement
 }
 
 type If struct {
+	Span
 	Cdt Statement
 	Csq Statement
 	Alt Statement
 }
 
 type Declare struct {
+	Span
 	Ident string
 	Type  Type
 	Value Statement
 }
 
 type Case struct {
+	Span
 	Cdt  Statement
 	Body []Statement
 	Else Statement
 }
 
 type When struct {
+	Span
 	Cdt  Statement
 	Body Statement
 }
 
 type Set struct {
+	Span
 	Ident string
 	Expr  Statement
 }
 
 type CallStatement struct {
+	Span
 	Ident Statement
 	Names []string
 	Args  []Statement
@@ -46,3 +54,17 @@ type CallStatement struct {
 func (_ CallStatement) Keyword() (string, error) {
 	return "CALL", nil
 }
+
+type ExecuteStatement struct {
+	Span
+	Immediate bool
+	Statement Statement
+	Using     []Statement
+}
+
+func (s ExecuteStatement) Keyword() (string, error) {
+	if s.Immediate {
+		return "EXECUTE IMMEDIATE", nil
+	}
+	return "EXECUTE", nil
+}
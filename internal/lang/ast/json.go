@@ -0,0 +1,454 @@
+package ast
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"reflect"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Version is the version of the JSON encoding of the nodes. It is increased
+// each time a change in the encoding can break existing consumers.
+const Version = 1
+
+// Document is the JSON representation of a list of statements
+//
+//	{"version": 1, "statements": [{"type": "SelectStatement", ...}]}
+//
+// Every node stored in a Statement is an object with a "type" member giving
+// the name of the node. The other members are the fields of the node with
+// their first letter lowered, except Type encoded as "kind" since "type" is
+// reserved. Fields with a zero value are omitted. Nodes defined by a dialect
+// are prefixed by the name of their package (ora.Block).
+type Document struct {
+	Version    int         `json:"version"`
+	Statements []Statement `json:"statements"`
+}
+
+func (d Document) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `{"version":%d,"statements":[`, d.Version)
+	for i := range d.Statements {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if err := encodeNode(&buf, d.Statements[i]); err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteString("]}")
+	return buf.Bytes(), nil
+}
+
+func (d *Document) UnmarshalJSON(data []byte) error {
+	var doc struct {
+		Version    int               `json:"version"`
+		Statements []json.RawMessage `json:"statements"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	if doc.Version != Version {
+		return fmt.Errorf("ast: unsupported version %d (want %d)", doc.Version, Version)
+	}
+	d.Version = doc.Version
+	d.Statements = d.Statements[:0]
+	for i := range doc.Statements {
+		stmt, err := Unmarshal(doc.Statements[i])
+		if err != nil {
+			return err
+		}
+		d.Statements = append(d.Statements, stmt)
+	}
+	return nil
+}
+
+// Encode writes the given statements as a Document
+func Encode(w io.Writer, list []Statement) error {
+	doc := Document{
+		Version:    Version,
+		Statements: list,
+	}
+	buf, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(buf)
+	return err
+}
+
+// Decode reads a Document and gives back its statements
+func Decode(r io.Reader) ([]Statement, error) {
+	var doc Document
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc.Statements, nil
+}
+
+// Marshal gives the JSON encoding of a single node
+func Marshal(stmt Statement) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := encodeNode(&buf, stmt); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal creates the node encoded in data. The type of the node should
+// have been registered first.
+func Unmarshal(data []byte) (Statement, error) {
+	var stmt Statement
+	if err := decodeValue(data, reflect.ValueOf(&stmt).Elem()); err != nil {
+		return nil, err
+	}
+	return stmt, nil
+}
+
+var types = struct {
+	sync.RWMutex
+	names map[reflect.Type]string
+	nodes map[string]reflect.Type
+}{
+	names: make(map[reflect.Type]string),
+	nodes: make(map[string]reflect.Type),
+}
+
+// RegisterType makes the type of the given nodes known to the JSON encoder
+// and decoder. Dialects should register the nodes they define. It panics if
+// a type is registered twice.
+func RegisterType(list ...Statement) {
+	types.Lock()
+	defer types.Unlock()
+	for _, v := range list {
+		t := reflect.TypeOf(v)
+		n := typeName(t)
+		if _, ok := types.nodes[n]; ok {
+			panic(fmt.Sprintf("ast: type %s registered twice", n))
+		}
+		types.nodes[n] = t
+		types.names[t] = n
+	}
+}
+
+var astPath = reflect.TypeOf(Node{}).PkgPath()
+
+func typeName(t reflect.Type) string {
+	if t.PkgPath() == astPath {
+		return t.Name()
+	}
+	return path.Base(t.PkgPath()) + "." + t.Name()
+}
+
+func lookupName(t reflect.Type) (string, bool) {
+	types.RLock()
+	defer types.RUnlock()
+	n, ok := types.names[t]
+	return n, ok
+}
+
+func lookupType(n string) (reflect.Type, bool) {
+	types.RLock()
+	defer types.RUnlock()
+	t, ok := types.nodes[n]
+	return t, ok
+}
+
+func fieldName(f reflect.StructField) string {
+	if tag := f.Tag.Get("json"); tag != "" {
+		return tag
+	}
+	if f.Name == "Type" {
+		return "kind"
+	}
+	r, z := utf8.DecodeRuneInString(f.Name)
+	return string(unicode.ToLower(r)) + f.Name[z:]
+}
+
+func encodeNode(buf *bytes.Buffer, stmt Statement) error {
+	if stmt == nil {
+		buf.WriteString("null")
+		return nil
+	}
+	v := reflect.ValueOf(stmt)
+	name, ok := lookupName(v.Type())
+	if !ok {
+		return fmt.Errorf("ast: unregistered node type %s", v.Type())
+	}
+	buf.WriteString(`{"type":`)
+	writeString(buf, name)
+	if v.Kind() != reflect.Struct {
+		buf.WriteString(`,"value":`)
+		if err := encodeValue(buf, v); err != nil {
+			return err
+		}
+	} else if err := encodeFields(buf, v, true); err != nil {
+		return err
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+func encodeFields(buf *bytes.Buffer, v reflect.Value, more bool) error {
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		if !f.IsExported() || v.Field(i).IsZero() {
+			continue
+		}
+		if more {
+			buf.WriteByte(',')
+		}
+		more = true
+		writeString(buf, fieldName(f))
+		buf.WriteByte(':')
+		if err := encodeValue(buf, v.Field(i)); err != nil {
+			return fmt.Errorf("%s.%s: %w", v.Type(), f.Name, err)
+		}
+	}
+	return nil
+}
+
+// errorType is the type of the errors kept in the nodes. They are encoded as
+// their message.
+var errorType = reflect.TypeFor[error]()
+
+func encodeValue(buf *bytes.Buffer, v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+		if v.Type() == errorType {
+			writeString(buf, v.Interface().(error).Error())
+			return nil
+		}
+		return encodeNode(buf, v.Interface())
+	case reflect.Struct:
+		buf.WriteByte('{')
+		if err := encodeFields(buf, v, false); err != nil {
+			return err
+		}
+		buf.WriteByte('}')
+	case reflect.Slice:
+		if v.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+		buf.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := encodeValue(buf, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		b, err := json.Marshal(v.Interface())
+		if err != nil {
+			return err
+		}
+		buf.Write(b)
+	default:
+		return fmt.Errorf("ast: %s can not be encoded", v.Type())
+	}
+	return nil
+}
+
+func writeString(buf *bytes.Buffer, str string) {
+	b, _ := json.Marshal(str)
+	buf.Write(b)
+}
+
+func decodeValue(data []byte, v reflect.Value) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.Type() == errorType {
+			var msg string
+			if err := json.Unmarshal(data, &msg); err != nil {
+				return err
+			}
+			v.Set(reflect.ValueOf(errors.New(msg)))
+			return nil
+		}
+		return decodeNode(data, v)
+	case reflect.Struct:
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(data, &obj); err != nil {
+			return err
+		}
+		return decodeFields(obj, v)
+	case reflect.Slice:
+		var list []json.RawMessage
+		if err := json.Unmarshal(data, &list); err != nil {
+			return err
+		}
+		s := reflect.MakeSlice(v.Type(), len(list), len(list))
+		for i := range list {
+			if err := decodeValue(list[i], s.Index(i)); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+		return nil
+	default:
+		return json.Unmarshal(data, v.Addr().Interface())
+	}
+}
+
+func decodeNode(data []byte, v reflect.Value) error {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+	var name string
+	if err := json.Unmarshal(obj["type"], &name); err != nil || name == "" {
+		return fmt.Errorf("ast: node without type")
+	}
+	t, ok := lookupType(name)
+	if !ok {
+		return fmt.Errorf("ast: unknown node type %q", name)
+	}
+	x := reflect.New(t).Elem()
+	if t.Kind() == reflect.Struct {
+		delete(obj, "type")
+		if err := decodeFields(obj, x); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	} else if raw, ok := obj["value"]; ok {
+		if err := decodeValue(raw, x); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	if !x.Type().AssignableTo(v.Type()) {
+		return fmt.Errorf("ast: %s can not be used as %s", name, v.Type())
+	}
+	v.Set(x)
+	return nil
+}
+
+func decodeFields(obj map[string]json.RawMessage, v reflect.Value) error {
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		raw, ok := obj[fieldName(f)]
+		if !ok {
+			continue
+		}
+		if err := decodeValue(raw, v.Field(i)); err != nil {
+			return fmt.Errorf("%s: %w", fieldName(f), err)
+		}
+	}
+	return nil
+}
+
+func init() {
+	RegisterType(
+		Node{},
+		Invalid{},
+		Limit{},
+		Offset{},
+		Order{},
+		Join{},
+		WindowDefinition{},
+		Window{},
+		FrameSpec{},
+		BetweenFrameSpec{},
+		CteStatement{},
+		WithStatement{},
+		ValuesStatement{},
+		SelectStatement{},
+		UnionStatement{},
+		IntersectStatement{},
+		ExceptStatement{},
+		MatchStatement{},
+		MergeStatement{},
+		Upsert{},
+		Assignment{},
+		InsertStatement{},
+		UpdateStatement{},
+		TruncateStatement{},
+		DeleteStatement{},
+		CopyStatement{},
+		SkippedRows{},
+		PrimaryKeyConstraint{},
+		ForeignKeyConstraint{},
+		NotNullConstraint{},
+		UniqueConstraint{},
+		CheckConstraint{},
+		DefaultConstraint{},
+		GeneratedConstraint{},
+		Constraint{},
+		Return{},
+		While{},
+		If{},
+		Declare{},
+		Case{},
+		When{},
+		Set{},
+		CallStatement{},
+		ExecuteStatement{},
+		GrantStatement{},
+		RevokeStatement{},
+		ProcedureParameter{},
+		CreateProcedureStatement{},
+		ColumnDef{},
+		RenameTableAction{},
+		RenameColumnAction{},
+		AddColumnAction{},
+		AlterColumnAction{},
+		DropColumnAction{},
+		AddConstraintAction{},
+		DropConstraintAction{},
+		RenameConstraintAction{},
+		AlterTableStatement{},
+		DropViewStatement{},
+		DropTableStatement{},
+		CreateViewStatement{},
+		CreateIndexStatement{},
+		CreateTableStatement{},
+		SetTransaction{},
+		StartTransaction{},
+		Savepoint{},
+		ReleaseSavepoint{},
+		RollbackSavepoint{},
+		Commit{},
+		Rollback{},
+		Group{},
+		Cast{},
+		Type{},
+		Not{},
+		Collate{},
+		Exists{},
+		Call{},
+		Row{},
+		Unary{},
+		Binary{},
+		All{},
+		Any{},
+		Is{},
+		In{},
+		Between{},
+		List{},
+		Placeholder{},
+		Value{},
+		Alias{},
+		Name{},
+	)
+}
@@ -1,9 +1,11 @@
 package ast
 
 type GrantStatement struct {
-	Object     string
-	Privileges []string
-	Users      []string
+	Span
+	Object      string
+	Privileges  []string
+	Users       []string
+	GrantOption bool
 }
 
 func (s GrantStatement) Keyword() (string, error) {
@@ -11,6 +13,7 @@ func (s GrantStatement) Keyword() (string, error) {
 }
 
 type RevokeStatement struct {
+	Span
 	Object     string
 	Privileges []string
 	Users      []string
@@ -9,6 +9,7 @@ const (
 )
 
 type ProcedureParameter struct {
+	Span
 	Mode    ParameterMode
 	Name    string
 	Type    Type
@@ -16,10 +17,12 @@ type ProcedureParameter struct {
 }
 
 type CreateProcedureStatement struct {
+	Span
 	Replace    bool
 	Name       Statement
 	Parameters []Statement
 	Language   string
+	Security   string
 	Body       Statement
 }
 
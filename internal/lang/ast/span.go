@@ -0,0 +1,77 @@
+package ast
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/midbel/sweet/internal/token"
+)
+
+// Span is the location of a node in the file it has been parsed from. Start
+// is the position of its first token and End the position right after its
+// last token.
+type Span struct {
+	File  string
+	Start token.Position
+	End   token.Position
+}
+
+func (s Span) Location() Span {
+	return s
+}
+
+func (s Span) IsZero() bool {
+	return s.Start.Line == 0
+}
+
+// Pos gives the start of the span as file:line:column. It is not named String
+// since the method would be promoted to every node embedding a Span.
+func (s Span) Pos() string {
+	if s.File == "" {
+		return s.Start.String()
+	}
+	return fmt.Sprintf("%s:%s", s.File, s.Start)
+}
+
+type Locatable interface {
+	Location() Span
+}
+
+// SpanOf gives the span of a node or a zero span if the node does not record
+// its location
+func SpanOf(stmt Statement) Span {
+	switch n := stmt.(type) {
+	case Node:
+		return SpanOf(n.Statement)
+	case Locatable:
+		return n.Location()
+	default:
+		return Span{}
+	}
+}
+
+var spanType = reflect.TypeOf(Span{})
+
+// Locate gives a copy of the node with its span set. Nodes that already know
+// their location are kept unchanged.
+func Locate(stmt Statement, span Span) Statement {
+	if n, ok := stmt.(Node); ok {
+		n.Statement = Locate(n.Statement, span)
+		return n
+	}
+	if stmt == nil || !SpanOf(stmt).IsZero() {
+		return stmt
+	}
+	v := reflect.ValueOf(stmt)
+	if v.Kind() != reflect.Struct {
+		return stmt
+	}
+	c := reflect.New(v.Type()).Elem()
+	c.Set(v)
+	f := c.FieldByName("Span")
+	if !f.IsValid() || f.Type() != spanType {
+		return stmt
+	}
+	f.Set(reflect.ValueOf(span))
+	return c.Interface()
+}
@@ -15,51 +15,68 @@ const (
 )
 
 type ColumnDef struct {
+	Span
 	Name        string
 	Type        Type
 	Constraints []Statement
 }
 
 type RenameTableAction struct {
+	Span
 	Name string
 }
 
 type RenameColumnAction struct {
+	Span
 	Old string
 	New string
 }
 
 type AddColumnAction struct {
+	Span
 	Def       Statement
 	NotExists bool
 }
 
+// AlterColumnAction changes the type, the default value or the nullability
+// of a column. Type is only set when the type of the column changes.
 type AlterColumnAction struct {
-	Name string
+	Span
+	Name        string
+	Type        Type
+	Default     Statement
+	DropDefault bool
+	NotNull     bool
+	DropNotNull bool
 }
 
 type DropColumnAction struct {
+	Span
 	Name    string
 	Exists  bool
 	Cascade CascadeMode
 }
 
 type AddConstraintAction struct {
+	Span
 	Constraint Statement
 }
 
 type DropConstraintAction struct {
+	Span
 	Name    string
 	Exists  bool
 	Cascade CascadeMode
 }
 
 type RenameConstraintAction struct {
+	Span
 	Old string
 	New string
 }
 
 type AlterTableStatement struct {
+	Span
 	Name   Statement
 	Action Statement
 }
@@ -69,6 +86,7 @@ func (s AlterTableStatement) Keyword() (string, error) {
 }
 
 type DropViewStatement struct {
+	Span
 	Names   []Statement
 	Exists  bool
 	Cascade CascadeMode
@@ -79,6 +97,7 @@ func (s DropViewStatement) Keyword() (string, error) {
 }
 
 type DropTableStatement struct {
+	Span
 	Names   []Statement
 	Exists  bool
 	Cascade CascadeMode
@@ -89,6 +108,7 @@ func (s DropTableStatement) Keyword() (string, error) {
 }
 
 type CreateViewStatement struct {
+	Span
 	Temp      bool
 	Name      Statement
 	NotExists bool
@@ -103,7 +123,25 @@ func (s CreateViewStatement) Keyword() (string, error) {
 	return "CREATE VIEW", nil
 }
 
+type CreateIndexStatement struct {
+	Span
+	Unique       bool
+	Concurrently bool
+	Name         string
+	NotExists    bool
+	Table        Statement
+	Columns      []string
+}
+
+func (s CreateIndexStatement) Keyword() (string, error) {
+	if s.Unique {
+		return "CREATE UNIQUE INDEX", nil
+	}
+	return "CREATE INDEX", nil
+}
+
 type CreateTableStatement struct {
+	Span
 	Temp        bool
 	Name        Statement
 	NotExists   bool
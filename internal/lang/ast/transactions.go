@@ -17,6 +17,7 @@ const (
 )
 
 type SetTransaction struct {
+	Span
 	Mode  TransactionMode
 	Level TransactionLevel
 }
@@ -26,6 +27,7 @@ func (_ SetTransaction) Keyword() (string, error) {
 }
 
 type StartTransaction struct {
+	Span
 	Mode TransactionMode
 	Body Statement
 	End  Statement
@@ -36,6 +38,7 @@ func (_ StartTransaction) Keyword() (string, error) {
 }
 
 type Savepoint struct {
+	Span
 	Name string
 }
 
@@ -44,6 +47,7 @@ func (_ Savepoint) Keyword() (string, error) {
 }
 
 type ReleaseSavepoint struct {
+	Span
 	Name string
 }
 
@@ -52,6 +56,7 @@ func (_ ReleaseSavepoint) Keyword() (string, error) {
 }
 
 type RollbackSavepoint struct {
+	Span
 	Name string
 }
 
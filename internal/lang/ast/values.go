@@ -74,21 +74,25 @@ func ReplaceExpr(b Binary) Statement {
 }
 
 type Group struct {
+	Span
 	Statement
 }
 
 type Cast struct {
+	Span
 	Ident Statement
 	Type  Type
 }
 
 type Type struct {
+	Span
 	Name      string
 	Length    int
 	Precision int
 }
 
 type Not struct {
+	Span
 	Statement
 }
 
@@ -97,11 +101,13 @@ func (n Not) GetNames() []string {
 }
 
 type Collate struct {
+	Span
 	Statement
 	Collation string
 }
 
 type Exists struct {
+	Span
 	Statement
 }
 
@@ -122,6 +128,7 @@ var sqlBuiltins = []string{
 }
 
 type Call struct {
+	Span
 	Distinct bool
 	Ident    Statement
 	Args     []Statement
@@ -142,7 +149,7 @@ func (c Call) GetIdent() string {
 }
 
 func (c Call) IsAggregate() bool {
-	return slices.Contains(sqlAggregates, c.GetIdent())
+	return slices.Contains(sqlAggregates, strings.ToLower(c.GetIdent()))
 }
 
 func (c Call) BuiltinSql() bool {
@@ -150,6 +157,7 @@ func (c Call) BuiltinSql() bool {
 }
 
 type Row struct {
+	Span
 	Values []Statement
 }
 
@@ -158,6 +166,7 @@ func (r Row) Keyword() (string, error) {
 }
 
 type Unary struct {
+	Span
 	Right Statement
 	Op    string
 }
@@ -167,6 +176,7 @@ func (u Unary) GetNames() []string {
 }
 
 type Binary struct {
+	Span
 	Left  Statement
 	Right Statement
 	Op    string
@@ -184,19 +194,23 @@ func (b Binary) IsRelation() bool {
 }
 
 type All struct {
+	Span
 	Statement
 }
 
 type Any struct {
+	Span
 	Statement
 }
 
 type Is struct {
+	Span
 	Ident Statement
 	Value Statement
 }
 
 type In struct {
+	Span
 	Ident Statement
 	Value Statement
 }
@@ -209,6 +223,7 @@ func (i In) GetNames() []string {
 }
 
 type Between struct {
+	Span
 	Not   bool
 	Ident Statement
 	Lower Statement
@@ -216,6 +231,7 @@ type Between struct {
 }
 
 type List struct {
+	Span
 	Values []Statement
 }
 
@@ -224,11 +240,15 @@ func (i List) Len() int {
 }
 
 type Placeholder struct {
+	Span
 	Statement
 }
 
 type Value struct {
+	Span
 	Literal string
+	// Prefix of string literals such as N, X, B or U&
+	Prefix string
 }
 
 func (v Value) Constant() bool {
@@ -248,13 +268,27 @@ func (v Value) False() bool {
 }
 
 type Alias struct {
+	Span
 	Statement
-	Alias string
-	As    bool
+	Alias  string
+	As     bool
+	Quoted bool
 }
 
 type Name struct {
-	Parts []string
+	Span
+	Parts  []string
+	Quoted []bool
+}
+
+// IsQuoted reports whether the i-th part of the name was written between
+// quotes in the input and is thus case sensitive
+func (n Name) IsQuoted(i int) bool {
+	return i >= 0 && i < len(n.Quoted) && n.Quoted[i]
+}
+
+func (n Name) AnyQuoted() bool {
+	return slices.Contains(n.Quoted, true)
 }
 
 func (n Name) All() bool {
@@ -0,0 +1,175 @@
+package ast
+
+import (
+	"reflect"
+)
+
+// Path is the list of the ancestors of a node. The root statement is the
+// first element and the direct parent of the node the last one.
+type Path []Statement
+
+func (p Path) Parent() Statement {
+	if len(p) == 0 {
+		return nil
+	}
+	return p[len(p)-1]
+}
+
+// Inside reports whether one of the ancestors of a node is of type T
+func Inside[T Statement](p Path) bool {
+	for i := range p {
+		if _, ok := p[i].(T); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Visitor is called by Walk for each node found in a statement. If the
+// returned Visitor is nil, the children of the node are not visited.
+type Visitor interface {
+	Visit(Statement, Path) Visitor
+}
+
+type inspector func(Statement, Path) bool
+
+func (fn inspector) Visit(stmt Statement, path Path) Visitor {
+	if fn(stmt, path) {
+		return fn
+	}
+	return nil
+}
+
+// Walk traverses stmt in depth first order. Every field of a node holding a
+// Statement is visited, including the nodes defined by the dialects. The
+// comments attached to a statement by Node are skipped.
+func Walk(v Visitor, stmt Statement) {
+	walk(v, stmt, nil)
+}
+
+// Inspect is like Walk but calls fn for each node. The children of a node are
+// visited only when fn returns true.
+func Inspect(stmt Statement, fn func(Statement, Path) bool) {
+	Walk(inspector(fn), stmt)
+}
+
+func walk(v Visitor, stmt Statement, path Path) {
+	if n, ok := stmt.(Node); ok {
+		stmt = n.Statement
+	}
+	if stmt == nil {
+		return
+	}
+	if v = v.Visit(stmt, path); v == nil {
+		return
+	}
+	path = append(path, stmt)
+	for _, c := range children(stmt) {
+		walk(v, c, path[:len(path):len(path)])
+	}
+}
+
+// Rewrite transforms stmt from the bottom up: the children of a node are
+// rewritten before fn is called with the node itself. The result of fn
+// replaces the node in its parent. Nodes are copied, stmt is left unchanged.
+func Rewrite(stmt Statement, fn func(Statement) Statement) Statement {
+	if n, ok := stmt.(Node); ok {
+		n.Statement = Rewrite(n.Statement, fn)
+		return n
+	}
+	if stmt == nil {
+		return nil
+	}
+	v := reflect.ValueOf(stmt)
+	if v.Kind() != reflect.Struct {
+		return fn(stmt)
+	}
+	c := reflect.New(v.Type()).Elem()
+	c.Set(v)
+	for i := 0; i < c.NumField(); i++ {
+		if !isChild(c.Type().Field(i)) {
+			continue
+		}
+		rewriteField(c.Field(i), fn)
+	}
+	return fn(c.Interface())
+}
+
+func rewriteField(f reflect.Value, fn func(Statement) Statement) {
+	switch {
+	case f.Kind() == reflect.Slice:
+		if f.IsNil() {
+			return
+		}
+		s := reflect.MakeSlice(f.Type(), f.Len(), f.Len())
+		for i := 0; i < f.Len(); i++ {
+			s.Index(i).Set(f.Index(i))
+			rewriteField(s.Index(i), fn)
+		}
+		f.Set(s)
+	case f.Kind() == reflect.Interface:
+		if f.IsNil() {
+			return
+		}
+		if x := Rewrite(f.Interface(), fn); x == nil {
+			f.Set(reflect.Zero(f.Type()))
+		} else {
+			f.Set(reflect.ValueOf(x))
+		}
+	default:
+		x := reflect.ValueOf(Rewrite(f.Interface(), fn))
+		if x.IsValid() && x.Type().AssignableTo(f.Type()) {
+			f.Set(x)
+		}
+	}
+}
+
+var (
+	stmtType = reflect.TypeOf((*Statement)(nil)).Elem()
+	locType  = reflect.TypeOf((*Locatable)(nil)).Elem()
+)
+
+// isChild reports whether a field of a node holds other nodes: a Statement,
+// a struct node such as Type or FrameSpec or a slice of them
+func isChild(f reflect.StructField) bool {
+	if !f.IsExported() || f.Type == spanType {
+		return false
+	}
+	t := f.Type
+	if t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	if t == stmtType {
+		return true
+	}
+	return t.Kind() == reflect.Struct && t.Implements(locType)
+}
+
+func children(stmt Statement) []Statement {
+	v := reflect.ValueOf(stmt)
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	var list []Statement
+	for i := 0; i < v.NumField(); i++ {
+		if !isChild(v.Type().Field(i)) {
+			continue
+		}
+		f := v.Field(i)
+		if f.Kind() != reflect.Slice {
+			list = appendChild(list, f)
+			continue
+		}
+		for j := 0; j < f.Len(); j++ {
+			list = appendChild(list, f.Index(j))
+		}
+	}
+	return list
+}
+
+func appendChild(list []Statement, f reflect.Value) []Statement {
+	if f.Kind() == reflect.Interface && f.IsNil() {
+		return list
+	}
+	return append(list, f.Interface())
+}
@@ -3,135 +3,99 @@ package complexity
 import (
 	"errors"
 	"io"
-	"slices"
 
+	"github.com/midbel/sweet/internal/lang"
 	"github.com/midbel/sweet/internal/lang/ast"
-	"github.com/midbel/sweet/internal/lang/parser"
+	_ "github.com/midbel/sweet/internal/lang/parser"
 )
 
-func Complexity(r io.Reader) (int, error) {
-	p, err := parser.NewParser(r)
+// Measure is the complexity of one statement and where it has been found
+type Measure struct {
+	ast.Span
+	Score int
+}
+
+func Complexity(r io.Reader, dialect string) (int, error) {
+	list, err := Measures(r, dialect)
+
+	var total int
+	for _, m := range list {
+		total += m.Score
+	}
+	return total, err
+}
+
+// Measures gives the complexity of each statement found in r
+func Measures(r io.Reader, dialect string) ([]Measure, error) {
+	d, err := lang.GetDialect(dialect)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
-	var total int
+	p, err := d.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	rp, recover := p.(lang.Recoverable)
+	if recover {
+		rp.SetRecover(true)
+	}
+	var list []Measure
 	for {
 		stmt, err := p.Parse()
 		if err != nil {
 			if errors.Is(err, io.EOF) {
 				break
 			}
-			return total, nil
+			return list, err
+		}
+		if x, ok := stmt.(ast.Invalid); ok {
+			if stmt = x.Statement; stmt == nil {
+				continue
+			}
+		}
+		m := Measure{
+			Span:  ast.SpanOf(stmt),
+			Score: measureQuery(stmt),
+		}
+		list = append(list, m)
+	}
+	if recover {
+		if errs := rp.Errors(); len(errs) > 0 {
+			return list, lang.ParseErrors(errs)
 		}
-		total += measureQuery(stmt)
 	}
-	return total, nil
+	return list, nil
 }
 
 func measureQuery(stmt ast.Statement) int {
 	var total int
+	ast.Inspect(stmt, func(stmt ast.Statement, _ ast.Path) bool {
+		total += measureNode(stmt)
+		return true
+	})
+	return total
+}
+
+// measureNode gives the number of paths added by a node without taking its
+// children into account
+func measureNode(stmt ast.Statement) int {
 	switch stmt := stmt.(type) {
 	case ast.TruncateStatement:
-		total++
-	case ast.DeleteStatement:
-	case ast.UpdateStatement:
-	case ast.InsertStatement:
-	case ast.MergeStatement:
-	case ast.ValuesStatement:
-	case ast.CallStatement:
-	case ast.UnionStatement:
-	case ast.ExceptStatement:
-	case ast.IntersectStatement:
+		return 1
 	case ast.SelectStatement:
-		total = measureSelect(stmt)
-	case ast.WithStatement:
-		total = measureWith(stmt)
-	case ast.CteStatement:
-		total = measureCte(stmt)
-	case ast.Set:
-	case ast.If:
-	case ast.While:
-	case ast.Return:
-	case ast.Alias:
-		total = measureQuery(stmt.Statement)
-	case ast.Join:
-		total = measureJoin(stmt)
-	case ast.Case:
-		total = measureCase(stmt)
-	case ast.When:
-		total = measureWhen(stmt)
-	case ast.Group:
-		total = measureQuery(stmt.Statement)
+		return 1
 	case ast.Binary:
-		total = measureBinary(stmt)
-	case ast.Unary:
-		total = measureQuery(stmt.Right)
-	case ast.Exists:
-	case ast.Between:
-	case ast.All:
-	case ast.Any:
-	case ast.Is:
+		if stmt.IsRelation() {
+			return 1
+		}
+		return 0
+	case ast.Case:
+		total := len(stmt.Body)
+		if stmt.Else != nil {
+			total++
+		}
+		return total
 	default:
-		// pass
+		return 0
 	}
-	return total
-}
-
-func measureBinary(stmt ast.Binary) int {
-	var total int
-	if stmt.IsRelation() {
-		total++
-	}
-	total += measureQuery(stmt.Left)
-	total += measureQuery(stmt.Right)
-	return total
-
-}
-
-func measureCase(stmt ast.Case) int {
-	var total int
-	for _, q := range stmt.Body {
-		total++
-		total += measureQuery(q)
-	}
-	if stmt.Else != nil {
-		total++
-		total += measureQuery(stmt.Else)
-	}
-	return total
-}
-
-func measureWhen(stmt ast.When) int {
-	return measureQuery(stmt.Cdt) + measureQuery(stmt.Body)
-}
-
-func measureJoin(stmt ast.Join) int {
-	var total int
-	if a, ok := stmt.Table.(ast.Alias); ok {
-		stmt.Table = a.Statement
-		return measureJoin(stmt)
-	} else {
-		total += measureQuery(stmt.Table)
-	}
-	return total + measureQuery(stmt.Where)
-}
-
-func measureSelect(stmt ast.SelectStatement) int {
-	var (
-		total int
-		list  []ast.Statement
-	)
-	list = slices.Concat(list, stmt.Tables, stmt.Columns)
-	for _, q := range list {
-		total += measureQuery(q)
-	}
-	return total + 1
-}
-
-func measureWith(stmt ast.WithStatement) int {
-	return 0
-}
-
-func measureCte(stmt ast.CteStatement) int {
-	return 0
 }
@@ -0,0 +1,175 @@
+package cst
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/midbel/sweet/internal/lang"
+	"github.com/midbel/sweet/internal/lang/ast"
+	"github.com/midbel/sweet/internal/token"
+)
+
+var (
+	ErrLocation = errors.New("node without location")
+	ErrOverlap  = errors.New("edit overlaps a previous edit")
+)
+
+// Tree is the concrete syntax of a file: the statements parsed from it and
+// every token of the input with the blanks found before it. The spans of the
+// nodes link them to the tokens they have been parsed from.
+//
+// Writing a Tree gives back its input byte for byte, except the parts
+// replaced by the edits registered on the tree.
+type Tree struct {
+	File       string
+	Statements []ast.Statement
+	Tokens     []token.Token
+
+	edits []edit
+}
+
+type edit struct {
+	Start int
+	End   int
+	Text  string
+}
+
+// Parse parses r with the given dialect keeping every token. Statements that
+// can not be parsed are kept as ast.Invalid.
+func Parse(r io.Reader, dialect lang.Dialect) (*Tree, error) {
+	scan, err := dialect.Scan(r)
+	if err != nil {
+		return nil, err
+	}
+	scan.KeepTrivia()
+
+	ps, err := dialect.NewParser(scan)
+	if err != nil {
+		return nil, err
+	}
+	if rp, ok := ps.(lang.Recoverable); ok {
+		rp.SetRecover(true)
+	}
+	tree := Tree{
+		File: scan.File(),
+	}
+	for {
+		stmt, err := ps.Parse()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		tree.Statements = append(tree.Statements, stmt)
+	}
+	for tok := scan.Scan(); tok.Type != token.EOF; tok = scan.Scan() {
+	}
+	if err := scan.Err(); err != nil {
+		return nil, err
+	}
+	tree.Tokens = scan.Tokens()
+	return &tree, nil
+}
+
+// TokensOf gives the tokens covered by the span of the given node
+func (t *Tree) TokensOf(stmt ast.Statement) []token.Token {
+	span := ast.SpanOf(stmt)
+	if span.IsZero() {
+		return nil
+	}
+	var list []token.Token
+	for _, tok := range t.Tokens {
+		if tok.Type == token.EOF || tok.Offset < span.Start.Offset {
+			continue
+		}
+		if tok.End.Offset > span.End.Offset {
+			break
+		}
+		list = append(list, tok)
+	}
+	return list
+}
+
+// Text gives the node as it was written in the input
+func (t *Tree) Text(stmt ast.Statement) string {
+	var str strings.Builder
+	for i, tok := range t.TokensOf(stmt) {
+		if i > 0 {
+			str.WriteString(tok.Leading)
+		}
+		str.WriteString(tok.Raw)
+	}
+	return str.String()
+}
+
+// Replace registers an edit replacing the text of the given node. The blanks
+// and comments around the node are left untouched.
+func (t *Tree) Replace(stmt ast.Statement, text string) error {
+	return t.ReplaceSpan(ast.SpanOf(stmt), text)
+}
+
+// ReplaceSpan registers an edit replacing the text found in the given span
+func (t *Tree) ReplaceSpan(span ast.Span, text string) error {
+	if span.IsZero() {
+		return ErrLocation
+	}
+	if span.File != t.File {
+		return fmt.Errorf("%s: node from another file than %s", span.Pos(), t.File)
+	}
+	e := edit{
+		Start: span.Start.Offset,
+		End:   span.End.Offset,
+		Text:  text,
+	}
+	for _, x := range t.edits {
+		if e.Start < x.End && x.Start < e.End {
+			return fmt.Errorf("%s: %w", span.Pos(), ErrOverlap)
+		}
+	}
+	t.edits = append(t.edits, e)
+	slices.SortFunc(t.edits, func(a, b edit) int {
+		return a.Start - b.Start
+	})
+	return nil
+}
+
+// Reset discards all the edits registered on the tree
+func (t *Tree) Reset() {
+	t.edits = t.edits[:0]
+}
+
+func (t *Tree) String() string {
+	var str strings.Builder
+	t.WriteTo(&str)
+	return str.String()
+}
+
+// WriteTo writes the input of the tree with its edits applied
+func (t *Tree) WriteTo(w io.Writer) (int64, error) {
+	var (
+		src = t.source()
+		pos int
+		str strings.Builder
+	)
+	for _, e := range t.edits {
+		str.WriteString(src[pos:e.Start])
+		str.WriteString(e.Text)
+		pos = e.End
+	}
+	str.WriteString(src[pos:])
+	n, err := io.WriteString(w, str.String())
+	return int64(n), err
+}
+
+func (t *Tree) source() string {
+	var str strings.Builder
+	for _, tok := range t.Tokens {
+		str.WriteString(tok.Leading)
+		str.WriteString(tok.Raw)
+	}
+	return str.String()
+}
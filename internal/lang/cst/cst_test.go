@@ -0,0 +1,80 @@
+package cst_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/midbel/sweet/internal/lang"
+	"github.com/midbel/sweet/internal/lang/ast"
+	"github.com/midbel/sweet/internal/lang/cst"
+	_ "github.com/midbel/sweet/internal/lang/parser"
+)
+
+const query = `-- list of users
+SELECT  u.id,   u.Name as "Label"
+  from users u -- main table
+	where u.id in (1,2) ;
+
+select * from users ;
+`
+
+func TestTreeRoundTrip(t *testing.T) {
+	tree := mustParse(t, query)
+	if got := tree.String(); got != query {
+		t.Errorf("input not preserved!\nwant %q\ngot  %q", query, got)
+	}
+	if len(tree.Statements) != 2 {
+		t.Fatalf("statements count mismatched! want 2, got %d", len(tree.Statements))
+	}
+}
+
+func TestTreeReplace(t *testing.T) {
+	tree := mustParse(t, query)
+
+	var count int
+	for _, stmt := range tree.Statements {
+		ast.Inspect(stmt, func(n ast.Statement, _ ast.Path) bool {
+			if n, ok := n.(ast.Name); ok && n.Ident() == "users" {
+				if err := tree.Replace(n, "accounts"); err != nil {
+					t.Errorf("fail to replace %s: %s", n.Ident(), err)
+				}
+				count++
+			}
+			return true
+		})
+	}
+	if count != 2 {
+		t.Fatalf("table not found! want 2 names, got %d", count)
+	}
+	want := strings.ReplaceAll(query, "from users", "from accounts")
+	if got := tree.String(); got != want {
+		t.Errorf("edit mismatched!\nwant %q\ngot  %q", want, got)
+	}
+	stmt := tree.Statements[0]
+	if n, ok := stmt.(ast.Node); ok {
+		stmt = n.Statement
+	}
+	sel, ok := stmt.(ast.SelectStatement)
+	if !ok {
+		t.Fatalf("select expected! got %T", stmt)
+	}
+	if got := tree.Text(sel.Where); got != "u.id in (1,2)" {
+		t.Errorf("text mismatched! got %q", got)
+	}
+	if err := tree.Replace(sel, ""); err == nil {
+		t.Errorf("overlapping edit should be rejected")
+	}
+}
+
+func mustParse(t *testing.T, query string) *cst.Tree {
+	t.Helper()
+	d, err := lang.GetDialect("")
+	if err != nil {
+		t.Fatalf("fail to get dialect: %s", err)
+	}
+	tree, err := cst.Parse(strings.NewReader(query), d)
+	if err != nil {
+		t.Fatalf("fail to parse query: %s", err)
+	}
+	return tree
+}
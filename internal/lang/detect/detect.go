@@ -0,0 +1,147 @@
+package detect
+
+import (
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/midbel/sweet/internal/lang"
+)
+
+// maxHits limits the number of times a single signal is counted so that a
+// long script repeating the same construct does not drown the others
+const maxHits = 3
+
+type Guess struct {
+	Dialect    string
+	Score      int
+	Confidence float64
+	Signals    []string
+}
+
+type signal struct {
+	Dialect string
+	Name    string
+	Weight  int
+	*regexp.Regexp
+}
+
+var signals = []signal{
+	{Dialect: "mysql", Name: "backtick quoted identifier", Weight: 3, Regexp: regexp.MustCompile("`[^`\n]+`")},
+	{Dialect: "mysql", Name: "LIMIT offset, count", Weight: 3, Regexp: regexp.MustCompile(`(?i)\bLIMIT\s+\d+\s*,\s*\d+`)},
+	{Dialect: "mysql", Name: "AUTO_INCREMENT", Weight: 2, Regexp: regexp.MustCompile(`(?i)\bAUTO_INCREMENT\b`)},
+	{Dialect: "mssql", Name: "bracket quoted identifier", Weight: 2, Regexp: regexp.MustCompile(`(^|[\s.,(])\[[A-Za-z_][^\]\n]*\]`)},
+	{Dialect: "mssql", Name: "SELECT TOP", Weight: 3, Regexp: regexp.MustCompile(`(?i)\bSELECT\s+(DISTINCT\s+)?TOP\b`)},
+	{Dialect: "mssql", Name: "GO batch separator", Weight: 3, Regexp: regexp.MustCompile(`(?im)^\s*GO\s*$`)},
+	{Dialect: "mssql", Name: "variable declaration", Weight: 3, Regexp: regexp.MustCompile(`(?i)\bDECLARE\s+@\w+`)},
+	{Dialect: "mssql", Name: "variable reference", Weight: 1, Regexp: regexp.MustCompile(`[=(,<>+\-*/]\s*@\w+`)},
+	{Dialect: "postgres", Name: "dollar quoted string", Weight: 3, Regexp: regexp.MustCompile(`\$\w*\$`)},
+	{Dialect: "postgres", Name: "cast operator", Weight: 2, Regexp: regexp.MustCompile(`::\s*\w`)},
+	{Dialect: "postgres", Name: "ILIKE", Weight: 1, Regexp: regexp.MustCompile(`(?i)\bILIKE\b`)},
+	{Dialect: "sqlite", Name: "PRAGMA", Weight: 3, Regexp: regexp.MustCompile(`(?i)\bPRAGMA\b`)},
+	{Dialect: "sqlite", Name: "AUTOINCREMENT", Weight: 2, Regexp: regexp.MustCompile(`(?i)\bAUTOINCREMENT\b`)},
+	{Dialect: "oracle", Name: "slash block terminator", Weight: 3, Regexp: regexp.MustCompile(`(?m)^/\s*$`)},
+	{Dialect: "oracle", Name: "outer join operator", Weight: 3, Regexp: regexp.MustCompile(`\(\+\)`)},
+	{Dialect: "oracle", Name: "CONNECT BY", Weight: 2, Regexp: regexp.MustCompile(`(?i)\bCONNECT\s+BY\b`)},
+	{Dialect: "oracle", Name: "%TYPE attribute", Weight: 2, Regexp: regexp.MustCompile(`(?i)%(ROW)?TYPE\b`)},
+	{Dialect: "oracle", Name: "oracle function", Weight: 1, Regexp: regexp.MustCompile(`(?i)\b(NVL|DECODE|ROWNUM|SYSDATE)\b`)},
+	{Dialect: "db2", Name: "SIGNAL SQLSTATE", Weight: 3, Regexp: regexp.MustCompile(`(?i)\bSIGNAL\s+SQLSTATE\b`)},
+	{Dialect: "db2", Name: "isolation clause", Weight: 2, Regexp: regexp.MustCompile(`(?i)\bWITH\s+(UR|CS|RS|RR)\b`)},
+}
+
+var macros = regexp.MustCompile(`(?im)^\s*@(format|lint|include|define|use|var|env)\b.*$`)
+
+// Detect reads the whole input and gives the list of registered dialects that
+// it could be written in, from the most to the least probable one. The
+// default dialect is always part of the list since it is the fallback when
+// no other signal is found.
+func Detect(r io.Reader) ([]Guess, error) {
+	buf, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return DetectString(string(buf)), nil
+}
+
+func DetectString(str string) []Guess {
+	str = macros.ReplaceAllString(strip(str), "")
+
+	var (
+		all   = make(map[string]*Guess)
+		total = 1
+	)
+	all[lang.DefaultDialect] = &Guess{
+		Dialect: lang.DefaultDialect,
+		Score:   1,
+	}
+	for _, s := range signals {
+		if _, err := lang.GetDialect(s.Dialect); err != nil {
+			continue
+		}
+		n := len(s.FindAllStringIndex(str, maxHits))
+		if n == 0 {
+			continue
+		}
+		g, ok := all[s.Dialect]
+		if !ok {
+			g = &Guess{
+				Dialect: s.Dialect,
+			}
+			all[s.Dialect] = g
+		}
+		g.Score += n * s.Weight
+		g.Signals = append(g.Signals, s.Name)
+		total += n * s.Weight
+	}
+	var list []Guess
+	for _, g := range all {
+		g.Confidence = float64(g.Score) / float64(total)
+		list = append(list, *g)
+	}
+	slices.SortFunc(list, func(a, b Guess) int {
+		if a.Score == b.Score {
+			return strings.Compare(a.Dialect, b.Dialect)
+		}
+		return b.Score - a.Score
+	})
+	return list
+}
+
+// strip removes the content of comments and single quoted strings to avoid
+// signals being picked from text that is not SQL code
+func strip(str string) string {
+	var (
+		ws    strings.Builder
+		runes = []rune(str)
+	)
+	for i := 0; i < len(runes); i++ {
+		switch c := runes[i]; {
+		case c == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+			if i < len(runes) {
+				ws.WriteRune('\n')
+			}
+		case c == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			for i += 2; i < len(runes) && !(runes[i] == '*' && i+1 < len(runes) && runes[i+1] == '/'); i++ {
+				if runes[i] == '\n' {
+					ws.WriteRune('\n')
+				}
+			}
+			i++
+		case c == '\'':
+			ws.WriteRune(c)
+			for i++; i < len(runes) && runes[i] != '\''; i++ {
+				if runes[i] == '\n' {
+					ws.WriteRune('\n')
+				}
+			}
+			ws.WriteRune('\'')
+		default:
+			ws.WriteRune(c)
+		}
+	}
+	return ws.String()
+}
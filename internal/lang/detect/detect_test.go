@@ -0,0 +1,73 @@
+package detect_test
+
+import (
+	"testing"
+
+	_ "github.com/midbel/sweet/internal/db2"
+	"github.com/midbel/sweet/internal/lang/detect"
+	_ "github.com/midbel/sweet/internal/lang/parser"
+	_ "github.com/midbel/sweet/internal/ms"
+	_ "github.com/midbel/sweet/internal/my"
+	_ "github.com/midbel/sweet/internal/ora"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		Query   string
+		Dialect string
+	}{
+		{
+			Query:   "select * from employees where dept = 'IT'",
+			Dialect: "ansi",
+		},
+		{
+			Query:   "select `id`, `name` from `users` limit 10, 20",
+			Dialect: "mysql",
+		},
+		{
+			Query:   "select top 10 [id], [name] from [dbo].[users]\nGO",
+			Dialect: "mssql",
+		},
+		{
+			Query:   "declare @total int;\nset @total = (select count(*) from users);",
+			Dialect: "mssql",
+		},
+		{
+			Query:   "@format upperize all\nselect id::text from users",
+			Dialect: "postgres",
+		},
+		{
+			Query:   "create function f() returns int as $$ select 1 $$ language sql",
+			Dialect: "postgres",
+		},
+		{
+			Query:   "pragma foreign_keys = on",
+			Dialect: "sqlite",
+		},
+		{
+			Query:   "select e.name from emp e, dept d where e.dept = d.id(+) connect by prior e.id = e.manager\n/",
+			Dialect: "oracle",
+		},
+		{
+			Query:   "select `id` from users -- limit 1, 2 with [brackets]",
+			Dialect: "mysql",
+		},
+		{
+			Query:   "select 'select top 10 [id] from t' from users",
+			Dialect: "ansi",
+		},
+	}
+	for _, c := range tests {
+		list := detect.DetectString(c.Query)
+		if len(list) == 0 {
+			t.Errorf("%s: no dialect detected", c.Query)
+			continue
+		}
+		if got := list[0].Dialect; got != c.Dialect {
+			t.Errorf("%s: dialect mismatched! want %s, got %s", c.Query, c.Dialect, got)
+		}
+		if list[0].Confidence <= 0 || list[0].Confidence > 1 {
+			t.Errorf("%s: invalid confidence %f", c.Query, list[0].Confidence)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package lang
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/midbel/sweet/internal/keywords"
+	"github.com/midbel/sweet/internal/scanner"
+)
+
+const DefaultDialect = "ansi"
+
+// Folding tells how a dialect transforms the case of unquoted identifiers
+type Folding int
+
+const (
+	FoldNone Folding = iota
+	FoldUpper
+	FoldLower
+)
+
+func (f Folding) Fold(str string) string {
+	switch f {
+	case FoldUpper:
+		return strings.ToUpper(str)
+	case FoldLower:
+		return strings.ToLower(str)
+	default:
+		return str
+	}
+}
+
+// Dialect describes a SQL dialect: how its input should be tokenized and
+// parsed, how its queries should be written back and which lint rules are
+// enabled by default, on top of the common ones, when no configuration says
+// otherwise.
+type Dialect struct {
+	Name       string
+	Aliases    []string
+	Keywords   keywords.Set
+	Tokenizers []scanner.Tokenizer
+	Formatter  Formatter
+	Rules      []string
+	Folding    Folding
+
+	NewParser func(*scanner.Scanner) (Parser, error)
+}
+
+func (d Dialect) Scan(r io.Reader) (*scanner.Scanner, error) {
+	scan, err := scanner.Scan(r, d.Keywords)
+	if err != nil {
+		return nil, err
+	}
+	for i := range d.Tokenizers {
+		scan.Register(d.Tokenizers[i])
+	}
+	return scan, nil
+}
+
+// NeedQuote reports whether the given identifier has to be quoted to keep its
+// meaning: it is a keyword, contains characters not allowed in a regular
+// identifier or its case would be changed by the dialect once unquoted.
+func (d Dialect) NeedQuote(ident string) bool {
+	if !IsRegularIdent(ident) {
+		return true
+	}
+	if d.IsKeyword(ident) {
+		return true
+	}
+	if d.Folding == FoldNone {
+		return false
+	}
+	return d.Folding.Fold(ident) != ident
+}
+
+// IsKeyword reports whether the given identifier is a keyword of the dialect
+// or the first word of one of its compound keywords. The keywords of the
+// dialect are not expected to be sorted.
+func (d Dialect) IsKeyword(ident string) bool {
+	return slices.ContainsFunc(d.Keywords, func(kw []string) bool {
+		return len(kw) > 0 && strings.EqualFold(kw[0], ident)
+	})
+}
+
+func (d Dialect) Parse(r io.Reader) (Parser, error) {
+	scan, err := d.Scan(r)
+	if err != nil {
+		return nil, err
+	}
+	return d.NewParser(scan)
+}
+
+var registry = struct {
+	sync.RWMutex
+	dialects map[string]Dialect
+	aliases  map[string]string
+}{
+	dialects: make(map[string]Dialect),
+	aliases:  make(map[string]string),
+}
+
+// Register makes a dialect available under its name and aliases. It panics
+// if the name or one of its aliases is already in use.
+func Register(d Dialect) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	if d.Name == "" {
+		panic("lang: dialect registered without name")
+	}
+	if d.NewParser == nil {
+		panic(fmt.Sprintf("lang: dialect %s registered without parser", d.Name))
+	}
+	if d.Formatter == nil {
+		d.Formatter = GetFormatter()
+	}
+	names := append([]string{d.Name}, d.Aliases...)
+	for _, n := range names {
+		n = strings.ToLower(n)
+		if _, ok := registry.aliases[n]; ok {
+			panic(fmt.Sprintf("lang: dialect %s registered twice", n))
+		}
+		registry.aliases[n] = d.Name
+	}
+	registry.dialects[d.Name] = d
+}
+
+// GetDialect returns the dialect registered under the given name or alias.
+// An empty name gives the default dialect.
+func GetDialect(name string) (Dialect, error) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	if name == "" {
+		name = DefaultDialect
+	}
+	n, ok := registry.aliases[strings.ToLower(name)]
+	if !ok {
+		return Dialect{}, fmt.Errorf("%s unsupported dialect", name)
+	}
+	return registry.dialects[n], nil
+}
+
+// IsRegularIdent reports whether the given string can be used as identifier
+// without being quoted
+func IsRegularIdent(ident string) bool {
+	if ident == "" {
+		return false
+	}
+	for i, r := range ident {
+		switch {
+		case r == '_' || unicode.IsLetter(r):
+		case i > 0 && unicode.IsDigit(r):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// IsMixedCase reports whether the given string contains both lower and upper
+// case letters
+func IsMixedCase(ident string) bool {
+	return strings.ToLower(ident) != ident && strings.ToUpper(ident) != ident
+}
+
+// Dialects gives the names of all registered dialects
+func Dialects() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	var list []string
+	for n := range registry.dialects {
+		list = append(list, n)
+	}
+	slices.Sort(list)
+	return list
+}
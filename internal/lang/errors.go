@@ -0,0 +1,64 @@
+package lang
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/midbel/sweet/internal/token"
+)
+
+// Codes given to syntax errors. They are part of the output of sweet and
+// should never be changed once published.
+const (
+	CodeSyntax             = "E001"
+	CodeInvalidToken       = "E002"
+	CodeKeywordExpected    = "E003"
+	CodeIdentExpected      = "E004"
+	CodeValueExpected      = "E005"
+	CodeMissingParen       = "E006"
+	CodeMissingEol         = "E007"
+	CodeUnknownOperator    = "E008"
+	CodeUnsupportedKeyword = "E009"
+	CodeUnexpectedKeyword  = "E010"
+	CodeMacro              = "E011"
+)
+
+type ParseError struct {
+	token.Token
+	End     token.Position
+	File    string
+	Code    string
+	Reason  string
+	Context string
+	// Query is the input found before the error and QueryLine the line
+	// number of its first line
+	Query     string
+	QueryLine int
+	Expected  []string
+	Hint      string
+}
+
+func (e ParseError) Literal() string {
+	return e.Token.Literal
+}
+
+func (e ParseError) Position() token.Position {
+	return e.Token.Position
+}
+
+func (e ParseError) Error() string {
+	pos := e.Token.Position
+	return fmt.Sprintf("[%s] at %d:%d, %s", e.Context, pos.Line, pos.Column, e.Reason)
+}
+
+// ParseErrors is the list of all the syntax errors found in an input parsed
+// in recovery mode
+type ParseErrors []ParseError
+
+func (e ParseErrors) Error() string {
+	var list []string
+	for i := range e {
+		list = append(list, e[i].Error())
+	}
+	return strings.Join(list, "\n")
+}
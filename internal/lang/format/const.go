@@ -26,6 +26,46 @@ const (
 		RewriteGroupByAggr
 )
 
+var rewriteNames = []struct {
+	Name string
+	Rule RewriteRule
+}{
+	{"use-std-expr", RewriteStdExpr},
+	{"use-std-op", RewriteStdOp},
+	{"missing-cte-alias", RewriteMissCteAlias},
+	{"missing-view-alias", RewriteMissViewAlias},
+	{"subquery-as-cte", RewriteWithCte},
+	{"cte-as-subquery", RewriteWithSubqueries},
+	{"join-as-subquery", RewriteJoinSubquery},
+	{"join-without-literal", RewriteJoinPredicate},
+	{"groupby-group", RewriteGroupByGroup},
+	{"groupby-aggr", RewriteGroupByAggr},
+}
+
+// GetRewriteRule gives the rule with the given name. all selects every rule.
+func GetRewriteRule(name string) (RewriteRule, bool) {
+	if name == "all" {
+		return RewriteAll, true
+	}
+	for _, n := range rewriteNames {
+		if n.Name == name {
+			return n.Rule, true
+		}
+	}
+	return 0, false
+}
+
+// Names gives the names of the rules set in r
+func (r RewriteRule) Names() []string {
+	var list []string
+	for _, n := range rewriteNames {
+		if r&n.Rule != 0 {
+			list = append(list, n.Name)
+		}
+	}
+	return list
+}
+
 func (r RewriteRule) All() bool {
 	return r == RewriteAll
 }
@@ -88,6 +128,47 @@ const (
 	UpperType
 )
 
+// GetUpperMode gives the mode with the given name
+func GetUpperMode(name string) (UpperMode, bool) {
+	switch name {
+	case "all":
+		return UpperId | UpperKw | UpperFn | UpperType, true
+	case "keyword", "kw":
+		return UpperKw, true
+	case "function", "fn":
+		return UpperFn, true
+	case "identifier", "ident", "id":
+		return UpperId, true
+	case "type":
+		return UpperType, true
+	case "none":
+		return UpperNone, true
+	default:
+		return 0, false
+	}
+}
+
+// Names gives the names of the modes set in u
+func (u UpperMode) Names() []string {
+	var list []string
+	if u.Keyword() {
+		list = append(list, "keyword")
+	}
+	if u.Function() {
+		list = append(list, "function")
+	}
+	if u.Identifier() {
+		list = append(list, "identifier")
+	}
+	if u.Type() {
+		list = append(list, "type")
+	}
+	if len(list) == 0 {
+		list = append(list, "none")
+	}
+	return list
+}
+
 func (u UpperMode) All() bool {
 	return u.Identifier() && u.Function() && u.Keyword() && u.Type()
 }
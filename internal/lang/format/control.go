@@ -114,3 +114,28 @@ func (w *Writer) FormatCall(stmt ast.CallStatement) error {
 	w.WriteNL()
 	return nil
 }
+
+func (w *Writer) FormatExecute(stmt ast.ExecuteStatement) error {
+	kw, _ := stmt.Keyword()
+	w.WriteKeyword(kw)
+	w.WriteBlank()
+	if err := w.FormatExpr(stmt.Statement, false); err != nil {
+		return err
+	}
+	if len(stmt.Using) == 0 {
+		return nil
+	}
+	w.WriteBlank()
+	w.WriteKeyword("USING")
+	w.WriteBlank()
+	for i, a := range stmt.Using {
+		if i > 0 {
+			w.WriteString(",")
+			w.WriteBlank()
+		}
+		if err := w.FormatExpr(a, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
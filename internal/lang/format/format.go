@@ -8,21 +8,13 @@ import (
 	"os"
 	"strings"
 
+	"github.com/midbel/sweet/internal/config"
 	"github.com/midbel/sweet/internal/lang"
 	"github.com/midbel/sweet/internal/lang/ast"
-	"github.com/midbel/sweet/internal/lang/parser"
+	_ "github.com/midbel/sweet/internal/lang/parser"
+	"github.com/midbel/sweet/internal/ora"
 )
 
-type ansiFormatter struct{}
-
-func (_ ansiFormatter) Quote(str string) string {
-	return fmt.Sprintf("\"%s\"", str)
-}
-
-func GetFormatter() lang.Formatter {
-	return ansiFormatter{}
-}
-
 type Writer struct {
 	inner *bufio.Writer
 
@@ -43,6 +35,7 @@ type Writer struct {
 	noColor   bool
 	currDepth int
 
+	dialect lang.Dialect
 	lang.Formatter
 }
 
@@ -52,27 +45,49 @@ func NewWriter(w io.Writer) *Writer {
 		UseIndent:    4,
 		UseSpace:     true,
 		UseKeepSpace: true,
-		Formatter:    ansiFormatter{},
+		Formatter:    lang.GetFormatter(),
 		Upperize:     UpperNone,
 		Rules:        0,
 	}
 	if w != os.Stdout {
 		ws.noColor = true
 	}
+	ws.dialect, _ = lang.GetDialect(lang.DefaultDialect)
 	return &ws
 }
 
+// Clone gives a writer with the same settings writing to out. Each clone has
+// its own state, so that clones can be used concurrently to format several
+// inputs. Colors are used by the clone when they are used by w.
+func (w *Writer) Clone(out io.Writer) *Writer {
+	c := *w
+	c.inner = bufio.NewWriter(out)
+	c.currDepth = 0
+	return &c
+}
+
 func Compact(w io.Writer) *Writer {
 	ws := NewWriter(w)
 	ws.Compact = true
 	return ws
 }
 
-func (w *Writer) configure(ps lang.Parser) {
-	p, ok := ps.(*parser.Parser)
-	if !ok {
-		return
+// SetDialect selects the dialect used to parse the input and to quote the
+// identifiers and keywords written
+func (w *Writer) SetDialect(name string) error {
+	d, err := lang.GetDialect(name)
+	if err != nil {
+		return err
 	}
+	w.dialect = d
+	w.Formatter = d.Formatter
+	return nil
+}
+
+// Configure applies the settings of the format section of cfg. Only the
+// settings found in cfg are changed.
+func (w *Writer) Configure(cfg *config.Config) {
+	p := cfg.Sub("format")
 	w.Compact = p.GetDefaultBool("compact", w.Compact)
 	w.UseIndent = int(p.GetDefaultInt("indent", int64(w.UseIndent)))
 	w.UseSpace = p.GetDefaultBool("space", w.UseSpace)
@@ -80,55 +95,62 @@ func (w *Writer) configure(ps lang.Parser) {
 	w.UseAs = p.GetDefaultBool("as", w.UseAs)
 	w.UseQuote = p.GetDefaultBool("quote", w.UseQuote)
 	w.UseCrlf = p.GetDefaultBool("crlf", w.UseCrlf)
+	w.PrependComma = p.GetDefaultBool("comma", w.PrependComma)
 	w.KeepComment = p.GetDefaultBool("comment", w.KeepComment)
-	for _, r := range p.GetStrings("rewrite") {
-		switch r {
-		case "all":
-			w.Rules |= RewriteAll
-		case "use-std-op":
-			w.Rules |= RewriteStdOp
-		case "use-std-expr":
-			w.Rules |= RewriteStdExpr
-		case "missing-cte-alias":
-			w.Rules |= RewriteMissCteAlias
-		case "missing-view-alias":
-			w.Rules |= RewriteMissViewAlias
-		case "subquery-as-cte":
-			w.Rules |= RewriteWithCte
-		case "cte-as-subquery":
-			w.Rules |= RewriteWithSubqueries
-		case "join-as-subquery":
-			w.Rules |= RewriteJoinSubquery
-		case "join-without-literal":
-			w.Rules |= RewriteJoinPredicate
-		default:
+	if p.Get("rewrite") != nil {
+		for _, r := range p.GetStrings("rewrite") {
+			if x, ok := GetRewriteRule(r); ok {
+				w.Rules |= x
+			}
 		}
 	}
-	for _, r := range p.GetStrings("upperize") {
-		switch r {
-		case "all":
-			w.Upperize |= UpperId | UpperKw | UpperFn | UpperType
-		case "keyword", "kw":
-			w.Upperize |= UpperKw
-		case "function", "fn":
-			w.Upperize |= UpperFn
-		case "identifier", "ident", "id":
-			w.Upperize |= UpperId
-		case "type":
-			w.Upperize |= UpperType
-		case "none":
-			w.Upperize = UpperNone
-		default:
+	if p.Get("upperize") != nil {
+		for _, r := range p.GetStrings("upperize") {
+			if x, ok := GetUpperMode(r); ok && x == UpperNone {
+				w.Upperize = x
+			} else if ok {
+				w.Upperize |= x
+			}
 		}
 	}
 }
 
+// Settings gives the current settings of the writer as a configuration
+// accepted by Configure
+func (w *Writer) Settings() *config.Config {
+	p := config.Make()
+	p.Set("compact", w.Compact)
+	p.Set("indent", int64(w.UseIndent))
+	p.Set("space", w.UseSpace)
+	p.Set("keepspace", w.UseKeepSpace)
+	p.Set("as", w.UseAs)
+	p.Set("quote", w.UseQuote)
+	p.Set("crlf", w.UseCrlf)
+	p.Set("comma", w.PrependComma)
+	p.Set("comment", w.KeepComment)
+	for _, n := range w.Rules.Names() {
+		p.Add("rewrite", n)
+	}
+	for _, n := range w.Upperize.Names() {
+		p.Add("upperize", n)
+	}
+	cfg := config.Make()
+	cfg.Set("format", p)
+	return cfg
+}
+
 func (w *Writer) Format(r io.Reader) error {
-	p, err := parser.NewParser(r)
+	p, err := w.dialect.Parse(r)
 	if err != nil {
 		return err
 	}
-	w.configure(p)
+	if c, ok := p.(lang.Configurable); ok {
+		w.Configure(c.Settings())
+	}
+	rp, recover := p.(lang.Recoverable)
+	if recover {
+		rp.SetRecover(true)
+	}
 	for {
 		stmt, err := p.Parse()
 		if err != nil {
@@ -137,16 +159,44 @@ func (w *Writer) Format(r io.Reader) error {
 			}
 			return err
 		}
-		if stmt, err = w.Rewrite(stmt); err != nil {
+		if err = w.WriteStatement(stmt); err != nil {
 			return err
 		}
-		if err = w.startStatement(stmt); err != nil {
-			return err
+	}
+	if recover {
+		if errs := rp.Errors(); len(errs) > 0 {
+			return lang.ParseErrors(errs)
 		}
 	}
 	return nil
 }
 
+// WriteStatement rewrites and writes a single statement that can come from
+// another source than the parser, such as a decoded JSON document
+func (w *Writer) WriteStatement(stmt ast.Statement) error {
+	if x, ok := stmt.(ast.Invalid); ok {
+		w.writeInvalid(x)
+		return nil
+	}
+	stmt, err := w.Rewrite(stmt)
+	if err != nil {
+		return err
+	}
+	return w.startStatement(stmt)
+}
+
+// writeInvalid writes back, as it was given, a statement that can not be
+// formatted because of syntax errors
+func (w *Writer) writeInvalid(stmt ast.Invalid) {
+	defer w.Flush()
+	w.Reset()
+	w.WriteString(stmt.Query)
+	if !strings.HasSuffix(stmt.Query, ";") {
+		w.WriteEOL()
+	}
+	w.WriteNL()
+}
+
 func (w *Writer) startStatement(stmt ast.Statement) error {
 	defer w.Flush()
 
@@ -158,10 +208,41 @@ func (w *Writer) startStatement(stmt ast.Statement) error {
 		w.WriteEOL()
 		w.writeCommentAfter(stmt)
 		w.WriteNL()
+		w.writeCopyData(stmt)
 	}
 	return err
 }
 
+// writeCopyData writes, as they were given, the lines following a COPY FROM
+// STDIN. They are always written on their own lines, even in compact mode.
+func (w *Writer) writeCopyData(stmt ast.Statement) {
+	if n, ok := stmt.(ast.Node); ok {
+		stmt = n.Statement
+	}
+	c, ok := stmt.(ast.CopyStatement)
+	if !ok || c.To {
+		return
+	}
+	if n, ok := c.Target.(ast.Name); !ok || n.Ident() != "STDIN" {
+		return
+	}
+	nl := func() {
+		if w.UseCrlf {
+			w.inner.WriteRune('\r')
+		}
+		w.inner.WriteRune('\n')
+	}
+	if w.Compact {
+		nl()
+	}
+	for _, line := range c.Data {
+		w.inner.WriteString(line)
+		nl()
+	}
+	w.inner.WriteString(`\.`)
+	nl()
+}
+
 func (w *Writer) FormatStatement(stmt ast.Statement) error {
 	var err error
 	switch stmt := stmt.(type) {
@@ -177,6 +258,8 @@ func (w *Writer) FormatStatement(stmt ast.Statement) error {
 		err = w.FormatAlterTable(stmt)
 	case ast.CreateViewStatement:
 		err = w.FormatCreateView(stmt)
+	case ast.CreateIndexStatement:
+		err = w.FormatCreateIndex(stmt)
 	case ast.DropTableStatement:
 		err = w.FormatDropTable(stmt)
 	case ast.DropViewStatement:
@@ -201,6 +284,8 @@ func (w *Writer) FormatStatement(stmt ast.Statement) error {
 		err = w.FormatDelete(stmt)
 	case ast.TruncateStatement:
 		err = w.FormatTruncate(stmt)
+	case ast.CopyStatement:
+		err = w.FormatCopy(stmt)
 	case ast.MergeStatement:
 		err = w.FormatMerge(stmt)
 	case ast.WithStatement:
@@ -209,6 +294,8 @@ func (w *Writer) FormatStatement(stmt ast.Statement) error {
 		err = w.FormatCte(stmt)
 	case ast.CallStatement:
 		err = w.FormatCall(stmt)
+	case ast.ExecuteStatement:
+		err = w.FormatExecute(stmt)
 	case ast.Commit:
 		err = w.FormatCommit(stmt)
 	case ast.Rollback:
@@ -239,6 +326,26 @@ func (w *Writer) FormatStatement(stmt ast.Statement) error {
 		err = w.FormatCase(stmt)
 	case ast.Join:
 		err = w.formatJoin(stmt)
+	case ora.SelectStatement:
+		err = w.FormatOracleSelect(stmt)
+	case ora.Block:
+		err = w.FormatBlock(stmt)
+	case ora.CreatePackageStatement:
+		err = w.FormatPackage(stmt)
+	case ora.CreateProcedureStatement:
+		err = w.FormatSubprogram(stmt)
+	case ora.Cursor:
+		err = w.FormatCursor(stmt)
+	case ora.ForLoop:
+		err = w.formatForLoop(stmt)
+	case ora.Loop:
+		err = w.formatLoop(stmt.Body)
+	case ora.Exit:
+		err = w.FormatExit(stmt)
+	case ora.Raise:
+		err = w.FormatRaise(stmt)
+	case ora.Null:
+		w.WriteKeyword("NULL")
 	default:
 		err = w.FormatExpr(stmt, false)
 	}
@@ -303,6 +410,11 @@ func (w *Writer) FormatExpr(stmt ast.Statement, nl bool) error {
 	case ast.Name:
 		w.FormatName(stmt)
 	case ast.Value:
+		if stmt.Prefix != "" {
+			w.WriteString(stmt.Prefix)
+			w.WriteQuoted(stmt.Literal)
+			break
+		}
 		w.FormatLiteral(stmt.Literal)
 	case ast.Group:
 		err = w.formatGroup(stmt)
@@ -342,6 +454,12 @@ func (w *Writer) FormatExpr(stmt ast.Statement, nl bool) error {
 		err = w.FormatCase(stmt)
 	case ast.When:
 		err = w.FormatWhen(stmt)
+	case ora.Prior:
+		err = w.formatPrior(stmt)
+	case ora.OuterJoin:
+		err = w.formatOuterJoin(stmt)
+	case ora.Attribute:
+		err = w.formatAttribute(stmt)
 	default:
 		// err = w.FormatStatement(stmt)
 		return fmt.Errorf("%T unsupported expression type", stmt)
@@ -558,7 +676,8 @@ func (w *Writer) formatUnary(stmt ast.Unary, nl bool) error {
 }
 
 func (w *Writer) formatGroup(stmt ast.Group) error {
-	if _, ok := stmt.Statement.(ast.SelectStatement); ok {
+	switch stmt.Statement.(type) {
+	case ast.SelectStatement, ora.SelectStatement:
 		w.WriteString("(")
 		if !w.Compact {
 			w.WriteNL()
@@ -710,7 +829,7 @@ func (w *Writer) WriteKeyword(kw string) {
 	if w.Upperize.Keyword() || w.Upperize.All() {
 		kw = strings.ToUpper(kw)
 	} else {
-		kw = strings.ToLower(kw)
+		kw = w.Formatter.Keyword(kw)
 	}
 	if w.withColor() {
 		w.WriteString(keywordColor)
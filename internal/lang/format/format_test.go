@@ -2,13 +2,18 @@ package format_test
 
 import (
 	"bufio"
+	"errors"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"testing/iotest"
 
+	"github.com/midbel/sweet/internal/lang/ast"
 	"github.com/midbel/sweet/internal/lang/format"
+	"github.com/midbel/sweet/internal/ora"
 )
 
 func TestFormat(t *testing.T) {
@@ -23,6 +28,215 @@ func TestFormat(t *testing.T) {
 	}
 }
 
+func TestFormatUpdate(t *testing.T) {
+	tests := []struct {
+		Query string
+		Want  string
+	}{
+		{
+			Query: "update t set a = 1;",
+			Want:  "update t set a=1\n;",
+		},
+		{
+			Query: "update t set a = 1, b = 2;",
+			Want:  "update t set a=1, b=2\n;",
+		},
+		{
+			Query: "update t set a = 1, b = 'x', c = c + 1 where d = 3;",
+			Want:  "update t set a=1, b='x', c=c + 1 where d = 3\n;",
+		},
+		{
+			Query: "update t set (a, b) = (1, 2), c = 3 returning a;",
+			Want:  "update t set (a, b)=(1, 2), c=3 returning a\n;",
+		},
+	}
+	for _, c := range tests {
+		var (
+			ws strings.Builder
+			wf = format.NewWriter(&ws)
+		)
+		if err := wf.Format(strings.NewReader(c.Query)); err != nil {
+			t.Errorf("%s: error formatting input SQL: %s", c.Query, err)
+			continue
+		}
+		if got := strings.TrimSpace(ws.String()); got != c.Want {
+			t.Errorf("%s: output SQL mismatched! want %q, got %q", c.Query, c.Want, got)
+		}
+	}
+}
+
+func TestFormatCopy(t *testing.T) {
+	query := "copy users (id, name) from stdin with csv header;\n1,it's ; select\n\\.\ncopy users to stdout;"
+	want := "copy users (id, name) from STDIN with (FORMAT csv, header)\n;\n1,it's ; select\n\\.\ncopy users to STDOUT\n;"
+
+	var (
+		ws strings.Builder
+		wf = format.NewWriter(&ws)
+	)
+	if err := wf.Format(strings.NewReader(query)); err != nil {
+		t.Fatalf("error formatting input SQL: %s", err)
+	}
+	if got := strings.TrimSpace(ws.String()); got != want {
+		t.Errorf("output SQL mismatched! want %q, got %q", want, got)
+	}
+}
+
+func TestFormatQuote(t *testing.T) {
+	tests := []struct {
+		Dialect string
+		Quote   bool
+		Want    string
+	}{
+		{
+			Dialect: "oracle",
+			Want:    `UPDATE "Order" SET Name=1 WHERE "Id" = 2`,
+		},
+		{
+			Dialect: "oracle",
+			Quote:   true,
+			Want:    `UPDATE "Order" SET "NAME"=1 WHERE "Id" = 2`,
+		},
+		{
+			Dialect: "postgres",
+			Quote:   true,
+			Want:    `update "Order" set "name"=1 where "Id" = 2`,
+		},
+		{
+			Dialect: "ansi",
+			Quote:   true,
+			Want:    `update "Order" set "NAME"=1 where "Id" = 2`,
+		},
+		{
+			Dialect: "sqlite",
+			Quote:   true,
+			Want:    `update "Order" set "Name"=1 where "Id" = 2`,
+		},
+	}
+	query := `update "Order" set Name = 1 where "Id" = 2;`
+	for _, c := range tests {
+		var (
+			ws strings.Builder
+			wf = format.NewWriter(&ws)
+		)
+		if err := wf.SetDialect(c.Dialect); err != nil {
+			t.Errorf("%s: fail to set dialect: %s", c.Dialect, err)
+			continue
+		}
+		wf.UseQuote = c.Quote
+		if err := wf.Format(strings.NewReader(query)); err != nil {
+			t.Errorf("%s: error formatting input SQL: %s", c.Dialect, err)
+			continue
+		}
+		got := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(ws.String()), ";"))
+		if got != c.Want {
+			t.Errorf("%s: output SQL mismatched! want %q, got %q", c.Dialect, c.Want, got)
+		}
+	}
+}
+
+func TestFormatOracle(t *testing.T) {
+	files := []string{
+		"select.sql",
+		"block.sql",
+		"package.sql",
+	}
+	for _, f := range files {
+		buf, err := os.ReadFile(filepath.Join("..", "..", "ora", "testdata", f))
+		if err != nil {
+			t.Errorf("%s: fail to read file: %s", f, err)
+			continue
+		}
+		first, err := formatOracle(string(buf))
+		if err != nil {
+			t.Errorf("%s: error formatting input SQL: %s", f, err)
+			continue
+		}
+		second, err := formatOracle(first)
+		if err != nil {
+			t.Errorf("%s: error formatting output SQL: %s", f, err)
+			continue
+		}
+		if first != second {
+			t.Errorf("%s: formatted SQL changed when formatted again!", f)
+			t.Logf("first : %s", first)
+			t.Logf("second: %s", second)
+		}
+		if third := formatOracleJSON(t, string(buf)); third != first {
+			t.Errorf("%s: SQL formatted from JSON mismatched!", f)
+			t.Logf("want: %s", first)
+			t.Logf("got : %s", third)
+		}
+	}
+
+	query := "select nvl(a, 0) from t where d < sysdate;"
+	if _, err := formatOracle(query); err != nil {
+		t.Errorf("%s: error formatting input SQL: %s", query, err)
+	}
+}
+
+func formatOracle(query string) (string, error) {
+	var (
+		ws strings.Builder
+		wf = format.NewWriter(&ws)
+	)
+	if err := wf.SetDialect("oracle"); err != nil {
+		return "", err
+	}
+	err := wf.Format(strings.NewReader(query))
+	return ws.String(), err
+}
+
+func formatOracleJSON(t *testing.T, query string) string {
+	t.Helper()
+	p, err := ora.Parse(strings.NewReader(query))
+	if err != nil {
+		t.Fatalf("fail to create parser: %s", err)
+	}
+	var list []ast.Statement
+	for {
+		stmt, err := p.Parse()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			t.Fatalf("error parsing statement: %s", err)
+		}
+		list = append(list, stmt)
+	}
+	var buf strings.Builder
+	if err := ast.Encode(&buf, list); err != nil {
+		t.Fatalf("fail to encode statements: %s", err)
+	}
+	if list, err = ast.Decode(strings.NewReader(buf.String())); err != nil {
+		t.Fatalf("fail to decode statements: %s", err)
+	}
+	var (
+		ws strings.Builder
+		wf = format.NewWriter(&ws)
+	)
+	if err := wf.SetDialect("oracle"); err != nil {
+		t.Fatalf("fail to set dialect: %s", err)
+	}
+	for _, stmt := range list {
+		if err := wf.WriteStatement(stmt); err != nil {
+			t.Fatalf("error formatting statement: %s", err)
+		}
+	}
+	return ws.String()
+}
+
+func TestFormatReadError(t *testing.T) {
+	var (
+		errRead = errors.New("read error")
+		input   = io.MultiReader(strings.NewReader("select a from t;\nselect b"), iotest.ErrReader(errRead))
+		ws      strings.Builder
+		wf      = format.NewWriter(&ws)
+	)
+	if err := wf.Format(input); !errors.Is(err, errRead) {
+		t.Errorf("read error expected! got %v", err)
+	}
+}
+
 func testFile(t *testing.T, file string) {
 	t.Helper()
 	input, want, err := getSQL(file)
@@ -71,3 +285,48 @@ func getSQL(file string) (string, string, error) {
 	sql = strings.TrimSpace(sql)
 	return strings.Join(lines, " "), sql, scan.Err()
 }
+
+func TestWriterClone(t *testing.T) {
+	queries := []string{
+		"select a, b from t where a = 1;",
+		"@format upperize keyword;\nselect a, b from t where a = 1;",
+		"@format quote true;\nselect a, b from t where a = 1;",
+		"@format indent 2;\nselect a, b from t where a = 1;",
+	}
+	var (
+		writer = format.NewWriter(io.Discard)
+		want   = make([]string, len(queries))
+	)
+	for i, q := range queries {
+		var ws strings.Builder
+		if err := writer.Clone(&ws).Format(strings.NewReader(q)); err != nil {
+			t.Fatalf("error formatting input SQL: %s", err)
+		}
+		want[i] = ws.String()
+	}
+
+	var (
+		got = make([]string, len(queries)*8)
+		wg  sync.WaitGroup
+	)
+	for i := range got {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var ws strings.Builder
+			if err := writer.Clone(&ws).Format(strings.NewReader(queries[i%len(queries)])); err != nil {
+				t.Errorf("error formatting input SQL: %s", err)
+			}
+			got[i] = ws.String()
+		}(i)
+	}
+	wg.Wait()
+	for i := range got {
+		if w := want[i%len(queries)]; got[i] != w {
+			t.Errorf("output SQL mismatched! want %q, got %q", w, got[i])
+		}
+	}
+	if writer.UseQuote || writer.UseIndent != 4 {
+		t.Errorf("settings of the input changed the cloned writer")
+	}
+}
@@ -0,0 +1,565 @@
+package format
+
+import (
+	"strings"
+
+	"github.com/midbel/sweet/internal/lang/ast"
+	"github.com/midbel/sweet/internal/ora"
+)
+
+func (w *Writer) FormatPackage(stmt ora.CreatePackageStatement) error {
+	w.Enter()
+	defer w.Leave()
+
+	kw, _ := stmt.Keyword()
+	w.WritePrefix()
+	w.WriteKeyword(kw)
+	w.WriteBlank()
+	if err := w.FormatExpr(stmt.Name, false); err != nil {
+		return err
+	}
+	w.WriteBlank()
+	w.WriteKeyword("AS")
+	w.WriteNL()
+	if err := w.formatDeclarations(stmt.Declare); err != nil {
+		return err
+	}
+	if stmt.Init != nil {
+		w.WritePrefix()
+		w.WriteKeyword("BEGIN")
+		w.WriteNL()
+		if err := w.formatPlsqlBody(stmt.Init); err != nil {
+			return err
+		}
+	}
+	w.WritePrefix()
+	w.WriteKeyword("END")
+	w.WriteBlank()
+	return w.FormatExpr(stmt.Name, false)
+}
+
+func (w *Writer) FormatSubprogram(stmt ora.CreateProcedureStatement) error {
+	w.Enter()
+	defer w.Leave()
+
+	w.WritePrefix()
+	return w.formatSubprogram(stmt, false)
+}
+
+// formatSubprogram writes a procedure or a function. Nested subprograms are the
+// ones declared in a package or in a block: they are given without CREATE.
+func (w *Writer) formatSubprogram(stmt ora.CreateProcedureStatement, nested bool) error {
+	kw, _ := stmt.Keyword()
+	if nested {
+		kw = "PROCEDURE"
+		if stmt.Function {
+			kw = "FUNCTION"
+		}
+	}
+	w.WriteKeyword(kw)
+	w.WriteBlank()
+	if err := w.FormatExpr(stmt.Name, false); err != nil {
+		return err
+	}
+	if err := w.formatPlsqlParameters(stmt.Parameters); err != nil {
+		return err
+	}
+	if stmt.Function {
+		w.WriteBlank()
+		w.WriteKeyword("RETURN")
+		w.WriteBlank()
+		if err := w.FormatType(stmt.Return); err != nil {
+			return err
+		}
+	}
+	if stmt.Body == nil {
+		return nil
+	}
+	block, ok := stmt.Body.(ora.Block)
+	if !ok {
+		return w.CanNotUse("procedure", stmt.Body)
+	}
+	w.WriteBlank()
+	w.WriteKeyword("IS")
+	w.WriteNL()
+	if err := w.formatDeclarations(block.Declare); err != nil {
+		return err
+	}
+	w.WritePrefix()
+	block.Declare = nil
+	return w.formatBlock(block)
+}
+
+func (w *Writer) formatPlsqlParameters(params []ast.Statement) error {
+	if len(params) == 0 {
+		return nil
+	}
+	w.WriteString("(")
+	for i, s := range params {
+		if i > 0 {
+			w.WriteString(",")
+			w.WriteBlank()
+		}
+		p, ok := s.(ast.ProcedureParameter)
+		if !ok {
+			return w.CanNotUse("procedure", s)
+		}
+		if w.Upperize.Identifier() || w.Upperize.All() {
+			p.Name = strings.ToUpper(p.Name)
+		}
+		w.WriteString(p.Name)
+		w.WriteBlank()
+		switch p.Mode {
+		case ast.ModeIn:
+			w.WriteKeyword("IN")
+		case ast.ModeOut:
+			w.WriteKeyword("OUT")
+		case ast.ModeInOut:
+			w.WriteKeyword("IN OUT")
+		}
+		if p.Mode != 0 {
+			w.WriteBlank()
+		}
+		if err := w.FormatType(p.Type); err != nil {
+			return err
+		}
+		if p.Default != nil {
+			w.WriteBlank()
+			w.WriteKeyword("DEFAULT")
+			w.WriteBlank()
+			if err := w.FormatExpr(p.Default, false); err != nil {
+				return err
+			}
+		}
+	}
+	w.WriteString(")")
+	return nil
+}
+
+// formatDeclarations writes the variables, cursors and subprograms declared by
+// a block or a package, one by line
+func (w *Writer) formatDeclarations(list []ast.Statement) error {
+	w.Enter()
+	defer w.Leave()
+	for _, s := range list {
+		w.WritePrefix()
+		var err error
+		switch s := s.(type) {
+		case ast.Declare:
+			err = w.formatVariable(s)
+		case ora.Cursor:
+			err = w.FormatCursor(s)
+		case ora.CreateProcedureStatement:
+			err = w.formatSubprogram(s, true)
+		default:
+			err = w.CanNotUse("declare", s)
+		}
+		if err != nil {
+			return err
+		}
+		w.WriteEOL()
+		w.WriteNL()
+	}
+	return nil
+}
+
+func (w *Writer) formatVariable(stmt ast.Declare) error {
+	w.WriteString(stmt.Ident)
+	w.WriteBlank()
+	if err := w.FormatType(stmt.Type); err != nil {
+		return err
+	}
+	if stmt.Value == nil {
+		return nil
+	}
+	w.WriteBlank()
+	w.WriteString(":=")
+	w.WriteBlank()
+	return w.FormatExpr(stmt.Value, false)
+}
+
+func (w *Writer) FormatCursor(stmt ora.Cursor) error {
+	w.WriteKeyword("CURSOR")
+	w.WriteBlank()
+	w.WriteString(stmt.Ident)
+	if len(stmt.Parameters) > 0 {
+		w.WriteBlank()
+		if err := w.formatPlsqlParameters(stmt.Parameters); err != nil {
+			return err
+		}
+	}
+	w.WriteBlank()
+	w.WriteKeyword("IS")
+	w.WriteBlank()
+	return w.compact(func() error {
+		return w.FormatStatement(stmt.Query)
+	})
+}
+
+func (w *Writer) FormatBlock(stmt ora.Block) error {
+	w.Enter()
+	defer w.Leave()
+
+	w.WritePrefix()
+	return w.formatBlock(stmt)
+}
+
+// formatBlock writes a block starting on the current line. The lines of its
+// declarations and of its body are indented.
+func (w *Writer) formatBlock(stmt ora.Block) error {
+	if len(stmt.Declare) > 0 {
+		w.WriteKeyword("DECLARE")
+		w.WriteNL()
+		if err := w.formatDeclarations(stmt.Declare); err != nil {
+			return err
+		}
+		w.WritePrefix()
+	}
+	w.WriteKeyword("BEGIN")
+	w.WriteNL()
+	if err := w.formatPlsqlBody(stmt.Body); err != nil {
+		return err
+	}
+	if len(stmt.Handlers) > 0 {
+		w.WritePrefix()
+		w.WriteKeyword("EXCEPTION")
+		w.WriteNL()
+		w.Enter()
+		for _, s := range stmt.Handlers {
+			h, ok := s.(ora.Handler)
+			if !ok {
+				w.Leave()
+				return w.CanNotUse("exception", s)
+			}
+			w.WritePrefix()
+			if err := w.formatHandler(h); err != nil {
+				w.Leave()
+				return err
+			}
+		}
+		w.Leave()
+	}
+	w.WritePrefix()
+	w.WriteKeyword("END")
+	if stmt.Label != "" {
+		w.WriteBlank()
+		w.WriteString(stmt.Label)
+	}
+	return nil
+}
+
+func (w *Writer) formatHandler(stmt ora.Handler) error {
+	w.WriteKeyword("WHEN")
+	w.WriteBlank()
+	for i, e := range stmt.Exceptions {
+		if i > 0 {
+			w.WriteBlank()
+			w.WriteKeyword("OR")
+			w.WriteBlank()
+		}
+		w.WriteString(e)
+	}
+	w.WriteBlank()
+	w.WriteKeyword("THEN")
+	w.WriteNL()
+	return w.formatPlsqlBody(stmt.Body)
+}
+
+// formatPlsqlBody writes the statements of a body, one by line, with a
+// deeper indentation than the statement owning the body
+func (w *Writer) formatPlsqlBody(stmt ast.Statement) error {
+	if n, ok := stmt.(ast.Node); ok {
+		stmt = n.Statement
+	}
+	var list ast.List
+	switch s := stmt.(type) {
+	case ast.List:
+		list = s
+	case nil:
+	default:
+		list.Values = append(list.Values, s)
+	}
+	w.Enter()
+	defer w.Leave()
+	for _, s := range list.Values {
+		if err := w.formatPlsqlStatement(s); err != nil {
+			return err
+		}
+		w.WriteEOL()
+		w.WriteNL()
+	}
+	return nil
+}
+
+// formatPlsqlStatement writes a statement of a body: assignments, calls and
+// the control flow statements are written with the syntax of PL/SQL
+func (w *Writer) formatPlsqlStatement(stmt ast.Statement) error {
+	if n, ok := stmt.(ast.Node); ok {
+		stmt = n.Statement
+	}
+	switch stmt.(type) {
+	case ast.SelectStatement, ora.SelectStatement:
+		// queries write their own indentation one level deeper
+		w.Leave()
+		defer w.Enter()
+		return w.FormatStatement(stmt)
+	}
+	w.WritePrefix()
+	switch stmt := stmt.(type) {
+	case ast.Set:
+		w.WriteString(stmt.Ident)
+		w.WriteBlank()
+		w.WriteString(":=")
+		w.WriteBlank()
+		return w.FormatExpr(stmt.Expr, false)
+	case ast.CallStatement:
+		return w.formatPlsqlCall(stmt)
+	case ast.If:
+		if err := w.formatPlsqlIf(stmt, "IF"); err != nil {
+			return err
+		}
+		w.WritePrefix()
+		w.WriteKeyword("END IF")
+		return nil
+	case ast.While:
+		w.WriteKeyword("WHILE")
+		w.WriteBlank()
+		if err := w.FormatExpr(stmt.Cdt, false); err != nil {
+			return err
+		}
+		w.WriteBlank()
+		return w.formatLoop(stmt.Body)
+	case ora.Loop:
+		return w.formatLoop(stmt.Body)
+	case ora.ForLoop:
+		return w.formatForLoop(stmt)
+	case ora.Block:
+		return w.formatBlock(stmt)
+	default:
+		return w.FormatStatement(stmt)
+	}
+}
+
+func (w *Writer) formatPlsqlCall(stmt ast.CallStatement) error {
+	if err := w.FormatExpr(stmt.Ident, false); err != nil {
+		return err
+	}
+	if len(stmt.Args) == 0 {
+		return nil
+	}
+	w.WriteString("(")
+	// named arguments can only be given after the positional ones
+	named := len(stmt.Args) - len(stmt.Names)
+	for i, a := range stmt.Args {
+		if i > 0 {
+			w.WriteString(",")
+			w.WriteBlank()
+		}
+		if i >= named {
+			w.WriteString(stmt.Names[i-named])
+			w.WriteBlank()
+			w.WriteString("=>")
+			w.WriteBlank()
+		}
+		if err := w.FormatExpr(a, false); err != nil {
+			return err
+		}
+	}
+	w.WriteString(")")
+	return nil
+}
+
+func (w *Writer) formatPlsqlIf(stmt ast.If, kw string) error {
+	w.WriteKeyword(kw)
+	w.WriteBlank()
+	if err := w.FormatExpr(stmt.Cdt, false); err != nil {
+		return err
+	}
+	w.WriteBlank()
+	w.WriteKeyword("THEN")
+	w.WriteNL()
+	if err := w.formatPlsqlBody(stmt.Csq); err != nil {
+		return err
+	}
+	if stmt.Alt == nil {
+		return nil
+	}
+	w.WritePrefix()
+	if s, ok := stmt.Alt.(ast.If); ok {
+		return w.formatPlsqlIf(s, "ELSIF")
+	}
+	w.WriteKeyword("ELSE")
+	w.WriteNL()
+	return w.formatPlsqlBody(stmt.Alt)
+}
+
+func (w *Writer) formatForLoop(stmt ora.ForLoop) error {
+	w.WriteKeyword("FOR")
+	w.WriteBlank()
+	w.WriteString(stmt.Ident)
+	w.WriteBlank()
+	w.WriteKeyword("IN")
+	w.WriteBlank()
+	var err error
+	switch stmt.Cursor.(type) {
+	case ast.SelectStatement, ora.SelectStatement:
+		w.WriteString("(")
+		err = w.compact(func() error {
+			return w.FormatStatement(stmt.Cursor)
+		})
+		w.WriteString(")")
+	default:
+		err = w.FormatExpr(stmt.Cursor, false)
+	}
+	if err != nil {
+		return err
+	}
+	w.WriteBlank()
+	return w.formatLoop(stmt.Body)
+}
+
+func (w *Writer) formatLoop(body ast.Statement) error {
+	w.WriteKeyword("LOOP")
+	w.WriteNL()
+	if err := w.formatPlsqlBody(body); err != nil {
+		return err
+	}
+	w.WritePrefix()
+	w.WriteKeyword("END LOOP")
+	return nil
+}
+
+func (w *Writer) FormatExit(stmt ora.Exit) error {
+	w.WriteKeyword("EXIT")
+	if stmt.Cdt == nil {
+		return nil
+	}
+	w.WriteBlank()
+	w.WriteKeyword("WHEN")
+	w.WriteBlank()
+	return w.FormatExpr(stmt.Cdt, false)
+}
+
+func (w *Writer) FormatRaise(stmt ora.Raise) error {
+	w.WriteKeyword("RAISE")
+	if stmt.Exception != "" {
+		w.WriteBlank()
+		w.WriteString(stmt.Exception)
+	}
+	return nil
+}
+
+func (w *Writer) FormatOracleSelect(stmt ora.SelectStatement) error {
+	hierarchy := func() error {
+		if stmt.StartWith != nil {
+			w.WriteNL()
+			w.WritePrefix()
+			w.WriteKeyword("START WITH")
+			w.WriteBlank()
+			if err := w.FormatExpr(stmt.StartWith, false); err != nil {
+				return err
+			}
+		}
+		if stmt.ConnectBy != nil {
+			w.WriteNL()
+			w.WritePrefix()
+			w.WriteKeyword("CONNECT BY")
+			w.WriteBlank()
+			if stmt.NoCycle {
+				w.WriteKeyword("NOCYCLE")
+				w.WriteBlank()
+			}
+			if err := w.FormatExpr(stmt.ConnectBy, false); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return w.formatSelect(stmt.SelectStatement, stmt.Into, hierarchy)
+}
+
+// formatOracleMatch writes the actions of a MERGE with the syntax of Oracle:
+// the conditions are given after the action and the rows updated can be
+// deleted
+func (w *Writer) formatOracleMatch(stmt ast.MatchStatement) error {
+	w.WriteKeyword("WHEN")
+	w.WriteBlank()
+	switch s := stmt.Statement.(type) {
+	case ora.MergeUpdate:
+		w.WriteKeyword("MATCHED")
+		w.WriteBlank()
+		w.WriteKeyword("THEN")
+		w.WriteNL()
+		w.WriteKeyword("UPDATE")
+		w.WriteBlank()
+		w.WriteKeyword("SET")
+		w.WriteBlank()
+		err := w.compact(func() error {
+			return w.FormatAssignment(s.List)
+		})
+		if err != nil {
+			return err
+		}
+		if err := w.formatMatchCondition(stmt.Condition); err != nil {
+			return err
+		}
+		if s.Delete != nil {
+			w.WriteBlank()
+			w.WriteKeyword("DELETE")
+			w.WriteBlank()
+			err := w.compact(func() error {
+				return w.FormatWhere(s.Delete)
+			})
+			if err != nil {
+				return err
+			}
+		}
+	case ora.MergeInsert:
+		w.WriteKeyword("NOT MATCHED")
+		w.WriteBlank()
+		w.WriteKeyword("THEN")
+		w.WriteNL()
+		if err := w.formatMatchInsert(s.InsertStatement); err != nil {
+			return err
+		}
+		if err := w.formatMatchCondition(stmt.Condition); err != nil {
+			return err
+		}
+	default:
+		return w.CanNotUse("merge", stmt.Statement)
+	}
+	return nil
+}
+
+func (w *Writer) formatMatchCondition(cdt ast.Statement) error {
+	if cdt == nil {
+		return nil
+	}
+	w.WriteBlank()
+	return w.compact(func() error {
+		return w.FormatWhere(cdt)
+	})
+}
+
+func (w *Writer) formatPrior(stmt ora.Prior) error {
+	w.WriteKeyword("PRIOR")
+	w.WriteBlank()
+	return w.FormatExpr(stmt.Statement, false)
+}
+
+func (w *Writer) formatOuterJoin(stmt ora.OuterJoin) error {
+	if err := w.FormatExpr(stmt.Statement, false); err != nil {
+		return err
+	}
+	w.WriteString("(+)")
+	return nil
+}
+
+func (w *Writer) formatAttribute(stmt ora.Attribute) error {
+	if err := w.FormatExpr(stmt.Ident, false); err != nil {
+		return err
+	}
+	w.WriteString("%")
+	w.WriteKeyword(stmt.Name)
+	return nil
+}
@@ -20,6 +20,10 @@ func (w *Writer) FormatGrant(stmt ast.GrantStatement) error {
 		}
 	}
 	w.WriteBlank()
+	w.WriteKeyword("ON")
+	w.WriteBlank()
+	w.WriteString(stmt.Object)
+	w.WriteBlank()
 	w.WriteKeyword("TO")
 	w.WriteBlank()
 	for i, u := range stmt.Users {
@@ -29,6 +33,10 @@ func (w *Writer) FormatGrant(stmt ast.GrantStatement) error {
 		}
 		w.WriteString(u)
 	}
+	if stmt.GrantOption {
+		w.WriteBlank()
+		w.WriteKeyword("WITH GRANT OPTION")
+	}
 	return nil
 }
 
@@ -48,6 +56,10 @@ func (w *Writer) FormatRevoke(stmt ast.RevokeStatement) error {
 		}
 	}
 	w.WriteBlank()
+	w.WriteKeyword("ON")
+	w.WriteBlank()
+	w.WriteString(stmt.Object)
+	w.WriteBlank()
 	w.WriteKeyword("FROM")
 	w.WriteBlank()
 	for i, u := range stmt.Users {
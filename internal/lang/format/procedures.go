@@ -38,6 +38,12 @@ func (w *Writer) FormatCreateProcedure(stmt ast.CreateProcedureStatement) error
 		w.WriteString(stmt.Language)
 		w.WriteNL()
 	}
+	if stmt.Security != "" {
+		w.WriteKeyword("SECURITY")
+		w.WriteBlank()
+		w.WriteKeyword(stmt.Security)
+		w.WriteNL()
+	}
 	w.WriteKeyword("BEGIN")
 	w.WriteNL()
 	if err := w.FormatStatement(stmt.Body); err != nil {
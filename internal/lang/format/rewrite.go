@@ -190,7 +190,7 @@ func (w *Writer) replaceCteWithSubquery(stmt ast.Statement) (ast.Statement, erro
 	for i := range with.Queries {
 		q, ok := with.Queries[i].(ast.CteStatement)
 		if !ok {
-			return nil, fmt.Errorf("unexpected query type in with")
+			return nil, fmt.Errorf("%s: unexpected query type in with", ast.SpanOf(with.Queries[i]).Pos())
 		}
 		qs = append(qs, q)
 	}
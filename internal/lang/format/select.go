@@ -88,6 +88,13 @@ func (w *Writer) FormatValues(stmt ast.ValuesStatement) error {
 }
 
 func (w *Writer) FormatSelect(stmt ast.SelectStatement) error {
+	return w.formatSelect(stmt, nil, nil)
+}
+
+// formatSelect writes a query with the variables receiving its result given
+// after the columns. The clauses of a dialect coming after the WHERE clause are
+// written by more.
+func (w *Writer) formatSelect(stmt ast.SelectStatement, into []ast.Statement, more func() error) error {
 	w.Enter()
 	defer w.Leave()
 
@@ -98,6 +105,21 @@ func (w *Writer) FormatSelect(stmt ast.SelectStatement) error {
 	if err := w.FormatSelectColumns(stmt.Columns); err != nil {
 		return err
 	}
+	if len(into) > 0 {
+		w.WriteNL()
+		w.WritePrefix()
+		w.WriteKeyword("INTO")
+		w.WriteBlank()
+		for i := range into {
+			if i > 0 {
+				w.WriteString(",")
+				w.WriteBlank()
+			}
+			if err := w.FormatExpr(into[i], false); err != nil {
+				return err
+			}
+		}
+	}
 	w.WriteNL()
 	w.WritePrefix()
 	if err := w.FormatFrom(stmt.Tables); err != nil {
@@ -110,6 +132,11 @@ func (w *Writer) FormatSelect(stmt ast.SelectStatement) error {
 			return err
 		}
 	}
+	if more != nil {
+		if err := more(); err != nil {
+			return err
+		}
+	}
 	if len(stmt.Groups) > 0 {
 		w.WriteNL()
 		w.WritePrefix()
@@ -473,13 +500,7 @@ func (w *Writer) FormatCte(stmt ast.CteStatement) error {
 					w.WriteBlank()
 				}
 			}
-			if w.Upperize.Identifier() {
-				s = strings.ToUpper(s)
-			}
-			if w.UseQuote {
-				s = w.Quote(s)
-			}
-			w.WriteString(s)
+			w.WriteString(w.formatIdent(s, false))
 		}
 		w.WriteString(")")
 	}
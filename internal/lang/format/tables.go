@@ -26,13 +26,7 @@ func (w *Writer) FormatCreateView(stmt ast.CreateViewStatement) error {
 				w.WriteString(",")
 				w.WriteBlank()
 			}
-			if w.Upperize.Identifier() || w.Upperize.All() {
-				s = strings.ToUpper(s)
-			}
-			if w.UseQuote {
-				s = w.Quote(s)
-			}
-			w.WriteString(s)
+			w.WriteString(w.formatIdent(s, false))
 		}
 		w.WriteString(")")
 	}
@@ -44,6 +38,38 @@ func (w *Writer) FormatCreateView(stmt ast.CreateViewStatement) error {
 	return w.FormatStatement(stmt.Select)
 }
 
+func (w *Writer) FormatCreateIndex(stmt ast.CreateIndexStatement) error {
+	kw, _ := stmt.Keyword()
+	w.WriteKeyword(kw)
+	w.WriteBlank()
+	if stmt.Concurrently {
+		w.WriteKeyword("CONCURRENTLY")
+		w.WriteBlank()
+	}
+	if stmt.NotExists {
+		w.WriteKeyword("IF NOT EXISTS")
+		w.WriteBlank()
+	}
+	w.WriteString(w.formatIdent(stmt.Name, false))
+	w.WriteBlank()
+	w.WriteKeyword("ON")
+	w.WriteBlank()
+	if err := w.FormatTableName(stmt.Table); err != nil {
+		return err
+	}
+	w.WriteBlank()
+	w.WriteString("(")
+	for i, s := range stmt.Columns {
+		if i > 0 {
+			w.WriteString(",")
+			w.WriteBlank()
+		}
+		w.WriteString(w.formatIdent(s, false))
+	}
+	w.WriteString(")")
+	return nil
+}
+
 type CreateTableFormatter interface {
 	FormatTableName(ast.Statement) error
 	FormatColumnDef(ConstraintFormatter, ast.Statement, int) error
@@ -338,6 +364,26 @@ func (w *Writer) FormatAlterTable(stmt ast.AlterTableStatement) error {
 		}
 		return nil
 	case ast.AlterColumnAction:
+		w.WriteKeyword("ALTER COLUMN")
+		w.WriteBlank()
+		w.WriteString(action.Name)
+		w.WriteBlank()
+		switch {
+		case action.Type.Name != "":
+			w.WriteKeyword("TYPE")
+			w.WriteBlank()
+			return w.FormatType(action.Type)
+		case action.Default != nil:
+			w.WriteKeyword("SET DEFAULT")
+			w.WriteBlank()
+			return w.FormatExpr(action.Default, false)
+		case action.DropDefault:
+			w.WriteKeyword("DROP DEFAULT")
+		case action.NotNull:
+			w.WriteKeyword("SET NOT NULL")
+		case action.DropNotNull:
+			w.WriteKeyword("DROP NOT NULL")
+		}
 	case ast.RenameColumnAction:
 		w.WriteKeyword("RENAME COLUMN")
 		w.WriteBlank()
@@ -1,6 +1,9 @@
 package format
 
-import "github.com/midbel/sweet/internal/lang/ast"
+import (
+	"github.com/midbel/sweet/internal/lang/ast"
+	"github.com/midbel/sweet/internal/ora"
+)
 
 func (w *Writer) FormatMerge(stmt ast.MergeStatement) error {
 	kw, _ := stmt.Keyword()
@@ -37,6 +40,10 @@ func (w *Writer) FormatMerge(stmt ast.MergeStatement) error {
 }
 
 func (w *Writer) FormatMatch(stmt ast.MatchStatement) error {
+	switch stmt.Statement.(type) {
+	case ora.MergeUpdate, ora.MergeInsert:
+		return w.formatOracleMatch(stmt)
+	}
 	w.WriteKeyword("WHEN")
 	w.WriteBlank()
 	switch stmt.Statement.(type) {
@@ -79,38 +86,40 @@ func (w *Writer) FormatMatch(stmt ast.MatchStatement) error {
 			return err
 		}
 	case ast.InsertStatement:
-		w.WriteKeyword("INSERT")
-		w.WriteBlank()
-		if len(stmt.Columns) > 0 {
-			w.WriteString("(")
-			for i := range stmt.Columns {
-				if i > 0 {
-					w.WriteString(",")
-					w.WriteBlank()
-				}
-				w.WriteString(stmt.Columns[i])
-			}
-			w.WriteString(")")
-			w.WriteBlank()
-		}
-		values, ok := stmt.Values.(ast.ValuesStatement)
-		if !ok {
-			return w.CanNotUse("merge", stmt.Values)
-		}
-		compact := w.Compact
-		w.Compact = true
-		defer func() {
-			w.Compact = compact
-		}()
-		if err := w.FormatValues(values); err != nil {
-			return err
-		}
+		return w.formatMatchInsert(stmt)
 	default:
 		return w.CanNotUse("merge", stmt)
 	}
 	return nil
 }
 
+func (w *Writer) formatMatchInsert(stmt ast.InsertStatement) error {
+	w.WriteKeyword("INSERT")
+	w.WriteBlank()
+	if len(stmt.Columns) > 0 {
+		w.WriteString("(")
+		for i := range stmt.Columns {
+			if i > 0 {
+				w.WriteString(",")
+				w.WriteBlank()
+			}
+			w.WriteString(stmt.Columns[i])
+		}
+		w.WriteString(")")
+		w.WriteBlank()
+	}
+	values, ok := stmt.Values.(ast.ValuesStatement)
+	if !ok {
+		return w.CanNotUse("merge", stmt.Values)
+	}
+	compact := w.Compact
+	w.Compact = true
+	defer func() {
+		w.Compact = compact
+	}()
+	return w.FormatValues(values)
+}
+
 func (w *Writer) FormatTruncate(stmt ast.TruncateStatement) error {
 	kw, _ := stmt.Keyword()
 	w.WriteKeyword(kw)
@@ -129,6 +138,72 @@ func (w *Writer) FormatTruncate(stmt ast.TruncateStatement) error {
 	return nil
 }
 
+func (w *Writer) FormatCopy(stmt ast.CopyStatement) error {
+	kw, _ := stmt.Keyword()
+	w.WriteKeyword(kw)
+	w.WriteBlank()
+	if q, ok := stmt.Table.(ast.Name); ok {
+		w.FormatName(q)
+	} else {
+		w.WriteString("(")
+		if err := w.FormatStatement(stmt.Table); err != nil {
+			return err
+		}
+		w.WriteString(")")
+	}
+	if len(stmt.Columns) > 0 {
+		w.WriteBlank()
+		w.WriteString("(")
+		for i, c := range stmt.Columns {
+			if i > 0 {
+				w.WriteString(",")
+				w.WriteBlank()
+			}
+			w.WriteString(c)
+		}
+		w.WriteString(")")
+	}
+	w.WriteBlank()
+	if stmt.To {
+		w.WriteKeyword("TO")
+	} else {
+		w.WriteKeyword("FROM")
+	}
+	w.WriteBlank()
+	if err := w.FormatExpr(stmt.Target, false); err != nil {
+		return err
+	}
+	if len(stmt.Options) == 0 {
+		return nil
+	}
+	w.WriteBlank()
+	w.WriteKeyword("WITH")
+	w.WriteBlank()
+	w.WriteString("(")
+	for i, o := range stmt.Options {
+		if i > 0 {
+			w.WriteString(",")
+			w.WriteBlank()
+		}
+		opt, ok := o.(ast.Assignment)
+		if !ok {
+			return w.CanNotUse("copy", o)
+		}
+		if err := w.FormatExpr(opt.Field, false); err != nil {
+			return err
+		}
+		if opt.Value == nil {
+			continue
+		}
+		w.WriteBlank()
+		if err := w.FormatExpr(opt.Value, false); err != nil {
+			return err
+		}
+	}
+	w.WriteString(")")
+	return nil
+}
+
 func (w *Writer) FormatDelete(stmt ast.DeleteStatement) error {
 	kw, _ := stmt.Keyword()
 	w.WriteKeyword(kw)
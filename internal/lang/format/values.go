@@ -1,6 +1,7 @@
 package format
 
 import (
+	"errors"
 	"strconv"
 	"strings"
 
@@ -32,17 +33,33 @@ func (w *Writer) FormatName(name ast.Name) error {
 		}
 		str := name.Parts[i]
 		if str == "" && i == len(name.Parts)-1 {
-			str = "*"
+			w.WriteString("*")
+			continue
 		}
-		if w.Upperize.Identifier() || w.Upperize.All() {
-			str = strings.ToUpper(str)
-		}
-		if w.UseQuote && str != "*" {
+		w.WriteString(w.formatIdent(str, name.IsQuoted(i)))
+	}
+	return nil
+}
+
+// formatIdent gives the identifier as it should be written. Identifiers
+// quoted in the input are case sensitive: their case is never changed and
+// their quotes are only removed if the dialect does not need them. Unquoted
+// identifiers are folded as the dialect does before being quoted so that they
+// still refer to the same object.
+func (w *Writer) formatIdent(str string, quoted bool) string {
+	if quoted {
+		if w.UseQuote || w.dialect.NeedQuote(str) {
 			str = w.Quote(str)
 		}
-		w.WriteString(str)
+		return str
 	}
-	return nil
+	if w.UseQuote {
+		return w.Quote(w.dialect.Folding.Fold(str))
+	}
+	if w.Upperize.Identifier() || w.Upperize.All() {
+		str = strings.ToUpper(str)
+	}
+	return str
 }
 
 func (w *Writer) FormatAlias(alias ast.Alias) error {
@@ -55,14 +72,7 @@ func (w *Writer) FormatAlias(alias ast.Alias) error {
 		w.WriteKeyword("AS")
 		w.WriteBlank()
 	}
-	str := alias.Alias
-	if w.Upperize.Identifier() || w.Upperize.All() {
-		str = strings.ToUpper(str)
-	}
-	if w.UseQuote {
-		str = w.Quote(str)
-	}
-	w.WriteString(str)
+	w.WriteString(w.formatIdent(alias.Alias, alias.Quoted))
 	return nil
 }
 
@@ -77,7 +87,7 @@ func (w *Writer) FormatLiteral(literal string) {
 		}
 		return
 	}
-	if _, err := strconv.Atoi(literal); err == nil {
+	if _, err := strconv.ParseInt(literal, 0, 64); err == nil || errors.Is(err, strconv.ErrRange) {
 		if w.withColor() {
 			w.WriteString(numberColor)
 		}
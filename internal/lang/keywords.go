@@ -9,6 +9,8 @@ var ansi = keywords.Set{
 	{"create", "or", "replace", "procedure"},
 	{"create", "table"},
 	{"create", "view"},
+	{"create", "index"},
+	{"create", "unique", "index"},
 	{"create", "temp", "view"},
 	{"create", "temporary", "view"},
 	{"create", "temp", "table"},
@@ -65,6 +67,7 @@ var ansi = keywords.Set{
 	{"delete", "from"},
 	{"truncate"},
 	{"truncate", "table"},
+	{"copy"},
 	{"update"},
 	{"merge"},
 	{"merge", "into"},
@@ -130,6 +133,8 @@ var ansi = keywords.Set{
 	{"exclude", "group"},
 	{"exclude", "ties"},
 	{"call"},
+	{"execute"},
+	{"execute", "immediate"},
 	{"constraint"},
 	{"primary", "key"},
 	{"foreign", "key"},
@@ -164,6 +169,7 @@ var ansi = keywords.Set{
 	{"continue", "identity"},
 	{"grant"},
 	{"revoke"},
+	{"with", "grant", "option"},
 	{"all", "privileges"},
 }
 
@@ -9,61 +9,22 @@ import (
 )
 
 func checkEnforcedAlias(stmt ast.Statement) ([]rules.LintMessage, error) {
-	switch stmt := stmt.(type) {
-	case ast.SelectStatement:
-		return selectEnforcedAlias(stmt)
-	case ast.UnionStatement:
-		return handleCompoundStatement(stmt.Left, stmt.Right, checkEnforcedAlias)
-	case ast.IntersectStatement:
-		return handleCompoundStatement(stmt.Left, stmt.Right, checkEnforcedAlias)
-	case ast.ExceptStatement:
-		return handleCompoundStatement(stmt.Left, stmt.Right, checkEnforcedAlias)
-	case ast.WithStatement:
-		return handleWithStatement(stmt, checkEnforcedAlias)
-	case ast.CteStatement:
-		return checkEnforcedAlias(stmt.Statement)
-	case ast.Join:
-		return checkEnforcedAlias(stmt.Table)
-	case ast.Group:
-		return checkEnforcedAlias(stmt.Statement)
-	default:
-		return nil, ErrNa
-	}
+	return inspect(stmt, selectEnforcedAlias)
 }
 
 func selectEnforcedAlias(stmt ast.SelectStatement) ([]rules.LintMessage, error) {
 	var list []rules.LintMessage
 	if cs := ast.GetAliasFromStmt(stmt.Columns); len(cs) == 0 {
-		list = append(list, enforcedAlias())
+		list = append(list, enforcedAlias(stmt))
 	}
 	if ts := ast.GetAliasFromStmt(stmt.Tables); len(ts) == 0 {
-		list = append(list, enforcedAlias())
+		list = append(list, enforcedAlias(stmt))
 	}
-	others, err := handleSelectStatement(stmt, checkEnforcedAlias)
-	return slices.Concat(list, others), err
+	return list, nil
 }
 
 func checkUniqueAlias(stmt ast.Statement) ([]rules.LintMessage, error) {
-	switch stmt := stmt.(type) {
-	case ast.SelectStatement:
-		return selectUniqueAlias(stmt)
-	case ast.UnionStatement:
-		return handleCompoundStatement(stmt.Left, stmt.Right, checkUniqueAlias)
-	case ast.IntersectStatement:
-		return handleCompoundStatement(stmt.Left, stmt.Right, checkUniqueAlias)
-	case ast.ExceptStatement:
-		return handleCompoundStatement(stmt.Left, stmt.Right, checkUniqueAlias)
-	case ast.WithStatement:
-		return handleWithStatement(stmt, checkUniqueAlias)
-	case ast.CteStatement:
-		return checkUniqueAlias(stmt.Statement)
-	case ast.Join:
-		return checkUniqueAlias(stmt.Table)
-	case ast.Group:
-		return checkUniqueAlias(stmt.Statement)
-	default:
-		return nil, ErrNa
-	}
+	return inspect(stmt, selectUniqueAlias)
 }
 
 func selectUniqueAlias(stmt ast.SelectStatement) ([]rules.LintMessage, error) {
@@ -77,39 +38,19 @@ func selectUniqueAlias(stmt ast.SelectStatement) ([]rules.LintMessage, error) {
 	)
 	for i := range columns {
 		if ok := contains(columns[i+1:], columns[i]); ok {
-			list = append(list, duplicatedAlias(columns[i]))
+			list = append(list, duplicatedAlias(stmt, columns[i]))
 		}
 	}
 	for i := range tables {
 		if ok := contains(tables[i+1:], tables[i]); ok {
-			list = append(list, duplicatedAlias(tables[i]))
+			list = append(list, duplicatedAlias(stmt, tables[i]))
 		}
 	}
-	others, err := handleSelectStatement(stmt, checkUniqueAlias)
-	return slices.Concat(list, others), err
+	return list, nil
 }
 
 func checkUndefinedAlias(stmt ast.Statement) ([]rules.LintMessage, error) {
-	switch stmt := stmt.(type) {
-	case ast.SelectStatement:
-		return selectUndefinedAlias(stmt)
-	case ast.UnionStatement:
-		return handleCompoundStatement(stmt.Left, stmt.Right, checkUndefinedAlias)
-	case ast.IntersectStatement:
-		return handleCompoundStatement(stmt.Left, stmt.Right, checkUndefinedAlias)
-	case ast.ExceptStatement:
-		return handleCompoundStatement(stmt.Left, stmt.Right, checkUndefinedAlias)
-	case ast.WithStatement:
-		return handleWithStatement(stmt, checkUndefinedAlias)
-	case ast.CteStatement:
-		return checkUndefinedAlias(stmt.Statement)
-	case ast.Join:
-		return checkUndefinedAlias(stmt.Table)
-	case ast.Group:
-		return checkUndefinedAlias(stmt.Statement)
-	default:
-		return nil, ErrNa
-	}
+	return inspect(stmt, selectUndefinedAlias)
 }
 
 func selectUndefinedAlias(stmt ast.SelectStatement) ([]rules.LintMessage, error) {
@@ -128,34 +69,14 @@ func selectUndefinedAlias(stmt ast.SelectStatement) ([]rules.LintMessage, error)
 			continue
 		}
 		if schema := n.Schema(); schema != "" && !slices.Contains(values, schema) {
-			list = append(list, undefinedAlias(schema))
+			list = append(list, undefinedAlias(n, schema))
 		}
 	}
-	others, err := handleSelectStatement(stmt, checkUndefinedAlias)
-	return slices.Concat(list, others), err
+	return list, nil
 }
 
 func checkMissingAlias(stmt ast.Statement) ([]rules.LintMessage, error) {
-	switch stmt := stmt.(type) {
-	case ast.SelectStatement:
-		return selectMissingAlias(stmt)
-	case ast.UnionStatement:
-		return handleCompoundStatement(stmt.Left, stmt.Right, checkMissingAlias)
-	case ast.IntersectStatement:
-		return handleCompoundStatement(stmt.Left, stmt.Right, checkMissingAlias)
-	case ast.ExceptStatement:
-		return handleCompoundStatement(stmt.Left, stmt.Right, checkMissingAlias)
-	case ast.WithStatement:
-		return handleWithStatement(stmt, checkMissingAlias)
-	case ast.CteStatement:
-		return checkMissingAlias(stmt.Statement)
-	case ast.Join:
-		return checkMissingAlias(stmt.Table)
-	case ast.Group:
-		return checkMissingAlias(stmt.Statement)
-	default:
-		return nil, ErrNa
-	}
+	return inspect(stmt, selectMissingAlias)
 }
 
 func selectMissingAlias(stmt ast.SelectStatement) ([]rules.LintMessage, error) {
@@ -165,7 +86,7 @@ func selectMissingAlias(stmt ast.SelectStatement) ([]rules.LintMessage, error) {
 			s = g.Statement
 		}
 		if _, ok := s.(ast.SelectStatement); ok {
-			list = append(list, missingAlias())
+			list = append(list, missingAlias(s))
 		}
 	}
 	for _, s := range stmt.Tables {
@@ -177,34 +98,14 @@ func selectMissingAlias(stmt ast.SelectStatement) ([]rules.LintMessage, error) {
 			}
 		}
 		if _, ok := s.(ast.SelectStatement); ok {
-			list = append(list, missingAlias())
+			list = append(list, missingAlias(s))
 		}
 	}
-	others, err := handleSelectStatement(stmt, checkMissingAlias)
-	return slices.Concat(list, others), err
+	return list, nil
 }
 
 func checkMisusedAlias(stmt ast.Statement) ([]rules.LintMessage, error) {
-	switch stmt := stmt.(type) {
-	case ast.SelectStatement:
-		return selectMisusedAlias(stmt)
-	case ast.UnionStatement:
-		return handleCompoundStatement(stmt.Left, stmt.Right, checkMisusedAlias)
-	case ast.IntersectStatement:
-		return handleCompoundStatement(stmt.Left, stmt.Right, checkMisusedAlias)
-	case ast.ExceptStatement:
-		return handleCompoundStatement(stmt.Left, stmt.Right, checkMisusedAlias)
-	case ast.WithStatement:
-		return handleWithStatement(stmt, checkMisusedAlias)
-	case ast.CteStatement:
-		return checkMisusedAlias(stmt.Statement)
-	case ast.Join:
-		return checkMisusedAlias(stmt.Table)
-	case ast.Group:
-		return checkMisusedAlias(stmt.Statement)
-	default:
-		return nil, ErrNa
-	}
+	return inspect(stmt, selectMisusedAlias)
 }
 
 func selectMisusedAlias(stmt ast.SelectStatement) ([]rules.LintMessage, error) {
@@ -215,49 +116,53 @@ func selectMisusedAlias(stmt ast.SelectStatement) ([]rules.LintMessage, error) {
 	for _, a := range ast.GetAliasFromStmt(stmt.Columns) {
 		ok := slices.Contains(names, a)
 		if ok {
-			list = append(list, unexpectedAlias(a))
+			list = append(list, unexpectedAlias(stmt, a))
 		}
 	}
-	others, err := handleSelectStatement(stmt, checkMisusedAlias)
-	return slices.Concat(list, others), err
+	return list, nil
 }
 
-func enforcedAlias() rules.LintMessage {
-	return rules.LintMessage{
+func enforcedAlias(stmt ast.Statement) rules.LintMessage {
+	msg := rules.LintMessage{
 		Severity: rules.Error,
 		Message:  "alias expected",
 		Rule:     ruleAliasExpected,
 	}
+	return locate(stmt, msg)
 }
 
-func unexpectedAlias(alias string) rules.LintMessage {
-	return rules.LintMessage{
+func unexpectedAlias(stmt ast.Statement, alias string) rules.LintMessage {
+	msg := rules.LintMessage{
 		Severity: rules.Error,
 		Message:  fmt.Sprintf("%s: alias not allowed in where clause", alias),
 		Rule:     ruleAliasUnexpected,
 	}
+	return locate(stmt, msg)
 }
 
-func undefinedAlias(alias string) rules.LintMessage {
-	return rules.LintMessage{
+func undefinedAlias(stmt ast.Statement, alias string) rules.LintMessage {
+	msg := rules.LintMessage{
 		Severity: rules.Error,
 		Message:  fmt.Sprintf("%s: alias not defined", alias),
 		Rule:     ruleAliasUndefined,
 	}
+	return locate(stmt, msg)
 }
 
-func missingAlias() rules.LintMessage {
-	return rules.LintMessage{
+func missingAlias(stmt ast.Statement) rules.LintMessage {
+	msg := rules.LintMessage{
 		Severity: rules.Error,
 		Message:  "alias needed but missing",
 		Rule:     ruleAliasMissing,
 	}
+	return locate(stmt, msg)
 }
 
-func duplicatedAlias(alias string) rules.LintMessage {
-	return rules.LintMessage{
+func duplicatedAlias(stmt ast.Statement, alias string) rules.LintMessage {
+	msg := rules.LintMessage{
 		Severity: rules.Error,
 		Message:  fmt.Sprintf("%s: alias already defined", alias),
 		Rule:     ruleAliasDuplicate,
 	}
+	return locate(stmt, msg)
 }
@@ -2,16 +2,18 @@ package lint
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/midbel/sweet/internal/lang/ast"
 	"github.com/midbel/sweet/internal/rules"
 )
 
 func checkDuplicateCte(stmt ast.Statement) ([]rules.LintMessage, error) {
-	with, ok := stmt.(ast.WithStatement)
-	if !ok {
-		return nil, ErrNa
-	}
+	return inspect(stmt, withDuplicateCte)
+}
+
+func withDuplicateCte(with ast.WithStatement) ([]rules.LintMessage, error) {
 	var (
 		seen = make(map[string]struct{})
 		list []rules.LintMessage
@@ -22,7 +24,7 @@ func checkDuplicateCte(stmt ast.Statement) ([]rules.LintMessage, error) {
 			return nil, fmt.Errorf("cte expected! got %T", q)
 		}
 		if _, ok := seen[c.Ident]; ok {
-			list = append(list, cteDuplicate(c.Ident))
+			list = append(list, cteDuplicate(c, c.Ident))
 			continue
 		}
 		seen[c.Ident] = struct{}{}
@@ -31,36 +33,44 @@ func checkDuplicateCte(stmt ast.Statement) ([]rules.LintMessage, error) {
 }
 
 func checkUnusedCte(stmt ast.Statement) ([]rules.LintMessage, error) {
-	with, ok := stmt.(ast.WithStatement)
-	if !ok {
-		return nil, ErrNa
-	}
-	var (
-		all  = make(map[string]struct{})
-		list []rules.LintMessage
-	)
-	for _, q := range with.Queries {
+	return inspect(stmt, withUnusedCte)
+}
+
+func withUnusedCte(with ast.WithStatement) ([]rules.LintMessage, error) {
+	var list []rules.LintMessage
+	for i, q := range with.Queries {
 		c, ok := q.(ast.CteStatement)
 		if !ok {
 			return nil, fmt.Errorf("cte expected! got %T", q)
 		}
-		all[c.Ident] = struct{}{}
-	}
-	for _, n := range with.GetNames() {
-		delete(all, n)
-	}
-	for n := range all {
-		list = append(list, cteUnused(n))
+		others := slices.Concat(with.Queries[:i], with.Queries[i+1:], []ast.Statement{with.Statement})
+		if !isCteUsed(c, others) {
+			list = append(list, cteUnused(c, c.Ident))
+		}
 	}
-
 	return list, nil
 }
 
-func checkColumnsMissingCte(stmt ast.Statement) ([]rules.LintMessage, error) {
-	with, ok := stmt.(ast.WithStatement)
-	if !ok {
-		return nil, ErrNa
+// isCteUsed reports whether the given cte is referenced by one of the given
+// statements
+func isCteUsed(cte ast.CteStatement, list []ast.Statement) bool {
+	var used bool
+	for _, s := range list {
+		ast.Inspect(s, func(stmt ast.Statement, _ ast.Path) bool {
+			if n, ok := stmt.(ast.Name); ok && len(n.Parts) == 1 && strings.EqualFold(n.Name(), cte.Ident) {
+				used = true
+			}
+			return !used
+		})
 	}
+	return used
+}
+
+func checkColumnsMissingCte(stmt ast.Statement) ([]rules.LintMessage, error) {
+	return inspect(stmt, withColumnsMissingCte)
+}
+
+func withColumnsMissingCte(with ast.WithStatement) ([]rules.LintMessage, error) {
 	var list []rules.LintMessage
 	for _, q := range with.Queries {
 		c, ok := q.(ast.CteStatement)
@@ -68,66 +78,149 @@ func checkColumnsMissingCte(stmt ast.Statement) ([]rules.LintMessage, error) {
 			return nil, fmt.Errorf("cte expected! got %T", q)
 		}
 		if len(c.Columns) == 0 {
-			list = append(list, cteColumnsMissing(c.Ident))
+			list = append(list, cteColumnsMissing(c, c.Ident))
 		}
 	}
 	return list, nil
 }
 
 func checkColumnsUnsedCte(stmt ast.Statement) ([]rules.LintMessage, error) {
-	return nil, nil
+	return inspect(stmt, withColumnsUnusedCte)
 }
 
-func checkColumnsMismatchedCte(stmt ast.Statement) ([]rules.LintMessage, error) {
-	with, ok := stmt.(ast.WithStatement)
-	if !ok {
-		return nil, ErrNa
+func withColumnsUnusedCte(with ast.WithStatement) ([]rules.LintMessage, error) {
+	var list []rules.LintMessage
+	for i, q := range with.Queries {
+		c, ok := q.(ast.CteStatement)
+		if !ok {
+			return nil, fmt.Errorf("cte expected! got %T", q)
+		}
+		next := slices.Concat(with.Queries[i+1:], []ast.Statement{with.Statement})
+		if with.Recursive {
+			next = append(next, c.Statement)
+		}
+		var (
+			used  bool
+			star  bool
+			names []string
+		)
+		for _, n := range next {
+			ast.Inspect(n, func(stmt ast.Statement, _ ast.Path) bool {
+				switch s := stmt.(type) {
+				case ast.SelectStatement:
+					star = star || slices.ContainsFunc(s.Columns, isStar)
+				case ast.Name:
+					used = used || (len(s.Parts) == 1 && strings.EqualFold(s.Name(), c.Ident))
+					names = append(names, strings.ToLower(s.Name()))
+				}
+				return true
+			})
+		}
+		if !used || star {
+			continue
+		}
+		for _, col := range getCteColumns(c) {
+			if !slices.Contains(names, strings.ToLower(col)) {
+				list = append(list, cteColumnUnused(c, c.Ident, col))
+			}
+		}
+	}
+	return list, nil
+}
+
+// getCteColumns gives the names of the columns of a cte: the names given in
+// its definition or the names of the columns of its query
+func getCteColumns(cte ast.CteStatement) []string {
+	if len(cte.Columns) > 0 {
+		return cte.Columns
+	}
+	q, ok := cte.Statement.(ast.SelectStatement)
+	if !ok || slices.ContainsFunc(q.Columns, isStar) {
+		return nil
+	}
+	var list []string
+	for _, c := range q.Columns {
+		switch c := c.(type) {
+		case ast.Alias:
+			list = append(list, c.Alias)
+		case ast.Name:
+			list = append(list, c.Name())
+		}
 	}
+	return list
+}
+
+func isStar(stmt ast.Statement) bool {
+	n, ok := stmt.(ast.Name)
+	return ok && n.Name() == "*"
+}
+
+func checkColumnsMismatchedCte(stmt ast.Statement) ([]rules.LintMessage, error) {
+	return inspect(stmt, withColumnsMismatchedCte)
+}
+
+func withColumnsMismatchedCte(with ast.WithStatement) ([]rules.LintMessage, error) {
 	var list []rules.LintMessage
 	for _, q := range with.Queries {
 		c, ok := q.(ast.CteStatement)
 		if !ok {
 			return nil, fmt.Errorf("cte expected! got %T", q)
 		}
+		if len(c.Columns) == 0 {
+			continue
+		}
 		q, ok := c.Statement.(ast.SelectStatement)
 		if !ok {
 			return nil, fmt.Errorf("select expected! got %T", q)
 		}
 		if len(c.Columns) != len(q.Columns) {
-			list = append(list, cteColumnsMismatched(c.Ident))
+			list = append(list, cteColumnsMismatched(c, c.Ident))
 		}
 	}
 	return list, nil
 }
 
-func cteColumnsMismatched(cte string) rules.LintMessage {
-	return rules.LintMessage{
+func cteColumnsMismatched(stmt ast.Statement, cte string) rules.LintMessage {
+	msg := rules.LintMessage{
 		Severity: rules.Error,
 		Message:  fmt.Sprintf("%s: columns count mismatched", cte),
 		Rule:     ruleCteColsMismatched,
 	}
+	return locate(stmt, msg)
 }
 
-func cteColumnsMissing(cte string) rules.LintMessage {
-	return rules.LintMessage{
+func cteColumnsMissing(stmt ast.Statement, cte string) rules.LintMessage {
+	msg := rules.LintMessage{
 		Severity: rules.Error,
 		Message:  fmt.Sprintf("%s: no columns defined for cte", cte),
 		Rule:     ruleCteColsMissing,
 	}
+	return locate(stmt, msg)
+}
+
+func cteColumnUnused(stmt ast.Statement, cte, column string) rules.LintMessage {
+	msg := rules.LintMessage{
+		Severity: rules.Warning,
+		Message:  fmt.Sprintf("%s: column %s declared but not used", cte, column),
+		Rule:     ruleCteColsUnused,
+	}
+	return locate(stmt, msg)
 }
 
-func cteDuplicate(cte string) rules.LintMessage {
-	return rules.LintMessage{
+func cteDuplicate(stmt ast.Statement, cte string) rules.LintMessage {
+	msg := rules.LintMessage{
 		Severity: rules.Error,
 		Message:  fmt.Sprintf("%s: cte already defined", cte),
 		Rule:     ruleCteDuplicated,
 	}
+	return locate(stmt, msg)
 }
 
-func cteUnused(cte string) rules.LintMessage {
-	return rules.LintMessage{
+func cteUnused(stmt ast.Statement, cte string) rules.LintMessage {
+	msg := rules.LintMessage{
 		Severity: rules.Error,
 		Message:  fmt.Sprintf("%s: cte declared but not used", cte),
 		Rule:     ruleCteUnused,
 	}
+	return locate(stmt, msg)
 }
@@ -3,50 +3,40 @@ package lint
 import (
 	"fmt"
 	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/midbel/sweet/internal/lang/ast"
 	"github.com/midbel/sweet/internal/rules"
 )
 
 func checkConstantBinary(stmt ast.Statement) ([]rules.LintMessage, error) {
-	switch stmt := stmt.(type) {
-	case ast.SelectStatement:
-		return selectConstantBinary(stmt)
-	case ast.UnionStatement:
-		return handleCompoundStatement(stmt.Left, stmt.Right, checkConstantBinary)
-	case ast.IntersectStatement:
-		return handleCompoundStatement(stmt.Left, stmt.Right, checkConstantBinary)
-	case ast.ExceptStatement:
-		return handleCompoundStatement(stmt.Left, stmt.Right, checkConstantBinary)
-	case ast.WithStatement:
-		return handleWithStatement(stmt, checkConstantBinary)
-	case ast.CteStatement:
-		return checkConstantBinary(stmt.Statement)
-	case ast.Join:
-		return joinConstantBinary(stmt)
-	case ast.Group:
-		return checkConstantBinary(stmt.Statement)
-	default:
-		return nil, ErrNa
-	}
+	return inspect(stmt, func(stmt ast.Statement) ([]rules.LintMessage, error) {
+		switch stmt := stmt.(type) {
+		case ast.SelectStatement:
+			return selectConstantBinary(stmt)
+		case ast.Join:
+			return joinConstantBinary(stmt)
+		default:
+			return nil, nil
+		}
+	})
 }
 
 func selectConstantBinary(stmt ast.SelectStatement) ([]rules.LintMessage, error) {
 	var list []rules.LintMessage
 	if isConstant(stmt.Where) {
-		list = append(list, constantOnlyExpr())
+		list = append(list, constantOnlyExpr(stmt.Where))
 	}
-	others, err := handleSelectStatement(stmt, checkConstantBinary)
-	return slices.Concat(list, others), err
+	return list, nil
 }
 
 func joinConstantBinary(stmt ast.Join) ([]rules.LintMessage, error) {
 	var list []rules.LintMessage
 	if isConstant(stmt.Where) {
-		list = append(list, constantOnlyExpr())
+		list = append(list, constantOnlyExpr(stmt.Where))
 	}
-	others, err := checkConstantBinary(stmt.Table)
-	return slices.Concat(list, others), err
+	return list, nil
 }
 
 func isConstant(stmt ast.Statement) bool {
@@ -77,26 +67,7 @@ func isConstant(stmt ast.Statement) bool {
 }
 
 func checkResultSubquery(stmt ast.Statement) ([]rules.LintMessage, error) {
-	switch stmt := stmt.(type) {
-	case ast.SelectStatement:
-		return selectResultSubquery(stmt)
-	case ast.UnionStatement:
-		return handleCompoundStatement(stmt.Left, stmt.Right, checkResultSubquery)
-	case ast.IntersectStatement:
-		return handleCompoundStatement(stmt.Left, stmt.Right, checkResultSubquery)
-	case ast.ExceptStatement:
-		return handleCompoundStatement(stmt.Left, stmt.Right, checkResultSubquery)
-	case ast.WithStatement:
-		return handleWithStatement(stmt, checkResultSubquery)
-	case ast.CteStatement:
-		return checkResultSubquery(stmt.Statement)
-	case ast.Join:
-		return checkResultSubquery(stmt.Table)
-	case ast.Group:
-		return checkResultSubquery(stmt.Statement)
-	default:
-		return nil, ErrNa
-	}
+	return inspect(stmt, selectResultSubquery)
 }
 
 func selectResultSubquery(stmt ast.SelectStatement) ([]rules.LintMessage, error) {
@@ -107,87 +78,192 @@ func selectResultSubquery(stmt ast.SelectStatement) ([]rules.LintMessage, error)
 			continue
 		}
 		if len(q.Columns) != 1 {
-			list = append(list, subqueryTooManyResult())
+			list = append(list, subqueryTooManyResult(q))
 		}
 	}
-	others, err := handleSelectStatement(stmt, checkResultSubquery)
-	return slices.Concat(list, others), err
+	return list, nil
 }
 
 func checkGroupBy(stmt ast.Statement) ([]rules.LintMessage, error) {
-	switch stmt := stmt.(type) {
-	case ast.SelectStatement:
-		return selectGroupBy(stmt)
-	case ast.UnionStatement:
-		return handleCompoundStatement(stmt.Left, stmt.Right, checkGroupBy)
-	case ast.IntersectStatement:
-		return handleCompoundStatement(stmt.Left, stmt.Right, checkGroupBy)
-	case ast.ExceptStatement:
-		return handleCompoundStatement(stmt.Left, stmt.Right, checkGroupBy)
-	case ast.WithStatement:
-		return handleWithStatement(stmt, checkGroupBy)
-	case ast.CteStatement:
-		return checkGroupBy(stmt.Statement)
-	case ast.Join:
-		return checkGroupBy(stmt.Table)
-	case ast.Group:
-		return checkGroupBy(stmt.Statement)
-	default:
-		return nil, ErrNa
-	}
+	return inspect(stmt, selectGroupBy)
 }
 
 func selectGroupBy(stmt ast.SelectStatement) ([]rules.LintMessage, error) {
-	if len(stmt.Groups) == 0 {
+	aggregated := slices.ContainsFunc(stmt.Columns, func(c ast.Statement) bool {
+		return len(getAggregates(c)) > 0
+	})
+	if len(stmt.Groups) == 0 && !aggregated {
 		return nil, nil
 	}
 	var (
-		list   []rules.LintMessage
-		groups = ast.GetNamesFromStmt(stmt.Groups)
+		list    []rules.LintMessage
+		names   []ast.Name
+		grouped = make([]bool, len(stmt.Columns))
 	)
-	for _, c := range stmt.Columns {
+	for _, g := range stmt.Groups {
+		if i, ok := getGroupedColumn(stmt.Columns, g); ok {
+			grouped[i] = true
+			continue
+		}
+		names = append(names, getColumnRefs(g)...)
+	}
+	for i, c := range stmt.Columns {
+		if grouped[i] {
+			continue
+		}
 		if a, ok := c.(ast.Alias); ok {
 			c = a.Statement
 		}
-		switch c := c.(type) {
-		case ast.Value:
+		for _, n := range getColumnRefs(c) {
+			if !containsName(names, n) {
+				list = append(list, exprNotInGroupBy(n, n.Ident()))
+			}
+		}
+	}
+	alias := ast.GetAliasFromStmt(stmt.Columns)
+	for _, n := range getColumnRefs(stmt.Having) {
+		if len(n.Parts) == 1 && slices.Contains(alias, n.Name()) {
+			continue
+		}
+		if !containsName(names, n) {
+			list = append(list, exprNotInGroupBy(n, n.Ident()))
+		}
+	}
+	return list, nil
+}
+
+// getGroupedColumn gives the index of the column referenced by a group by
+// expression given as an ordinal or as the alias of the column
+func getGroupedColumn(columns []ast.Statement, group ast.Statement) (int, bool) {
+	switch g := group.(type) {
+	case ast.Value:
+		n, err := strconv.Atoi(g.Literal)
+		if err != nil || n < 1 || n > len(columns) {
+			return 0, false
+		}
+		return n - 1, true
+	case ast.Name:
+		if len(g.Parts) != 1 {
+			return 0, false
+		}
+		i := slices.IndexFunc(columns, func(c ast.Statement) bool {
+			a, ok := c.(ast.Alias)
+			return ok && strings.EqualFold(a.Alias, g.Name())
+		})
+		return i, i >= 0
+	default:
+		return 0, false
+	}
+}
+
+// containsName reports whether name is in the list. An unqualified name matches
+// a qualified one with the same column.
+func containsName(list []ast.Name, name ast.Name) bool {
+	return slices.ContainsFunc(list, func(n ast.Name) bool {
+		if len(n.Parts) == 1 || len(name.Parts) == 1 {
+			return strings.EqualFold(n.Name(), name.Name())
+		}
+		return strings.EqualFold(n.Ident(), name.Ident())
+	})
+}
+
+// getColumnRefs gives the columns used by stmt outside of aggregate functions
+// and subqueries
+func getColumnRefs(stmt ast.Statement) []ast.Name {
+	var list []ast.Name
+	ast.Inspect(stmt, func(stmt ast.Statement, _ ast.Path) bool {
+		switch s := stmt.(type) {
 		case ast.Name:
-			if !slices.Contains(groups, c.Ident()) {
-				list = append(list, exprNotInGroupBy(c.Ident()))
+			list = append(list, s)
+		case ast.Call:
+			if isAggregate(s) {
+				return false
+			}
+			for _, a := range s.Args {
+				list = append(list, getColumnRefs(a)...)
 			}
+			return false
+		case ast.Value, ast.SelectStatement, ast.UnionStatement, ast.IntersectStatement, ast.ExceptStatement:
+			return false
+		}
+		return true
+	})
+	return list
+}
+
+// getAggregates gives the calls to aggregate functions found in stmt outside of
+// subqueries
+func getAggregates(stmt ast.Statement) []ast.Call {
+	var list []ast.Call
+	ast.Inspect(stmt, func(stmt ast.Statement, _ ast.Path) bool {
+		switch s := stmt.(type) {
 		case ast.Call:
-			if !c.IsAggregate() {
-				list = append(list, aggregateExpected(c.GetIdent()))
+			if isAggregate(s) {
+				list = append(list, s)
+				return false
 			}
+		case ast.SelectStatement, ast.UnionStatement, ast.IntersectStatement, ast.ExceptStatement:
+			return false
+		}
+		return true
+	})
+	return list
+}
+
+// isAggregate reports whether stmt is a call to an aggregate function that is
+// not used as a window function
+func isAggregate(stmt ast.Statement) bool {
+	c, ok := stmt.(ast.Call)
+	return ok && c.Over == nil && c.IsAggregate()
+}
+
+func checkInvalidExpr(stmt ast.Statement) ([]rules.LintMessage, error) {
+	return inspect(stmt, func(stmt ast.Statement) ([]rules.LintMessage, error) {
+		switch stmt := stmt.(type) {
+		case ast.SelectStatement:
+			return selectInvalidExpr(stmt)
+		case ast.Join:
+			return aggregateNotAllowed(stmt.Where, "join"), nil
+		case ast.Call:
+			return callInvalidExpr(stmt)
 		default:
-			list = append(list, unexpectedExpr(""))
+			return nil, nil
 		}
+	})
+}
+
+func selectInvalidExpr(stmt ast.SelectStatement) ([]rules.LintMessage, error) {
+	var list []rules.LintMessage
+	list = append(list, aggregateNotAllowed(stmt.Where, "where")...)
+	for _, g := range stmt.Groups {
+		list = append(list, aggregateNotAllowed(g, "group by")...)
 	}
-	others, err := handleSelectStatement(stmt, checkGroupBy)
-	return slices.Concat(list, others), err
+	return list, nil
 }
 
-func checkAsUsage(stmt ast.Statement) ([]rules.LintMessage, error) {
-	switch stmt := stmt.(type) {
-	case ast.SelectStatement:
-		return selectInconsistentAs(stmt)
-	case ast.UnionStatement:
-		return handleCompoundStatement(stmt.Left, stmt.Right, checkAsUsage)
-	case ast.IntersectStatement:
-		return handleCompoundStatement(stmt.Left, stmt.Right, checkAsUsage)
-	case ast.ExceptStatement:
-		return handleCompoundStatement(stmt.Left, stmt.Right, checkAsUsage)
-	case ast.WithStatement:
-		return handleWithStatement(stmt, checkAsUsage)
-	case ast.CteStatement:
-		return checkAsUsage(stmt.Statement)
-	case ast.Join:
-		return checkAsUsage(stmt.Table)
-	case ast.Group:
-		return checkAsUsage(stmt.Statement)
-	default:
-		return nil, ErrNa
+func callInvalidExpr(stmt ast.Call) ([]rules.LintMessage, error) {
+	if !isAggregate(stmt) {
+		return nil, nil
+	}
+	var list []rules.LintMessage
+	for _, a := range stmt.Args {
+		for _, c := range getAggregates(a) {
+			list = append(list, nestedAggregate(c, c.GetIdent()))
+		}
 	}
+	return list, nil
+}
+
+func aggregateNotAllowed(stmt ast.Statement, clause string) []rules.LintMessage {
+	var list []rules.LintMessage
+	for _, c := range getAggregates(stmt) {
+		list = append(list, aggregateInClause(c, c.GetIdent(), clause))
+	}
+	return list
+}
+
+func checkAsUsage(stmt ast.Statement) ([]rules.LintMessage, error) {
+	return inspect(stmt, selectInconsistentAs)
 }
 
 func selectInconsistentAs(stmt ast.SelectStatement) ([]rules.LintMessage, error) {
@@ -205,7 +281,7 @@ func selectInconsistentAs(stmt ast.SelectStatement) ([]rules.LintMessage, error)
 		}
 	}
 	if used && len(stmt.Columns) > 1 {
-		list = append(list, inconsistentAs("select"))
+		list = append(list, inconsistentAs(stmt, "select"))
 	}
 	used = false
 	for _, s := range stmt.Tables {
@@ -222,37 +298,50 @@ func selectInconsistentAs(stmt ast.SelectStatement) ([]rules.LintMessage, error)
 		}
 	}
 	if used && len(stmt.Tables) > 1 {
-		list = append(list, inconsistentAs("from"))
+		list = append(list, inconsistentAs(stmt, "from"))
 	}
-	others, err := handleSelectStatement(stmt, checkAsUsage)
-	return slices.Concat(list, others), err
+	return list, nil
 }
 
 func checkDirectionUsage(stmt ast.Statement) ([]rules.LintMessage, error) {
+	return inspect(stmt, func(stmt ast.Statement) ([]rules.LintMessage, error) {
+		switch stmt := stmt.(type) {
+		case ast.SelectStatement:
+			return ordersInconsistentDir(stmt, stmt.Orders)
+		case ast.ValuesStatement:
+			return ordersInconsistentDir(stmt, stmt.Orders)
+		case ast.Window:
+			return ordersInconsistentDir(stmt, stmt.Orders)
+		default:
+			return nil, nil
+		}
+	})
+}
+
+// ordersInconsistentDir checks that the direction of the orders is given
+// for all or none of them when ASC is used
+func ordersInconsistentDir(stmt ast.Statement, orders []ast.Statement) ([]rules.LintMessage, error) {
+	var asc, implicit bool
+	for _, o := range orders {
+		o, ok := o.(ast.Order)
+		if !ok {
+			continue
+		}
+		switch o.Dir {
+		case ast.AscOrder:
+			asc = true
+		case 0:
+			implicit = true
+		}
+	}
+	if asc && implicit {
+		return makeArray(inconsistentOrder(stmt)), nil
+	}
 	return nil, nil
 }
 
 func checkForUnqualifiedNames(stmt ast.Statement) ([]rules.LintMessage, error) {
-	switch stmt := stmt.(type) {
-	case ast.SelectStatement:
-		return selectUnqualifiedNames(stmt)
-	case ast.UnionStatement:
-		return handleCompoundStatement(stmt.Left, stmt.Right, checkForUnqualifiedNames)
-	case ast.IntersectStatement:
-		return handleCompoundStatement(stmt.Left, stmt.Right, checkForUnqualifiedNames)
-	case ast.ExceptStatement:
-		return handleCompoundStatement(stmt.Left, stmt.Right, checkForUnqualifiedNames)
-	case ast.WithStatement:
-		return handleWithStatement(stmt, checkForUnqualifiedNames)
-	case ast.CteStatement:
-		return checkForUnqualifiedNames(stmt.Statement)
-	case ast.Join:
-		return checkForUnqualifiedNames(stmt.Table)
-	case ast.Group:
-		return checkForUnqualifiedNames(stmt.Statement)
-	default:
-		return nil, ErrNa
-	}
+	return inspect(stmt, selectUnqualifiedNames)
 }
 
 func selectUnqualifiedNames(stmt ast.SelectStatement) ([]rules.LintMessage, error) {
@@ -269,73 +358,80 @@ func selectUnqualifiedNames(stmt ast.SelectStatement) ([]rules.LintMessage, erro
 			continue
 		}
 		if len(n.Parts) == 1 && len(names) > 0 {
-			list = append(list, unqualifiedName(n.Ident()))
+			list = append(list, unqualifiedName(n, n.Ident()))
 		}
 	}
-	others, err := handleSelectStatement(stmt, checkForUnqualifiedNames)
-	return slices.Concat(list, others), err
+	return list, nil
 }
 
-func unqualifiedName(name string) rules.LintMessage {
-	return rules.LintMessage{
+func unqualifiedName(stmt ast.Statement, name string) rules.LintMessage {
+	msg := rules.LintMessage{
 		Severity: rules.Error,
 		Message:  fmt.Sprintf("%s should be fully qualified", name),
 		Rule:     ruleExprUnqualified,
 	}
+	return locate(stmt, msg)
 }
 
-func inconsistentAs(clause string) rules.LintMessage {
-	return rules.LintMessage{
+func inconsistentAs(stmt ast.Statement, clause string) rules.LintMessage {
+	msg := rules.LintMessage{
 		Severity: rules.Warning,
 		Message:  fmt.Sprintf("%s: inconsistent use of AS", clause),
 		Rule:     ruleInconsistentUseAs,
 	}
+	return locate(stmt, msg)
 }
 
-func inconsistentOrder() rules.LintMessage {
-	return rules.LintMessage{
+func inconsistentOrder(stmt ast.Statement) rules.LintMessage {
+	msg := rules.LintMessage{
 		Severity: rules.Warning,
 		Message:  "inconsistent use of ASC/DESC",
 		Rule:     ruleInconsistentUseOrder,
 	}
+	return locate(stmt, msg)
 }
 
-func aggregateExpected(ident string) rules.LintMessage {
-	return rules.LintMessage{
+func exprNotInGroupBy(stmt ast.Statement, ident string) rules.LintMessage {
+	msg := rules.LintMessage{
 		Severity: rules.Error,
-		Message:  fmt.Sprintf("%s should be an aggregate function", ident),
+		Message:  fmt.Sprintf("%s should be used in group by clause or in an aggregate function", ident),
 		Rule:     ruleExprAggregate,
 	}
+	return locate(stmt, msg)
 }
 
-func exprNotInGroupBy(ident string) rules.LintMessage {
-	return rules.LintMessage{
+func aggregateInClause(stmt ast.Statement, ident, clause string) rules.LintMessage {
+	msg := rules.LintMessage{
 		Severity: rules.Error,
-		Message:  fmt.Sprintf("%s should be used in group by clause", ident),
+		Message:  fmt.Sprintf("%s: aggregate function not allowed in %s clause", ident, clause),
 		Rule:     ruleExprInvalid,
 	}
+	return locate(stmt, msg)
 }
 
-func unexpectedExpr(ident string) rules.LintMessage {
-	return rules.LintMessage{
+func nestedAggregate(stmt ast.Statement, ident string) rules.LintMessage {
+	msg := rules.LintMessage{
 		Severity: rules.Error,
-		Message:  "%s: unexpected expression",
+		Message:  fmt.Sprintf("%s: aggregate functions can not be nested", ident),
 		Rule:     ruleExprInvalid,
 	}
+	return locate(stmt, msg)
 }
 
-func subqueryTooManyResult() rules.LintMessage {
-	return rules.LintMessage{
+func subqueryTooManyResult(stmt ast.Statement) rules.LintMessage {
+	msg := rules.LintMessage{
 		Severity: rules.Error,
 		Message:  "too many result returned by subquery",
 		Rule:     ruleSubqueryColsMismatched,
 	}
+	return locate(stmt, msg)
 }
 
-func constantOnlyExpr() rules.LintMessage {
-	return rules.LintMessage{
+func constantOnlyExpr(stmt ast.Statement) rules.LintMessage {
+	msg := rules.LintMessage{
 		Severity: rules.Error,
 		Message:  "expression composed of constant values",
 		Rule:     ruleConstExprBin,
 	}
+	return locate(stmt, msg)
 }
@@ -0,0 +1,53 @@
+package lint
+
+import (
+	"fmt"
+
+	"github.com/midbel/sweet/internal/config"
+	"github.com/midbel/sweet/internal/lang"
+	"github.com/midbel/sweet/internal/lang/ast"
+	"github.com/midbel/sweet/internal/rules"
+)
+
+func buildQuotedIdentRule(_ *config.Config, d lang.Dialect) (RuleFunc, error) {
+	fn := func(stmt ast.Statement) ([]rules.LintMessage, error) {
+		return inspect(stmt, func(stmt ast.Statement) ([]rules.LintMessage, error) {
+			switch stmt := stmt.(type) {
+			case ast.Alias:
+				return aliasQuotedIdent(stmt, d)
+			case ast.Name:
+				return nameQuotedIdent(stmt, d)
+			default:
+				return nil, nil
+			}
+		})
+	}
+	return fn, nil
+}
+
+func aliasQuotedIdent(stmt ast.Alias, d lang.Dialect) ([]rules.LintMessage, error) {
+	var list []rules.LintMessage
+	if stmt.Quoted && d.NeedQuote(stmt.Alias) {
+		list = append(list, quoteSensitive(stmt, stmt.Alias))
+	}
+	return list, nil
+}
+
+func nameQuotedIdent(stmt ast.Name, d lang.Dialect) ([]rules.LintMessage, error) {
+	var list []rules.LintMessage
+	for i, p := range stmt.Parts {
+		if stmt.IsQuoted(i) && d.NeedQuote(p) {
+			list = append(list, quoteSensitive(stmt, p))
+		}
+	}
+	return list, nil
+}
+
+func quoteSensitive(stmt ast.Statement, ident string) rules.LintMessage {
+	msg := rules.LintMessage{
+		Severity: rules.Warning,
+		Message:  fmt.Sprintf("%s: meaning of identifier changes when unquoted", ident),
+		Rule:     ruleIdentQuoteSensitive,
+	}
+	return locate(stmt, msg)
+}
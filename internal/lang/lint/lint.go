@@ -4,21 +4,43 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"slices"
 
 	"github.com/midbel/sweet/internal/config"
+	"github.com/midbel/sweet/internal/lang"
 	"github.com/midbel/sweet/internal/lang/ast"
-	"github.com/midbel/sweet/internal/lang/parser"
+	_ "github.com/midbel/sweet/internal/lang/parser"
 	"github.com/midbel/sweet/internal/rules"
 )
 
 var ErrNa = errors.New("not applicable")
 
+// script holds the statements of an input. It is given to the rules that can
+// only be checked once the whole input has been parsed. Its statements are not
+// visited when the script is inspected.
+type script struct {
+	statements []ast.Statement
+}
+
+// scriptRules are the rules checked on the script of an input
+var scriptRules = []string{
+	ruleMigrationForeignIndex,
+	ruleSecurityRevoke,
+	ruleProcedureTransaction,
+}
+
 type Linter struct {
 	MinLevel   rules.Level
 	Max        int
 	AbortOnErr bool
+	SkipRows   bool
+	Env        string
 	rules      rules.Map[ast.Statement]
+	dialect    lang.Dialect
+	// configs are the configurations given to Configure. They are applied
+	// again when the rules are built for another dialect.
+	configs []*config.Config
 }
 
 func NewLinter() *Linter {
@@ -27,9 +49,69 @@ func NewLinter() *Linter {
 		Max:      0,
 		rules:    getDefaultRules(),
 	}
+	i.dialect, _ = lang.GetDialect(lang.DefaultDialect)
 	return &i
 }
 
+// Clone gives a linter with the same settings and rules. The rules enabled by
+// the macros of an input only affect the clone used to lint it, so that clones
+// can be used concurrently.
+func (i *Linter) Clone() *Linter {
+	c := *i
+	c.rules = maps.Clone(i.rules)
+	c.configs = slices.Clip(i.configs)
+	return &c
+}
+
+// SetDialect selects the dialect used to parse the input. The rules are built
+// again for the dialect: the default rules of the linter and the ones of the
+// dialect are enabled and the configurations given to the linter are applied
+// once more.
+func (i *Linter) SetDialect(name string) error {
+	d, err := lang.GetDialect(name)
+	if err != nil {
+		return err
+	}
+	i.dialect = d
+
+	set := getDefaultRules()
+	for _, n := range d.Rules {
+		list, err := getRulesByName(n)
+		if err != nil {
+			return err
+		}
+		for _, r := range list {
+			set.Register(r.Name, defaultPriority, r.Func)
+		}
+	}
+	i.rules = set
+	if err := i.rebuildRules(); err != nil {
+		return err
+	}
+	for _, cfg := range i.configs {
+		if err := i.apply(cfg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rebuildRules builds again the enabled rules depending on the dialect
+func (i *Linter) rebuildRules() error {
+	for n, r := range i.rules {
+		build, ok := ruleBuilders[n]
+		if !ok {
+			continue
+		}
+		fn, err := build(config.Make(), i.dialect)
+		if err != nil {
+			return err
+		}
+		i.rules.Register(n, r.Priority, fn)
+	}
+	return nil
+}
+
 func (i *Linter) Rules() []rules.LintInfo {
 	var infos []rules.LintInfo
 	for _, n := range GetRuleNames() {
@@ -42,15 +124,43 @@ func (i *Linter) Rules() []rules.LintInfo {
 	return infos
 }
 
+// Lint parses and checks the statements of r. The messages silenced by the
+// sweet: directives found in the comments of r are not reported.
 func (i *Linter) Lint(r io.Reader) ([]rules.LintMessage, error) {
-	p, err := parser.NewParser(r)
+	sups := newSuppressions(r)
+	p, err := i.dialect.Parse(r)
 	if err != nil {
 		return nil, err
 	}
-	if ps, ok := p.(*parser.Parser); ok {
-		i.configure(ps.Config)
+	cm, _ := p.(lang.Commenter)
+	if cm != nil {
+		cm.KeepComments(true)
 	}
-	var list []rules.LintMessage
+	comments := func() {
+		if cm != nil {
+			sups.Add(cm.Comments())
+		}
+	}
+	if c, ok := p.(lang.Configurable); ok {
+		if err := i.Configure(c.Settings()); err != nil {
+			return nil, err
+		}
+	}
+	if rs, ok := p.(lang.RowSkipper); ok && i.SkipRows {
+		rs.SkipRows(true)
+	}
+	rp, recover := p.(lang.Recoverable)
+	if recover = recover && !i.AbortOnErr; recover {
+		rp.SetRecover(true)
+	}
+	var (
+		list []rules.LintMessage
+		all  script
+		keep = slices.ContainsFunc(scriptRules, func(n string) bool {
+			_, ok := i.rules[n]
+			return ok
+		})
+	)
 	for {
 		stmt, err := p.Parse()
 		if err != nil {
@@ -59,16 +169,46 @@ func (i *Linter) Lint(r io.Reader) ([]rules.LintMessage, error) {
 			}
 			return nil, err
 		}
+		if x, ok := stmt.(ast.Invalid); ok {
+			if x.Statement == nil {
+				continue
+			}
+			stmt = x.Statement
+		}
+		if keep {
+			all.statements = append(all.statements, stmt)
+		}
 		others, err := i.LintStatement(stmt)
 		if err != nil {
 			return nil, err
 		}
-		list = append(list, others...)
+		comments()
+		list = append(list, sups.Filter(others)...)
 		if i.Max > 0 && len(list) >= i.Max {
 			list = list[:i.Max]
+			keep = false
 			break
 		}
 	}
+	// the directives found after the statements only change the messages
+	// silenced for the whole file
+	comments()
+	list = sups.Filter(list)
+	if keep && len(all.statements) > 0 {
+		others, err := i.LintStatement(all)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, sups.Filter(others)...)
+		if i.Max > 0 && len(list) >= i.Max {
+			list = list[:i.Max]
+		}
+	}
+	if recover {
+		if errs := rp.Errors(); len(errs) > 0 {
+			return list, lang.ParseErrors(errs)
+		}
+	}
 	return list, nil
 }
 
@@ -82,11 +222,39 @@ func (i *Linter) LintStatement(stmt ast.Statement) ([]rules.LintMessage, error)
 			}
 			return nil, err
 		}
-		list = append(list, res...)
+		for j := range res {
+			if res[j].Severity < i.MinLevel {
+				continue
+			}
+			list = append(list, locate(stmt, res[j]))
+		}
 	}
 	return list, nil
 }
 
+// Configure enables, disables or changes the level and priority of the rules
+// found in the lint section of cfg. A rule is given either as a boolean or as
+// a configuration with a level, a priority and the options of the rule. The
+// name of a group of rules applies to every rule of the group. When an
+// environment is set, the lint section of this environment is applied after.
+func (i *Linter) Configure(cfg *config.Config) error {
+	if err := i.apply(cfg); err != nil {
+		return err
+	}
+	i.configs = append(i.configs, cfg)
+	return nil
+}
+
+func (i *Linter) apply(cfg *config.Config) error {
+	if err := i.configure(cfg.Sub("lint")); err != nil {
+		return err
+	}
+	if i.Env == "" {
+		return nil
+	}
+	return i.configure(cfg.Sub("env").Sub(i.Env).Sub("lint"))
+}
+
 func (i *Linter) configure(cfg *config.Config) error {
 	for _, k := range cfg.Keys() {
 		set, err := getRulesByName(k)
@@ -97,6 +265,7 @@ func (i *Linter) configure(cfg *config.Config) error {
 			enabled  bool
 			level    rules.Level
 			priority = defaultPriority
+			options  = config.Make()
 		)
 
 		v := cfg.Get(k)
@@ -104,13 +273,25 @@ func (i *Linter) configure(cfg *config.Config) error {
 			enabled = b
 		} else if x, ok := v.(*config.Config); ok {
 			level = rules.GetLevelFromName(x.GetString("level"))
-			if level < rules.Default {
+			if level == rules.Unknown {
 				return fmt.Errorf("unknown level %q", x.GetString("level"))
 			}
-			priority = int(x.GetInt("priority"))
-			enabled = true
+			priority = int(x.GetDefaultInt("priority", defaultPriority))
+			enabled, options = true, x
 		}
 		for _, fn := range set {
+			if fn.Func == nil {
+				continue
+			}
+			if !enabled {
+				delete(i.rules, fn.Name)
+				continue
+			}
+			if build, ok := ruleBuilders[fn.Name]; ok {
+				if fn.Func, err = build(options, i.dialect); err != nil {
+					return err
+				}
+			}
 			fn.Func = customizeRule(fn.Func, enabled, level)
 			i.rules.Register(fn.Name, priority, fn.Func)
 		}
@@ -118,27 +299,20 @@ func (i *Linter) configure(cfg *config.Config) error {
 	return nil
 }
 
-func checkJoin(stmt ast.Statement) ([]rules.LintMessage, error) {
-	switch stmt := stmt.(type) {
-	case ast.SelectStatement:
-		return handleSelectStatement(stmt, checkJoin)
-	case ast.UnionStatement:
-		return handleCompoundStatement(stmt.Left, stmt.Right, checkJoin)
-	case ast.IntersectStatement:
-		return handleCompoundStatement(stmt.Left, stmt.Right, checkJoin)
-	case ast.ExceptStatement:
-		return handleCompoundStatement(stmt.Left, stmt.Right, checkJoin)
-	case ast.WithStatement:
-		return handleWithStatement(stmt, checkJoin)
-	case ast.CteStatement:
-		return checkJoin(stmt.Statement)
-	case ast.Join:
-		return joinWithConstant(stmt)
-	case ast.Group:
-		return checkJoin(stmt.Statement)
-	default:
-		return nil, ErrNa
+// Settings gives the rules enabled in the linter as a configuration accepted
+// by Configure
+func (i *Linter) Settings() *config.Config {
+	set := config.Make()
+	for n := range i.rules {
+		set.Set(n, true)
 	}
+	cfg := config.Make()
+	cfg.Set("lint", set)
+	return cfg
+}
+
+func checkJoin(stmt ast.Statement) ([]rules.LintMessage, error) {
+	return inspect(stmt, joinWithConstant)
 }
 
 func joinWithConstant(stmt ast.Join) ([]rules.LintMessage, error) {
@@ -161,32 +335,13 @@ func joinWithConstant(stmt ast.Join) ([]rules.LintMessage, error) {
 		}
 	}
 	if check(stmt.Where) {
-		return makeArray(constantJoin()), nil
+		return makeArray(constantJoin(stmt)), nil
 	}
 	return nil, nil
 }
 
 func checkSubqueriesNotAllow(stmt ast.Statement) ([]rules.LintMessage, error) {
-	switch stmt := stmt.(type) {
-	case ast.SelectStatement:
-		return selectSubqueries(stmt)
-	case ast.UnionStatement:
-		return handleCompoundStatement(stmt.Left, stmt.Right, checkSubqueriesNotAllow)
-	case ast.IntersectStatement:
-		return handleCompoundStatement(stmt.Left, stmt.Right, checkSubqueriesNotAllow)
-	case ast.ExceptStatement:
-		return handleCompoundStatement(stmt.Left, stmt.Right, checkSubqueriesNotAllow)
-	case ast.WithStatement:
-		return handleWithStatement(stmt, checkSubqueriesNotAllow)
-	case ast.CteStatement:
-		return checkSubqueriesNotAllow(stmt.Statement)
-	case ast.Join:
-		return checkSubqueriesNotAllow(stmt.Table)
-	case ast.Group:
-		return checkSubqueriesNotAllow(stmt.Statement)
-	default:
-		return nil, ErrNa
-	}
+	return inspect(stmt, selectSubqueries)
 }
 
 func selectSubqueries(stmt ast.SelectStatement) ([]rules.LintMessage, error) {
@@ -203,7 +358,7 @@ func selectSubqueries(stmt ast.SelectStatement) ([]rules.LintMessage, error) {
 	var list []rules.LintMessage
 	for _, c := range stmt.Columns {
 		if isSubquery(c) {
-			list = append(list, subqueryDisallow())
+			list = append(list, subqueryDisallow(c))
 		}
 	}
 	for _, t := range stmt.Tables {
@@ -212,165 +367,68 @@ func selectSubqueries(stmt ast.SelectStatement) ([]rules.LintMessage, error) {
 			continue
 		}
 		if isSubquery(j.Table) {
-			list = append(list, subqueryDisallow())
+			list = append(list, subqueryDisallow(j))
 		}
 	}
-	others, err := handleSelectStatement(stmt, checkSubqueriesNotAllow)
-	return slices.Concat(list, others), err
+	return list, nil
 }
 
-func subqueryDisallow() rules.LintMessage {
-	return rules.LintMessage{
+func subqueryDisallow(stmt ast.Statement) rules.LintMessage {
+	msg := rules.LintMessage{
 		Severity: rules.Error,
 		Message:  "subquery is not allowed",
 		Rule:     ruleSubqueryNotAllow,
 	}
+	return locate(stmt, msg)
 }
 
-func constantJoin() rules.LintMessage {
-	return rules.LintMessage{
+func constantJoin(stmt ast.Statement) rules.LintMessage {
+	msg := rules.LintMessage{
 		Severity: rules.Error,
 		Message:  "join expression composed of constant values",
 		Rule:     ruleConstExprJoin,
 	}
+	return locate(stmt, msg)
 }
 
-func handleExpr(stmt ast.Statement, check RuleFunc) ([]rules.LintMessage, error) {
-	var list []rules.LintMessage
-	switch stmt := stmt.(type) {
-	case ast.Case:
-		msg, err := check(stmt.Cdt)
-		if err != nil && !errors.Is(err, ErrNa) {
-			return nil, err
-		}
-		list = slices.Concat(list, msg)
-		for _, b := range stmt.Body {
-			msg, err = check(b)
-			if err != nil && !errors.Is(err, ErrNa) {
-				return nil, err
-			}
-			list = slices.Concat(list, msg)
-		}
-		msg, err = check(stmt.Else)
-		if err != nil && !errors.Is(err, ErrNa) {
-			return nil, err
-		}
-		list = slices.Concat(list, msg)
-	case ast.Between:
-		l0, err := check(stmt.Ident)
-		if err != nil && !errors.Is(err, ErrNa) {
-			return nil, err
-		}
-		l1, err := check(stmt.Lower)
-		if err != nil && !errors.Is(err, ErrNa) {
-			break
-		}
-		l2, err := check(stmt.Upper)
-		if err != nil && !errors.Is(err, ErrNa) {
-			break
-		}
-		list = slices.Concat(l0, l1, l2)
-	case ast.In:
-		l0, err := check(stmt.Ident)
-		if err != nil && !errors.Is(err, ErrNa) {
-			return nil, err
-		}
-		l1, err := check(stmt.Value)
-		if err != nil && !errors.Is(err, ErrNa) {
-			break
-		}
-		list = slices.Concat(l0, l1)
-	case ast.Is:
-		l0, err := check(stmt.Ident)
-		if err != nil && !errors.Is(err, ErrNa) {
-			return nil, err
-		}
-		l1, err := check(stmt.Value)
-		if err != nil && !errors.Is(err, ErrNa) {
-			break
-		}
-		list = slices.Concat(l0, l1)
-	case ast.List:
-		for _, v := range stmt.Values {
-			others, err := check(v)
-			if err != nil && !errors.Is(err, ErrNa) {
-				return nil, err
-			}
-			list = slices.Concat(list, others)
-		}
-	case ast.Binary:
-		l0, err := check(stmt.Left)
-		if err != nil && !errors.Is(err, ErrNa) {
-			return nil, err
+// inspect calls check for every node of type T found in stmt and collects
+// the messages it gives
+func inspect[T ast.Statement](stmt ast.Statement, check func(T) ([]rules.LintMessage, error)) ([]rules.LintMessage, error) {
+	var (
+		list []rules.LintMessage
+		err  error
+	)
+	ast.Inspect(stmt, func(stmt ast.Statement, _ ast.Path) bool {
+		if err != nil {
+			return false
 		}
-		l1, err := check(stmt.Right)
-		if err != nil && !errors.Is(err, ErrNa) {
-			break
+		n, ok := stmt.(T)
+		if !ok {
+			return true
 		}
-		list = slices.Concat(l0, l1)
-	default:
-		return nil, ErrNa
-	}
-	return list, nil
-}
-
-func handleCompoundStatement(q1, q2 ast.Statement, check RuleFunc) ([]rules.LintMessage, error) {
-	list1, err := check(q1)
-	if err != nil && !errors.Is(err, ErrNa) {
-		return nil, err
-	}
-	list2, err := check(q2)
-	if err != nil && !errors.Is(err, ErrNa) {
-		return nil, err
-	}
-	return append(list1, list2...), nil
-}
-
-func handleWithStatement(with ast.WithStatement, check RuleFunc) ([]rules.LintMessage, error) {
-	var list []rules.LintMessage
-	for _, q := range with.Queries {
-		ms, err := check(q)
-		if err != nil {
-			if !errors.Is(err, ErrNa) {
-				return nil, err
-			}
-			continue
+		ms, e := check(n)
+		if e != nil && !errors.Is(e, ErrNa) {
+			err = e
+			return false
 		}
 		list = append(list, ms...)
-	}
-	ms, err := check(with.Statement)
-	if err == nil {
-		list = append(list, ms...)
-	}
+		return true
+	})
 	return list, err
 }
 
-func handleSelectStatement(stmt ast.SelectStatement, check RuleFunc) ([]rules.LintMessage, error) {
-	var list []rules.LintMessage
-	for _, c := range stmt.Columns {
-		msg, err := check(c)
-		if err != nil && !errors.Is(err, ErrNa) {
-			return nil, err
-		}
-		list = slices.Concat(list, msg)
-	}
-	for _, c := range stmt.Tables {
-		msg, err := check(c)
-		if err != nil && !errors.Is(err, ErrNa) {
-			return nil, err
-		}
-		list = slices.Concat(list, msg)
-	}
-	if stmt.Where != nil {
-		others, err := handleExpr(stmt.Where, check)
-		if err != nil && !errors.Is(err, ErrNa) {
-			return nil, err
-		}
-		list = slices.Concat(list, others)
-	}
-	return list, nil
-}
-
 func makeArray[T rules.LintMessage](el T) []T {
 	return []T{el}
 }
+
+// locate sets the location of the message to the one of the given node when
+// the message has not been located yet
+func locate(stmt ast.Statement, msg rules.LintMessage) rules.LintMessage {
+	if msg.Position.Line > 0 {
+		return msg
+	}
+	span := ast.SpanOf(stmt)
+	msg.File = span.File
+	msg.Position = span.Start
+	return msg
+}
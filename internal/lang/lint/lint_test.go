@@ -0,0 +1,823 @@
+package lint_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/midbel/sweet/internal/config"
+	"github.com/midbel/sweet/internal/lang/lint"
+	_ "github.com/midbel/sweet/internal/ora"
+	"github.com/midbel/sweet/internal/rules"
+)
+
+func TestRules(t *testing.T) {
+	tests := []struct {
+		Rule    string
+		Query   string
+		Dialect string
+		Want    int
+	}{
+		{
+			Rule:  "expr.aggregate",
+			Query: "select e.dept, e.name, count(*) from employees e group by e.dept;",
+			Want:  1,
+		},
+		{
+			Rule:  "expr.aggregate",
+			Query: "select e.dept d, upper(e.name), max(e.salary) from employees e group by d, 2;",
+			Want:  0,
+		},
+		{
+			Rule:  "expr.aggregate",
+			Query: "select e.name, count(*) from employees e;",
+			Want:  1,
+		},
+		{
+			Rule:  "expr.aggregate",
+			Query: "select e.dept from employees e group by e.dept having count(*) > 1 and e.name = 'x';",
+			Want:  1,
+		},
+		{
+			Rule:  "expr.invalid",
+			Query: "select e.dept from employees e where count(*) > 1 group by e.dept;",
+			Want:  1,
+		},
+		{
+			Rule:  "expr.invalid",
+			Query: "select sum(max(e.salary)) from employees e;",
+			Want:  1,
+		},
+		{
+			Rule:  "rewrite.expr",
+			Query: "select e.name from employees e where e.manager = null;",
+			Want:  1,
+		},
+		{
+			Rule:  "rewrite.expr.not",
+			Query: "select e.name from employees e where not (e.id = 1) and not (e.dept in ('it')) and e.name not in ('x');",
+			Want:  2,
+		},
+		{
+			Rule:  "cte.columns.unused",
+			Query: "with x(id, name, dept) as (select e.id, e.name, e.dept from employees e) select x.id, x.dept from x;",
+			Want:  1,
+		},
+		{
+			Rule:  "cte.columns.unused",
+			Query: "with x as (select e.id, e.name from employees e) select * from x;",
+			Want:  0,
+		},
+		{
+			Rule:  "cte.unused",
+			Query: "with x as (select e.id from employees e) select x.id from x;",
+			Want:  0,
+		},
+		{
+			Rule:  "cte.unused",
+			Query: "with x as (select e.id from employees e) select d.id from departments d;",
+			Want:  1,
+		},
+		{
+			Rule:  "cte.columns.mismatched",
+			Query: "with x as (select e.id, e.name from employees e) select x.id, x.name from x;",
+			Want:  0,
+		},
+		{
+			Rule:  "cte.columns.mismatched",
+			Query: "with x(id) as (select e.id, e.name from employees e) select x.id from x;",
+			Want:  1,
+		},
+		{
+			Rule:  "inconsistent.use.order",
+			Query: "select e.name from employees e order by e.name asc, e.id;",
+			Want:  1,
+		},
+		{
+			Rule:  "inconsistent.use.order",
+			Query: "select e.name from employees e order by e.name, e.id desc;",
+			Want:  0,
+		},
+		{
+			Rule:  "safety.where.missing",
+			Query: "delete from employees;",
+			Want:  1,
+		},
+		{
+			Rule:  "safety.where.missing",
+			Query: "update employees set salary = 0 where id = 1;",
+			Want:  0,
+		},
+		{
+			Rule:  "safety.where.always",
+			Query: "update employees set salary = 0 where 1 = 1;",
+			Want:  1,
+		},
+		{
+			Rule:  "safety.where.always",
+			Query: "delete from employees where id = 1 or (id = id);",
+			Want:  1,
+		},
+		{
+			Rule:  "safety.destructive",
+			Query: "truncate employees;",
+			Want:  1,
+		},
+		{
+			Rule:  "safety.destructive",
+			Query: "-- sweet:migration\ndrop table employees;",
+			Want:  0,
+		},
+		{
+			Rule:  "safety.join.cartesian",
+			Query: "select e.name, d.name from employees e, departments d;",
+			Want:  1,
+		},
+		{
+			Rule:  "safety.join.cartesian",
+			Query: "select e.name, d.name from employees e, departments d where e.dept = d.id;",
+			Want:  0,
+		},
+		{
+			Rule:  "safety.merge.matched",
+			Query: "merge into employees e using staging s on e.id = s.id when matched then delete;",
+			Want:  1,
+		},
+		{
+			Rule:  "null.compare",
+			Query: "select e.name from employees e where e.manager <> null;",
+			Want:  1,
+		},
+		{
+			Rule:  "null.not.in",
+			Query: "select e.name from employees e where e.dept not in (select d.id from departments d);",
+			Want:  1,
+		},
+		{
+			Rule:  "null.not.in",
+			Query: "select e.name from employees e where e.dept not in (select d.id from departments d where d.id is not null);",
+			Want:  0,
+		},
+		{
+			Rule:  "null.not.in",
+			Query: "select e.name from employees e where e.dept not in (1, null);",
+			Want:  1,
+		},
+		{
+			Rule:  "null.count",
+			Query: "select d.name, count(*) from departments d left join employees e on e.dept = d.id group by d.name;",
+			Want:  1,
+		},
+		{
+			Rule:  "null.count",
+			Query: "select d.name, count(e.id) from departments d left join employees e on e.dept = d.id group by d.name;",
+			Want:  0,
+		},
+		{
+			Rule:  "null.join.filter",
+			Query: "select d.name from departments d left join employees e on e.dept = d.id where e.salary > 10 and d.id = 1;",
+			Want:  1,
+		},
+		{
+			Rule:  "null.join.filter",
+			Query: "select d.name from departments d left join employees e on e.dept = d.id where e.id is null or e.salary > 10;",
+			Want:  0,
+		},
+		{
+			Rule:  "perf.predicate.function",
+			Query: "select u.id from users u join logs l on date(l.created) = u.day where lower(u.email) = 'x';",
+			Want:  2,
+		},
+		{
+			Rule:  "perf.predicate.function",
+			Query: "select u.id from users u where u.email = lower('X');",
+			Want:  0,
+		},
+		{
+			Rule:  "perf.predicate.wildcard",
+			Query: "select u.id from users u where u.email like '%@example.com' or u.name like 'x%';",
+			Want:  1,
+		},
+		{
+			Rule:  "perf.select.star",
+			Query: "insert into archive select * from users;",
+			Want:  1,
+		},
+		{
+			Rule:  "perf.select.star",
+			Query: "select * from users u where exists (select * from logs l where l.user = u.id);",
+			Want:  0,
+		},
+		{
+			Rule:  "perf.predicate.or",
+			Query: "select u.id from users u where u.email = 'x' or u.name = 'y';",
+			Want:  1,
+		},
+		{
+			Rule:  "perf.predicate.or",
+			Query: "select u.id from users u where u.email = 'x' or u.email = 'y';",
+			Want:  0,
+		},
+		{
+			Rule:  "perf.subquery.order",
+			Query: "with x as (select u.id from users u order by u.id) select x.id from x;",
+			Want:  1,
+		},
+		{
+			Rule:  "perf.distinct.group",
+			Query: "select distinct u.dept from users u group by u.dept;",
+			Want:  1,
+		},
+		{
+			Rule:  "perf.union.all",
+			Query: "select 'a', u.id from users u union select 'b', g.id from guests g;",
+			Want:  1,
+		},
+		{
+			Rule:  "perf.union.all",
+			Query: "select u.id from users u union select g.id from guests g;",
+			Want:  0,
+		},
+		{
+			Rule:  "perf.subquery.correlated",
+			Query: "select u.id, (select count(l.id) from logs l where l.user = u.id) from users u;",
+			Want:  1,
+		},
+		{
+			Rule:  "naming.table",
+			Query: "create table UserAccount (id int);",
+			Want:  1,
+		},
+		{
+			Rule:  "naming.table.form",
+			Query: "create table users (id int);",
+			Want:  1,
+		},
+		{
+			Rule:  "naming.column",
+			Query: "create table account (id int, FirstName varchar(10), last_name varchar(10));",
+			Want:  1,
+		},
+		{
+			Rule:  "naming.constraint",
+			Query: "create table account (id int, constraint account_pk primary key (id));",
+			Want:  1,
+		},
+		{
+			Rule:  "naming.index",
+			Query: "create index account_email on account (email);",
+			Want:  1,
+		},
+		{
+			Rule:  "naming.cte",
+			Query: "with LastUsers as (select u.id from users u) select l.id from LastUsers l;",
+			Want:  1,
+		},
+		{
+			Rule:  "naming.alias.short",
+			Query: "select a.id from users a join roles b on a.role = b.id join teams t on t.id = a.team join logs l on l.author = a.id;",
+			Want:  4,
+		},
+		{
+			Rule:  "naming.alias.short",
+			Query: "select a.id from users a join roles b on a.role = b.id;",
+			Want:  0,
+		},
+		{
+			Rule:  "naming.keyword",
+			Query: "select u.id \"select\" from users u;",
+			Want:  1,
+		},
+		{
+			Rule:  "migration.column.notnull",
+			Query: "alter table employees add column badge int not null;",
+			Want:  1,
+		},
+		{
+			Rule:  "migration.column.notnull",
+			Query: "alter table employees add column badge int not null default 0;",
+			Want:  0,
+		},
+		{
+			Rule:  "migration.column.notnull",
+			Query: "alter table employees alter column badge set not null;",
+			Want:  1,
+		},
+		{
+			Rule:  "migration.column.type",
+			Query: "alter table employees alter column name set data type text;",
+			Want:  1,
+		},
+		{
+			Rule:  "migration.drop",
+			Query: "alter table employees drop column badge;",
+			Want:  1,
+		},
+		{
+			Rule:  "migration.rename",
+			Query: "alter table employees rename column name to fullname;",
+			Want:  1,
+		},
+		{
+			Rule:    "ident.quote.sensitive",
+			Query:   "select \"order_id\" from orders;",
+			Dialect: "ansi",
+			Want:    1,
+		},
+		{
+			Rule:    "ident.quote.sensitive",
+			Query:   "select \"ORDER_ID\" from orders;",
+			Dialect: "ansi",
+			Want:    0,
+		},
+		{
+			Rule:    "ident.quote.sensitive",
+			Query:   "select \"order\" from orders;",
+			Dialect: "ansi",
+			Want:    1,
+		},
+		{
+			Rule:    "ident.quote.sensitive",
+			Query:   "select \"order_id\" from orders;",
+			Dialect: "postgres",
+			Want:    0,
+		},
+		{
+			Rule:    "ident.quote.sensitive",
+			Query:   "select \"Order_Id\" from orders;",
+			Dialect: "postgres",
+			Want:    1,
+		},
+		{
+			Rule:    "ident.quote.sensitive",
+			Query:   "select \"order_id\" from orders;",
+			Dialect: "oracle",
+			Want:    1,
+		},
+		{
+			Rule:    "ident.quote.sensitive",
+			Query:   "select \"ORDER_ID\" from orders;",
+			Dialect: "oracle",
+			Want:    0,
+		},
+		{
+			Rule:    "ident.quote.sensitive",
+			Query:   "select \"Order_Id\" from orders;",
+			Dialect: "sqlite",
+			Want:    0,
+		},
+		{
+			Rule:    "migration.index.concurrently",
+			Query:   "create index ix_employees_name on employees (name);",
+			Dialect: "postgres",
+			Want:    1,
+		},
+		{
+			Rule:    "migration.index.concurrently",
+			Query:   "create index concurrently ix_employees_name on employees (name);",
+			Dialect: "postgres",
+			Want:    0,
+		},
+		{
+			Rule:  "migration.index.concurrently",
+			Query: "create index ix_employees_name on employees (name);",
+			Want:  0,
+		},
+		{
+			Rule:  "migration.exists",
+			Query: "create table if not exists teams (id int); drop table teams;",
+			Want:  1,
+		},
+		{
+			Rule:  "migration.table.primary",
+			Query: "create table teams (id int, name varchar(10));",
+			Want:  1,
+		},
+		{
+			Rule:  "migration.foreign.index",
+			Query: "create table teams (id int primary key, owner int references employees (id));",
+			Want:  1,
+		},
+		{
+			Rule:  "migration.foreign.index",
+			Query: "create table teams (id int primary key, owner int references employees (id)); create index ix_teams_owner on teams (owner);",
+			Want:  0,
+		},
+		{
+			Rule:  "security.grant.all",
+			Query: "grant all privileges on employees to hr;",
+			Want:  1,
+		},
+		{
+			Rule:  "security.grant.public",
+			Query: "grant select on employees to hr, public;",
+			Want:  1,
+		},
+		{
+			Rule:  "security.grant.option",
+			Query: "grant select on employees to hr with grant option;",
+			Want:  1,
+		},
+		{
+			Rule:  "security.revoke.unmatched",
+			Query: "grant select, update on employees to hr; revoke update on employees from hr; revoke select on departments from hr;",
+			Want:  1,
+		},
+		{
+			Rule:  "security.procedure.definer",
+			Query: "create procedure raise(in id int) language plpgsql security definer begin update employees set salary = salary * 2 where id = id; end;",
+			Want:  1,
+		},
+		{
+			Rule:  "security.execute.dynamic",
+			Query: "create procedure purge(in tbl varchar(10)) begin declare q varchar(100) default 'delete from ' || tbl; execute q; execute 'truncate employees'; end;",
+			Want:  1,
+		},
+		{
+			Rule:  "security.execute.dynamic",
+			Query: "create procedure purge(in n int) begin declare total int default n + 1; execute total; execute 'truncate employees'; end;",
+			Want:  0,
+		},
+		{
+			Rule:  "security.execute.dynamic",
+			Query: "create procedure purge(in tbl varchar(10)) begin execute 'delete from ' + tbl; end;",
+			Want:  1,
+		},
+		{
+			Rule:  "security.execute.dynamic",
+			Query: "execute 'delete from ' || tbl;",
+			Want:  1,
+		},
+		{
+			Rule:    "security.execute.dynamic",
+			Dialect: "oracle",
+			Query:   "DECLARE w VARCHAR2(100) := 'employees'; BEGIN EXECUTE IMMEDIATE 'DELETE FROM ' || w; END;",
+			Want:    1,
+		},
+		{
+			Rule:    "security.execute.dynamic",
+			Dialect: "oracle",
+			Query:   "DECLARE q VARCHAR2(100) := 'SELECT 1 FROM dual'; BEGIN DECLARE q VARCHAR2(100) := 'DELETE FROM ' || w; BEGIN NULL; END; EXECUTE IMMEDIATE q; END;",
+			Want:    0,
+		},
+		{
+			Rule:  "security.credentials",
+			Query: "select u.id from users u where u.name = 'admin' and u.password = 'admin';",
+			Want:  1,
+		},
+		{
+			Rule:  "procedure.variable.unused",
+			Query: "create procedure raise(in pct int) begin declare total int; declare unused int default 0; set total = pct; return total; end;",
+			Want:  1,
+		},
+		{
+			Rule:  "procedure.variable.unassigned",
+			Query: "create procedure raise(in pct int) begin declare total int; set total = total + pct; return total; end;",
+			Want:  1,
+		},
+		{
+			Rule:  "procedure.unreachable",
+			Query: "create procedure raise(in pct int) begin return pct; set pct = 0; end;",
+			Want:  1,
+		},
+		{
+			Rule:  "procedure.while.unchanged",
+			Query: "create procedure raise(in pct int) begin declare i int default 0; while i < pct do update employees set salary = salary + 1; end while; end;",
+			Want:  1,
+		},
+		{
+			Rule:  "procedure.while.unchanged",
+			Query: "create procedure raise(in pct int) begin declare i int default 0; while i < pct do set i = i + 1; end while; end;",
+			Want:  0,
+		},
+		{
+			Rule:  "procedure.if.empty",
+			Query: "create procedure raise(in pct int) begin if pct > 0 then else return 0; end if; end;",
+			Want:  1,
+		},
+		{
+			Rule:  "procedure.parameter.shadow",
+			Query: "create procedure raise(in pct int) begin declare pct int default 0; return pct; end;",
+			Want:  1,
+		},
+		{
+			Rule:  "procedure.parameter.out",
+			Query: "create procedure raise(in pct int, out total int, out status int) begin set total = pct; end;",
+			Want:  1,
+		},
+		{
+			Rule:  "procedure.transaction",
+			Query: "create procedure purge() begin delete from logs; commit; end; start transaction; call purge(); commit; call purge();",
+			Want:  1,
+		},
+	}
+	for _, c := range tests {
+		linter := lint.NewLinter()
+		if c.Dialect != "" {
+			if err := linter.SetDialect(c.Dialect); err != nil {
+				t.Errorf("%s: fail to set dialect: %s", c.Rule, err)
+				continue
+			}
+		}
+		set := config.Make()
+		set.Set(c.Rule, true)
+		cfg := config.Make()
+		cfg.Set("lint", set)
+		if err := linter.Configure(cfg); err != nil {
+			t.Errorf("%s: fail to configure linter: %s", c.Rule, err)
+			continue
+		}
+		list, err := linter.Lint(strings.NewReader(c.Query))
+		if err != nil {
+			t.Errorf("%s: fail to lint %q: %s", c.Rule, c.Query, err)
+			continue
+		}
+		var got int
+		for _, m := range list {
+			if m.Rule == c.Rule {
+				got++
+			}
+		}
+		if got != c.Want {
+			t.Errorf("%s: messages count mismatched for %q! want %d, got %d", c.Rule, c.Query, c.Want, got)
+		}
+	}
+}
+
+func TestSuppressions(t *testing.T) {
+	tests := []struct {
+		Dialect string
+		Query   string
+		Want    int
+	}{
+		{
+			Query: "-- sweet:disable-next-line safety.where.missing\ndelete from employees;\ndelete from departments;",
+			Want:  1,
+		},
+		{
+			Query: "-- sweet:disable-next-line alias.missing\ndelete from employees;",
+			Want:  1,
+		},
+		{
+			Query: "-- sweet:disable safety.*\ndelete from employees;\ndelete from departments;\n-- sweet:enable\ndelete from teams;",
+			Want:  1,
+		},
+		{
+			Query: "-- sweet:disable safety\ndelete from employees;\n-- sweet:enable null\ndelete from teams;",
+			Want:  0,
+		},
+		{
+			Query: "-- sweet:disable-file\ndelete from employees;\n\ndelete from teams;",
+			Want:  0,
+		},
+		{
+			Query: "delete from employees;\n-- sweet:disable-file\ndelete from teams;",
+			Want:  0,
+		},
+		{
+			Dialect: "postgres",
+			Query:   "copy employees from stdin;\n-- sweet:disable-file\n\\.\ndelete from employees;",
+			Want:    1,
+		},
+	}
+	for _, c := range tests {
+		linter := lint.NewLinter()
+		if err := linter.SetDialect(c.Dialect); err != nil {
+			t.Errorf("fail to set dialect: %s", err)
+			continue
+		}
+		set := config.Make()
+		set.Set("safety.where.missing", true)
+		cfg := config.Make()
+		cfg.Set("lint", set)
+		if err := linter.Configure(cfg); err != nil {
+			t.Errorf("fail to configure linter: %s", err)
+			continue
+		}
+		list, err := linter.Lint(strings.NewReader(c.Query))
+		if err != nil {
+			t.Errorf("fail to lint %q: %s", c.Query, err)
+			continue
+		}
+		if len(list) != c.Want {
+			t.Errorf("messages count mismatched for %q! want %d, got %d", c.Query, c.Want, len(list))
+		}
+	}
+}
+
+func TestMinLevel(t *testing.T) {
+	tests := []struct {
+		Level rules.Level
+		Rule  string
+		Want  int
+	}{
+		{
+			Level: rules.Info,
+			Rule:  "warning",
+			Want:  1,
+		},
+		{
+			Level: rules.Warning,
+			Rule:  "warning",
+			Want:  1,
+		},
+		{
+			Level: rules.Error,
+			Rule:  "warning",
+			Want:  0,
+		},
+		{
+			Level: rules.Error,
+			Rule:  "error",
+			Want:  1,
+		},
+	}
+	for _, c := range tests {
+		linter := lint.NewLinter()
+		linter.MinLevel = c.Level
+
+		rule := config.Make()
+		rule.Set("level", c.Rule)
+		set := config.Make()
+		set.Set("safety.where.missing", rule)
+		cfg := config.Make()
+		cfg.Set("lint", set)
+		if err := linter.Configure(cfg); err != nil {
+			t.Errorf("fail to configure linter: %s", err)
+			continue
+		}
+		list, err := linter.Lint(strings.NewReader("delete from employees;"))
+		if err != nil {
+			t.Errorf("fail to lint: %s", err)
+			continue
+		}
+		if len(list) != c.Want {
+			t.Errorf("messages count mismatched for %s (%s)! want %d, got %d", c.Rule, c.Level, c.Want, len(list))
+		}
+	}
+}
+
+func TestDialectRules(t *testing.T) {
+	tests := []struct {
+		Dialect string
+		Want    int
+	}{
+		{
+			Dialect: "postgres",
+			Want:    1,
+		},
+		{
+			Dialect: "ansi",
+			Want:    0,
+		},
+		{
+			Dialect: "sqlite",
+			Want:    0,
+		},
+	}
+	for _, c := range tests {
+		linter := lint.NewLinter()
+		if err := linter.SetDialect(c.Dialect); err != nil {
+			t.Errorf("%s: fail to set dialect: %s", c.Dialect, err)
+			continue
+		}
+		list, err := linter.Lint(strings.NewReader("create index idx_users_name on users (name);"))
+		if err != nil {
+			t.Errorf("%s: fail to lint: %s", c.Dialect, err)
+			continue
+		}
+		var got int
+		for _, m := range list {
+			if m.Rule == "migration.index.concurrently" {
+				got++
+			}
+		}
+		if got != c.Want {
+			t.Errorf("%s: messages count mismatched! want %d, got %d", c.Dialect, c.Want, got)
+		}
+	}
+}
+
+func TestLinterClone(t *testing.T) {
+	queries := []string{
+		"delete from employees;",
+		"@lint 'safety.where.missing' on;\ndelete from employees;",
+		"@lint 'safety.where.missing' off;\ndelete from employees;",
+	}
+	var (
+		linter = lint.NewLinter()
+		want   = make([]int, len(queries))
+	)
+	for i, q := range queries {
+		list, err := linter.Clone().Lint(strings.NewReader(q))
+		if err != nil {
+			t.Fatalf("fail to lint %q: %s", q, err)
+		}
+		want[i] = len(list)
+	}
+	if want[1] == want[2] {
+		t.Fatalf("lint macros not applied: %v", want)
+	}
+
+	var (
+		got = make([]int, len(queries)*8)
+		wg  sync.WaitGroup
+	)
+	for i := range got {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			list, err := linter.Clone().Lint(strings.NewReader(queries[i%len(queries)]))
+			if err != nil {
+				t.Errorf("fail to lint: %s", err)
+			}
+			got[i] = len(list)
+		}(i)
+	}
+	wg.Wait()
+	for i := range got {
+		if w := want[i%len(queries)]; got[i] != w {
+			t.Errorf("%q: messages count mismatched! want %d, got %d", queries[i%len(queries)], w, got[i])
+		}
+	}
+	list, err := linter.Lint(strings.NewReader(queries[0]))
+	if err != nil {
+		t.Fatalf("fail to lint: %s", err)
+	}
+	if len(list) != want[0] {
+		t.Errorf("macros of the inputs changed the cloned linter")
+	}
+}
+
+func TestDialectConfigured(t *testing.T) {
+	rule := config.Make()
+	rule.Set("form", "plural")
+	rule.Set("level", "error")
+	set := config.Make()
+	set.Set("naming.table.form", rule)
+	cfg := config.Make()
+	cfg.Set("lint", set)
+
+	for _, d := range []string{"ansi", "postgres"} {
+		linter := lint.NewLinter()
+		if err := linter.Configure(cfg); err != nil {
+			t.Fatalf("fail to configure linter: %s", err)
+		}
+		if err := linter.SetDialect(d); err != nil {
+			t.Fatalf("%s: fail to set dialect: %s", d, err)
+		}
+		list, err := linter.Lint(strings.NewReader("create table user (id int);"))
+		if err != nil {
+			t.Fatalf("%s: fail to lint: %s", d, err)
+		}
+		var got []rules.LintMessage
+		for _, m := range list {
+			if m.Rule == "naming.table.form" {
+				got = append(got, m)
+			}
+		}
+		if len(got) != 1 {
+			t.Errorf("%s: messages count mismatched! want 1, got %d", d, len(got))
+			continue
+		}
+		if got[0].Severity != rules.Error {
+			t.Errorf("%s: level mismatched! want %s, got %s", d, rules.Error, got[0].Severity)
+		}
+	}
+}
+
+func TestOraclePositions(t *testing.T) {
+	query := `CREATE OR REPLACE PROCEDURE raise_salary(p_pct IN NUMBER) IS
+	v_unused NUMBER := 0;
+	v_total NUMBER;
+BEGIN
+	v_total := v_total + p_pct;
+	NULL;
+END;
+/`
+	linter := lint.NewLinter()
+	if err := linter.SetDialect("oracle"); err != nil {
+		t.Fatalf("fail to set dialect: %s", err)
+	}
+	list, err := linter.Lint(strings.NewReader(query))
+	if err != nil {
+		t.Fatalf("fail to lint: %s", err)
+	}
+	want := map[string]string{
+		"procedure.variable.unused":     "2:2",
+		"procedure.variable.unassigned": "5:13",
+	}
+	for _, m := range list {
+		pos, ok := want[m.Rule]
+		if !ok {
+			continue
+		}
+		delete(want, m.Rule)
+		if got := m.Position.String(); got != pos {
+			t.Errorf("%s: position mismatched! want %s, got %s", m.Rule, pos, got)
+		}
+	}
+	for r := range want {
+		t.Errorf("%s: message expected", r)
+	}
+}
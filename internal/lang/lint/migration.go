@@ -0,0 +1,331 @@
+package lint
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/midbel/sweet/internal/config"
+	"github.com/midbel/sweet/internal/lang"
+	"github.com/midbel/sweet/internal/lang/ast"
+	"github.com/midbel/sweet/internal/rules"
+)
+
+func checkNotNullColumn(stmt ast.Statement) ([]rules.LintMessage, error) {
+	return inspect(stmt, notNullColumn)
+}
+
+func notNullColumn(stmt ast.AlterTableStatement) ([]rules.LintMessage, error) {
+	var def ast.ColumnDef
+	switch a := stmt.Action.(type) {
+	case ast.AddColumnAction:
+		def, _ = a.Def.(ast.ColumnDef)
+	case ast.AlterColumnAction:
+		if a.NotNull {
+			return makeArray(notNullWithoutDefault(a, a.Name)), nil
+		}
+		return nil, nil
+	default:
+		return nil, nil
+	}
+	var notnull, defaulted bool
+	for _, c := range def.Constraints {
+		switch unwrapConstraint(c).(type) {
+		case ast.NotNullConstraint, ast.PrimaryKeyConstraint:
+			notnull = true
+		case ast.DefaultConstraint, ast.GeneratedConstraint:
+			defaulted = true
+		}
+	}
+	if notnull && !defaulted {
+		return makeArray(notNullWithoutDefault(def, def.Name)), nil
+	}
+	return nil, nil
+}
+
+func checkDropObject(stmt ast.Statement) ([]rules.LintMessage, error) {
+	var list []rules.LintMessage
+	switch stmt := unwrapNode(stmt).(type) {
+	case ast.DropTableStatement:
+		for _, n := range stmt.Names {
+			list = append(list, breakingChange(stmt, "drop table", getTableQualifier(n), ruleMigrationDrop))
+		}
+	case ast.AlterTableStatement:
+		if a, ok := stmt.Action.(ast.DropColumnAction); ok {
+			list = append(list, breakingChange(a, "drop column", a.Name, ruleMigrationDrop))
+		}
+	}
+	return list, nil
+}
+
+func checkRenameObject(stmt ast.Statement) ([]rules.LintMessage, error) {
+	var list []rules.LintMessage
+	if stmt, ok := unwrapNode(stmt).(ast.AlterTableStatement); ok {
+		switch a := stmt.Action.(type) {
+		case ast.RenameTableAction:
+			list = append(list, breakingChange(a, "rename table", getTableQualifier(stmt.Name), ruleMigrationRename))
+		case ast.RenameColumnAction:
+			list = append(list, breakingChange(a, "rename column", a.Old, ruleMigrationRename))
+		}
+	}
+	return list, nil
+}
+
+func checkColumnType(stmt ast.Statement) ([]rules.LintMessage, error) {
+	return inspect(stmt, func(stmt ast.AlterColumnAction) ([]rules.LintMessage, error) {
+		if stmt.Type.Name == "" {
+			return nil, nil
+		}
+		return makeArray(columnTypeChanged(stmt)), nil
+	})
+}
+
+// buildConcurrentIndexRule builds the rule checking that indexes are created
+// without locking their table. Only PostgreSQL supports CONCURRENTLY.
+func buildConcurrentIndexRule(_ *config.Config, d lang.Dialect) (RuleFunc, error) {
+	fn := func(stmt ast.Statement) ([]rules.LintMessage, error) {
+		if d.Name != "postgres" {
+			return nil, nil
+		}
+		return inspect(stmt, func(stmt ast.CreateIndexStatement) ([]rules.LintMessage, error) {
+			if stmt.Concurrently {
+				return nil, nil
+			}
+			return makeArray(indexNotConcurrent(stmt)), nil
+		})
+	}
+	return fn, nil
+}
+
+func checkIfExists(stmt ast.Statement) ([]rules.LintMessage, error) {
+	var (
+		list []rules.LintMessage
+		add  = func(stmt ast.Statement, ok bool, kw string) {
+			if !ok {
+				list = append(list, missingIfExists(stmt, kw))
+			}
+		}
+	)
+	switch stmt := unwrapNode(stmt).(type) {
+	case ast.CreateTableStatement:
+		add(stmt, stmt.NotExists, "create table")
+	case ast.CreateViewStatement:
+		add(stmt, stmt.NotExists, "create view")
+	case ast.CreateIndexStatement:
+		add(stmt, stmt.NotExists, "create index")
+	case ast.DropTableStatement:
+		add(stmt, stmt.Exists, "drop table")
+	case ast.DropViewStatement:
+		add(stmt, stmt.Exists, "drop view")
+	case ast.AlterTableStatement:
+		switch a := stmt.Action.(type) {
+		case ast.AddColumnAction:
+			add(a, a.NotExists, "add column")
+		case ast.DropColumnAction:
+			add(a, a.Exists, "drop column")
+		case ast.DropConstraintAction:
+			add(a, a.Exists, "drop constraint")
+		}
+	}
+	return list, nil
+}
+
+func checkPrimaryKey(stmt ast.Statement) ([]rules.LintMessage, error) {
+	q, ok := unwrapNode(stmt).(ast.CreateTableStatement)
+	if !ok || len(getPrimaryKey(q)) > 0 {
+		return nil, nil
+	}
+	return makeArray(missingPrimaryKey(q)), nil
+}
+
+// foreignKey is a foreign key of a table with the statement defining it
+type foreignKey struct {
+	stmt    ast.Statement
+	table   string
+	columns []string
+}
+
+// checkForeignIndex checks that the columns of the foreign keys are the first
+// columns of an index of their table: the primary key, a unique constraint or
+// an index created by the input
+func checkForeignIndex(stmt ast.Statement) ([]rules.LintMessage, error) {
+	s, ok := stmt.(script)
+	if !ok {
+		return nil, nil
+	}
+	var (
+		keys    []foreignKey
+		indexes = make(map[string][][]string)
+	)
+	for _, stmt := range s.statements {
+		switch stmt := unwrapNode(stmt).(type) {
+		case ast.CreateTableStatement:
+			table := getTableQualifier(stmt.Name)
+			if pk := getPrimaryKey(stmt); len(pk) > 0 {
+				indexes[table] = append(indexes[table], pk)
+			}
+			for _, c := range stmt.Columns {
+				def, ok := c.(ast.ColumnDef)
+				if !ok {
+					continue
+				}
+				for _, c := range def.Constraints {
+					switch unwrapConstraint(c).(type) {
+					case ast.UniqueConstraint:
+						indexes[table] = append(indexes[table], []string{def.Name})
+					case ast.ForeignKeyConstraint:
+						keys = append(keys, foreignKey{stmt: def, table: table, columns: []string{def.Name}})
+					}
+				}
+			}
+			for _, c := range stmt.Constraints {
+				switch x := unwrapConstraint(c).(type) {
+				case ast.UniqueConstraint:
+					indexes[table] = append(indexes[table], x.Columns)
+				case ast.ForeignKeyConstraint:
+					keys = append(keys, foreignKey{stmt: stmt, table: table, columns: x.Locals})
+				}
+			}
+		case ast.AlterTableStatement:
+			a, ok := stmt.Action.(ast.AddConstraintAction)
+			if !ok {
+				break
+			}
+			table := getTableQualifier(stmt.Name)
+			switch x := unwrapConstraint(a.Constraint).(type) {
+			case ast.PrimaryKeyConstraint:
+				indexes[table] = append(indexes[table], x.Columns)
+			case ast.UniqueConstraint:
+				indexes[table] = append(indexes[table], x.Columns)
+			case ast.ForeignKeyConstraint:
+				keys = append(keys, foreignKey{stmt: stmt, table: table, columns: x.Locals})
+			}
+		case ast.CreateIndexStatement:
+			table := getTableQualifier(stmt.Table)
+			indexes[table] = append(indexes[table], stmt.Columns)
+		}
+	}
+	var list []rules.LintMessage
+	for _, k := range keys {
+		ok := slices.ContainsFunc(indexes[k.table], func(cols []string) bool {
+			return isIndexPrefix(cols, k.columns)
+		})
+		if !ok {
+			list = append(list, foreignKeyWithoutIndex(k))
+		}
+	}
+	return list, nil
+}
+
+// isIndexPrefix reports whether the given columns are the first columns of an
+// index whatever their order
+func isIndexPrefix(index, columns []string) bool {
+	if len(columns) == 0 || len(columns) > len(index) {
+		return false
+	}
+	for _, c := range index[:len(columns)] {
+		ok := slices.ContainsFunc(columns, func(other string) bool {
+			return strings.EqualFold(c, other)
+		})
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// getPrimaryKey gives the columns of the primary key of a table defined either
+// on a column or as a constraint of the table
+func getPrimaryKey(stmt ast.CreateTableStatement) []string {
+	for _, c := range stmt.Constraints {
+		if pk, ok := unwrapConstraint(c).(ast.PrimaryKeyConstraint); ok {
+			return pk.Columns
+		}
+	}
+	for _, c := range stmt.Columns {
+		def, ok := c.(ast.ColumnDef)
+		if !ok {
+			continue
+		}
+		for _, c := range def.Constraints {
+			if _, ok := unwrapConstraint(c).(ast.PrimaryKeyConstraint); ok {
+				return []string{def.Name}
+			}
+		}
+	}
+	return nil
+}
+
+func unwrapConstraint(stmt ast.Statement) ast.Statement {
+	if c, ok := stmt.(ast.Constraint); ok {
+		return c.Statement
+	}
+	return stmt
+}
+
+func notNullWithoutDefault(stmt ast.Statement, column string) rules.LintMessage {
+	msg := rules.LintMessage{
+		Severity: rules.Error,
+		Message:  fmt.Sprintf("%s: not null column without default fails on existing rows", column),
+		Rule:     ruleMigrationNotNull,
+	}
+	return locate(stmt, msg)
+}
+
+func breakingChange(stmt ast.Statement, kw, name, rule string) rules.LintMessage {
+	msg := rules.LintMessage{
+		Severity: rules.Error,
+		Message:  fmt.Sprintf("%s: %s breaks the running versions of the application", name, kw),
+		Rule:     rule,
+	}
+	return locate(stmt, msg)
+}
+
+func columnTypeChanged(stmt ast.AlterColumnAction) rules.LintMessage {
+	msg := rules.LintMessage{
+		Severity: rules.Warning,
+		Message:  fmt.Sprintf("%s: changing the type of a column can rewrite the table", stmt.Name),
+		Rule:     ruleMigrationType,
+	}
+	return locate(stmt, msg)
+}
+
+func indexNotConcurrent(stmt ast.CreateIndexStatement) rules.LintMessage {
+	msg := rules.LintMessage{
+		Severity: rules.Warning,
+		Message:  fmt.Sprintf("%s: create index without concurrently locks the table", stmt.Name),
+		Rule:     ruleMigrationConcurrent,
+	}
+	return locate(stmt, msg)
+}
+
+func missingIfExists(stmt ast.Statement, kw string) rules.LintMessage {
+	cond := "if exists"
+	if strings.HasPrefix(kw, "create") || strings.HasPrefix(kw, "add") {
+		cond = "if not exists"
+	}
+	msg := rules.LintMessage{
+		Severity: rules.Warning,
+		Message:  fmt.Sprintf("%s without %s can not be run twice", kw, cond),
+		Rule:     ruleMigrationExists,
+	}
+	return locate(stmt, msg)
+}
+
+func missingPrimaryKey(stmt ast.CreateTableStatement) rules.LintMessage {
+	msg := rules.LintMessage{
+		Severity: rules.Warning,
+		Message:  fmt.Sprintf("%s: table without primary key", getTableQualifier(stmt.Name)),
+		Rule:     ruleMigrationPrimaryKey,
+	}
+	return locate(stmt, msg)
+}
+
+func foreignKeyWithoutIndex(key foreignKey) rules.LintMessage {
+	msg := rules.LintMessage{
+		Severity: rules.Warning,
+		Message:  fmt.Sprintf("%s(%s): foreign key without index", key.table, strings.Join(key.columns, ", ")),
+		Rule:     ruleMigrationForeignIndex,
+	}
+	return locate(key.stmt, msg)
+}
@@ -0,0 +1,383 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/midbel/sweet/internal/config"
+	"github.com/midbel/sweet/internal/lang"
+	"github.com/midbel/sweet/internal/lang/ast"
+	"github.com/midbel/sweet/internal/rules"
+)
+
+// RuleBuilder builds a rule from the options found in its configuration and
+// the dialect of the input
+type RuleBuilder func(*config.Config, lang.Dialect) (RuleFunc, error)
+
+// ruleBuilders are the rules accepting options in their configuration
+var ruleBuilders = map[string]RuleBuilder{
+	ruleNamingTable:      buildNamingRule(ruleNamingTable, getTableNames),
+	ruleNamingTableForm:  buildTableFormRule,
+	ruleNamingColumn:     buildNamingRule(ruleNamingColumn, getColumnNames),
+	ruleNamingConstraint: buildNamingRule(ruleNamingConstraint, getConstraintNames),
+	ruleNamingIndex:      buildNamingRule(ruleNamingIndex, getIndexNames),
+	ruleNamingCte:        buildNamingRule(ruleNamingCte, getCteNames),
+	ruleNamingAlias:      buildNamingRule(ruleNamingAlias, getAliasNames),
+	ruleNamingAliasShort: buildShortAliasRule,
+	ruleNamingKeyword:    buildKeywordRule,
+
+	ruleMigrationConcurrent: buildConcurrentIndexRule,
+
+	ruleIdentQuoteSensitive: buildQuotedIdentRule,
+}
+
+// defaultRule builds the rule with the given name without options
+func defaultRule(name string) RuleFunc {
+	d, _ := lang.GetDialect(lang.DefaultDialect)
+	fn, err := ruleBuilders[name](config.Make(), d)
+	if err != nil {
+		panic(err)
+	}
+	return fn
+}
+
+// namedObject is a name defined by a statement. Kind gives the kind of the
+// object (table, primary, foreign...) when it changes the convention to use.
+type namedObject struct {
+	stmt ast.Statement
+	name string
+	kind string
+}
+
+// namingStyle checks that names follow a case style, match a pattern and
+// start with the prefix of their kind
+type namingStyle struct {
+	style    string
+	pattern  *regexp.Regexp
+	prefixes map[string]string
+}
+
+var namingStyles = map[string]*regexp.Regexp{
+	"snake_case": regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`),
+	"camel":      regexp.MustCompile(`^[a-z][a-zA-Z0-9]*$`),
+	"pascal":     regexp.MustCompile(`^[A-Z][a-zA-Z0-9]*$`),
+}
+
+var defaultPrefixes = map[string]map[string]string{
+	ruleNamingConstraint: {
+		"primary": "pk_",
+		"foreign": "fk_",
+	},
+	ruleNamingIndex: {
+		"index": "ix_",
+	},
+}
+
+func makeNamingStyle(rule string, cfg *config.Config) (namingStyle, error) {
+	ns := namingStyle{
+		style:    cfg.GetDefaultString("style", "snake_case"),
+		prefixes: make(map[string]string),
+	}
+	switch ns.style {
+	case "snake_case", "camel", "pascal", "lower", "upper", "any":
+	default:
+		return ns, fmt.Errorf("%s: unknown naming style %q", rule, ns.style)
+	}
+	if str := cfg.GetString("pattern"); str != "" {
+		re, err := regexp.Compile("^(?:" + str + ")$")
+		if err != nil {
+			return ns, fmt.Errorf("%s: %w", rule, err)
+		}
+		ns.pattern = re
+	}
+	for k, v := range defaultPrefixes[rule] {
+		ns.prefixes[k] = cfg.GetDefaultString(k, v)
+	}
+	for _, k := range []string{"unique", "check"} {
+		if v := cfg.GetString(k); v != "" {
+			ns.prefixes[k] = v
+		}
+	}
+	return ns, nil
+}
+
+// Check gives the reason why the name does not follow the convention
+func (ns namingStyle) Check(obj namedObject) (string, bool) {
+	if p := ns.prefixes[obj.kind]; p != "" && !strings.HasPrefix(obj.name, p) {
+		return fmt.Sprintf("should start with %s", p), false
+	}
+	if ns.pattern != nil && !ns.pattern.MatchString(obj.name) {
+		return fmt.Sprintf("does not match %s", ns.pattern), false
+	}
+	var ok bool
+	switch ns.style {
+	case "lower":
+		ok = strings.ToLower(obj.name) == obj.name
+	case "upper":
+		ok = strings.ToUpper(obj.name) == obj.name
+	case "any":
+		ok = true
+	default:
+		ok = namingStyles[ns.style].MatchString(obj.name)
+	}
+	if !ok {
+		return fmt.Sprintf("is not %s", ns.style), false
+	}
+	return "", true
+}
+
+func buildNamingRule(rule string, names func(ast.Statement) []namedObject) RuleBuilder {
+	return func(cfg *config.Config, _ lang.Dialect) (RuleFunc, error) {
+		ns, err := makeNamingStyle(rule, cfg)
+		if err != nil {
+			return nil, err
+		}
+		fn := func(stmt ast.Statement) ([]rules.LintMessage, error) {
+			var list []rules.LintMessage
+			for _, obj := range names(stmt) {
+				if reason, ok := ns.Check(obj); !ok {
+					list = append(list, namingMismatch(obj, reason, rule))
+				}
+			}
+			return list, nil
+		}
+		return fn, nil
+	}
+}
+
+func buildTableFormRule(cfg *config.Config, _ lang.Dialect) (RuleFunc, error) {
+	form := cfg.GetDefaultString("form", "singular")
+	if form != "singular" && form != "plural" {
+		return nil, fmt.Errorf("%s: unknown form %q", ruleNamingTableForm, form)
+	}
+	fn := func(stmt ast.Statement) ([]rules.LintMessage, error) {
+		var list []rules.LintMessage
+		for _, obj := range getTableNames(stmt) {
+			if isPlural(obj.name) != (form == "plural") {
+				list = append(list, namingMismatch(obj, "should be "+form, ruleNamingTableForm))
+			}
+		}
+		return list, nil
+	}
+	return fn, nil
+}
+
+// isPlural reports whether the last word of a name looks like an english
+// plural
+func isPlural(name string) bool {
+	name = strings.ToLower(name)
+	if i := strings.LastIndexAny(name, "_."); i >= 0 {
+		name = name[i+1:]
+	}
+	if len(name) < 3 || !strings.HasSuffix(name, "s") {
+		return false
+	}
+	for _, s := range []string{"ss", "us", "is", "ics"} {
+		if strings.HasSuffix(name, s) {
+			return false
+		}
+	}
+	return true
+}
+
+func buildShortAliasRule(cfg *config.Config, _ lang.Dialect) (RuleFunc, error) {
+	limit := int(cfg.GetDefaultInt("tables", 3))
+	fn := func(stmt ast.Statement) ([]rules.LintMessage, error) {
+		return inspect(stmt, func(q ast.SelectStatement) ([]rules.LintMessage, error) {
+			if len(getQueryTables(q)) <= limit {
+				return nil, nil
+			}
+			var list []rules.LintMessage
+			for _, t := range q.Tables {
+				if j, ok := t.(ast.Join); ok {
+					t = j.Table
+				}
+				if a, ok := t.(ast.Alias); ok && len(a.Alias) == 1 {
+					list = append(list, aliasTooShort(a, limit))
+				}
+			}
+			return list, nil
+		})
+	}
+	return fn, nil
+}
+
+func buildKeywordRule(_ *config.Config, d lang.Dialect) (RuleFunc, error) {
+	kw := d.Keywords
+	if len(kw) == 0 {
+		kw = lang.GetKeywords()
+	}
+	kw = kw.Prepare()
+	fn := func(stmt ast.Statement) ([]rules.LintMessage, error) {
+		var (
+			list []rules.LintMessage
+			objs []namedObject
+		)
+		objs = append(objs, getTableNames(stmt)...)
+		objs = append(objs, getColumnNames(stmt)...)
+		objs = append(objs, getConstraintNames(stmt)...)
+		objs = append(objs, getIndexNames(stmt)...)
+		objs = append(objs, getCteNames(stmt)...)
+		objs = append(objs, getAliasNames(stmt)...)
+		for _, obj := range objs {
+			if _, _, ok := kw.Is([]string{obj.name}); ok {
+				list = append(list, namingKeyword(obj, d.Name))
+			}
+		}
+		return list, nil
+	}
+	return fn, nil
+}
+
+func getTableNames(stmt ast.Statement) []namedObject {
+	var list []namedObject
+	switch stmt := unwrapNode(stmt).(type) {
+	case ast.CreateTableStatement:
+		if n, ok := stmt.Name.(ast.Name); ok {
+			list = append(list, namedObject{stmt: stmt, name: n.Name(), kind: "table"})
+		}
+	case ast.AlterTableStatement:
+		if a, ok := stmt.Action.(ast.RenameTableAction); ok {
+			list = append(list, namedObject{stmt: a, name: a.Name, kind: "table"})
+		}
+	}
+	return list
+}
+
+func getColumnNames(stmt ast.Statement) []namedObject {
+	var list []namedObject
+	switch stmt := unwrapNode(stmt).(type) {
+	case ast.CreateTableStatement:
+		for _, c := range stmt.Columns {
+			if def, ok := c.(ast.ColumnDef); ok {
+				list = append(list, namedObject{stmt: def, name: def.Name, kind: "column"})
+			}
+		}
+	case ast.AlterTableStatement:
+		switch a := stmt.Action.(type) {
+		case ast.AddColumnAction:
+			if def, ok := a.Def.(ast.ColumnDef); ok {
+				list = append(list, namedObject{stmt: def, name: def.Name, kind: "column"})
+			}
+		case ast.RenameColumnAction:
+			list = append(list, namedObject{stmt: a, name: a.New, kind: "column"})
+		}
+	}
+	return list
+}
+
+func getConstraintNames(stmt ast.Statement) []namedObject {
+	var list []namedObject
+	add := func(stmt ast.Statement) {
+		c, ok := stmt.(ast.Constraint)
+		if !ok || c.Name == "" {
+			return
+		}
+		obj := namedObject{
+			stmt: c,
+			name: c.Name,
+		}
+		switch c.Statement.(type) {
+		case ast.PrimaryKeyConstraint:
+			obj.kind = "primary"
+		case ast.ForeignKeyConstraint:
+			obj.kind = "foreign"
+		case ast.UniqueConstraint:
+			obj.kind = "unique"
+		case ast.CheckConstraint:
+			obj.kind = "check"
+		}
+		list = append(list, obj)
+	}
+	switch stmt := unwrapNode(stmt).(type) {
+	case ast.CreateTableStatement:
+		for _, c := range stmt.Columns {
+			if def, ok := c.(ast.ColumnDef); ok {
+				for _, c := range def.Constraints {
+					add(c)
+				}
+			}
+		}
+		for _, c := range stmt.Constraints {
+			add(c)
+		}
+	case ast.AlterTableStatement:
+		switch a := stmt.Action.(type) {
+		case ast.AddConstraintAction:
+			add(a.Constraint)
+		case ast.RenameConstraintAction:
+			list = append(list, namedObject{stmt: a, name: a.New})
+		}
+	}
+	return list
+}
+
+func getIndexNames(stmt ast.Statement) []namedObject {
+	var list []namedObject
+	if stmt, ok := unwrapNode(stmt).(ast.CreateIndexStatement); ok {
+		list = append(list, namedObject{stmt: stmt, name: stmt.Name, kind: "index"})
+	}
+	return list
+}
+
+func getCteNames(stmt ast.Statement) []namedObject {
+	var list []namedObject
+	ast.Inspect(stmt, func(stmt ast.Statement, _ ast.Path) bool {
+		if c, ok := stmt.(ast.CteStatement); ok {
+			list = append(list, namedObject{stmt: c, name: c.Ident, kind: "cte"})
+		}
+		return true
+	})
+	return list
+}
+
+func getAliasNames(stmt ast.Statement) []namedObject {
+	var list []namedObject
+	ast.Inspect(stmt, func(stmt ast.Statement, _ ast.Path) bool {
+		if a, ok := stmt.(ast.Alias); ok && a.Alias != "" {
+			list = append(list, namedObject{stmt: a, name: a.Alias, kind: "alias"})
+		}
+		return true
+	})
+	return list
+}
+
+func unwrapNode(stmt ast.Statement) ast.Statement {
+	if n, ok := stmt.(ast.Node); ok {
+		return n.Statement
+	}
+	return stmt
+}
+
+func namingMismatch(obj namedObject, reason, rule string) rules.LintMessage {
+	kind := obj.kind
+	switch kind {
+	case "", "primary", "foreign", "unique", "check":
+		kind = "constraint"
+	}
+	msg := rules.LintMessage{
+		Severity: rules.Warning,
+		Message:  fmt.Sprintf("%s: %s name %s", obj.name, kind, reason),
+		Rule:     rule,
+	}
+	return locate(obj.stmt, msg)
+}
+
+func namingKeyword(obj namedObject, dialect string) rules.LintMessage {
+	msg := rules.LintMessage{
+		Severity: rules.Warning,
+		Message:  fmt.Sprintf("%s: name is a keyword of %s", obj.name, dialect),
+		Rule:     ruleNamingKeyword,
+	}
+	return locate(obj.stmt, msg)
+}
+
+func aliasTooShort(stmt ast.Alias, limit int) rules.LintMessage {
+	msg := rules.LintMessage{
+		Severity: rules.Info,
+		Message:  fmt.Sprintf("%s: single letter alias in a query with more than %d tables", stmt.Alias, limit),
+		Rule:     ruleNamingAliasShort,
+	}
+	return locate(stmt, msg)
+}
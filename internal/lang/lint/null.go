@@ -0,0 +1,285 @@
+package lint
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/midbel/sweet/internal/lang/ast"
+	"github.com/midbel/sweet/internal/rules"
+)
+
+// nullSafeFuncs are the functions giving a value other than null when one of
+// their arguments is null
+var nullSafeFuncs = []string{
+	"coalesce",
+	"ifnull",
+	"isnull",
+	"nvl",
+	"nvl2",
+	"count",
+}
+
+func checkNullCompare(stmt ast.Statement) ([]rules.LintMessage, error) {
+	return inspect(stmt, nullCompare)
+}
+
+func nullCompare(stmt ast.Binary) ([]rules.LintMessage, error) {
+	switch stmt.Op {
+	case "=", "<>", "!=", "<", "<=", ">", ">=":
+	default:
+		return nil, nil
+	}
+	if isNull(stmt.Left) || isNull(stmt.Right) {
+		return makeArray(nullComparison(stmt)), nil
+	}
+	return nil, nil
+}
+
+func checkNullNotIn(stmt ast.Statement) ([]rules.LintMessage, error) {
+	return inspect(stmt, nullNotIn)
+}
+
+// nullNotIn checks the NOT IN predicates whose list or subquery can give a
+// null: the predicate is then never true.
+func nullNotIn(stmt ast.Not) ([]rules.LintMessage, error) {
+	in, ok := unwrapGroup(stmt.Statement).(ast.In)
+	if !ok {
+		return nil, nil
+	}
+	switch value := unwrapGroup(in.Value).(type) {
+	case ast.List:
+		if slices.ContainsFunc(value.Values, isNull) {
+			return makeArray(nullInList(stmt)), nil
+		}
+	case ast.SelectStatement:
+		if len(value.Columns) != 1 {
+			break
+		}
+		if isNullable(value.Columns[0], value.Where) {
+			return makeArray(nullInSubquery(stmt)), nil
+		}
+	}
+	return nil, nil
+}
+
+// isNullable reports whether the column of a subquery can be null. A column
+// is considered nullable unless it is a non null literal, the result of a
+// function giving no null or is filtered by an IS NOT NULL in where.
+func isNullable(col, where ast.Statement) bool {
+	if a, ok := col.(ast.Alias); ok {
+		col = a.Statement
+	}
+	switch c := unwrapGroup(col).(type) {
+	case ast.Value:
+		return c.Null()
+	case ast.Call:
+		return !slices.Contains(nullSafeFuncs, strings.ToLower(c.GetIdent()))
+	case ast.Name:
+		return !isFilteredNotNull(c, where)
+	default:
+		return true
+	}
+}
+
+func isFilteredNotNull(name ast.Name, where ast.Statement) bool {
+	for _, c := range getConjuncts(where) {
+		n, ok := c.(ast.Not)
+		if !ok {
+			continue
+		}
+		is, ok := unwrapGroup(n.Statement).(ast.Is)
+		if !ok || !isNull(is.Value) {
+			continue
+		}
+		if other, ok := is.Ident.(ast.Name); ok && strings.EqualFold(other.Ident(), name.Ident()) {
+			return true
+		}
+	}
+	return false
+}
+
+func checkNullCount(stmt ast.Statement) ([]rules.LintMessage, error) {
+	return inspect(stmt, nullCount)
+}
+
+// nullCount checks the queries counting their rows with COUNT(*) while one of
+// their tables is on the optional side of an outer join: the rows without
+// match are counted too.
+func nullCount(stmt ast.SelectStatement) ([]rules.LintMessage, error) {
+	if len(getOptionalTables(stmt)) == 0 {
+		return nil, nil
+	}
+	var list []rules.LintMessage
+	for _, c := range stmt.Columns {
+		ast.Inspect(c, func(stmt ast.Statement, _ ast.Path) bool {
+			switch stmt := stmt.(type) {
+			case ast.SelectStatement:
+				return false
+			case ast.Call:
+				if strings.ToLower(stmt.GetIdent()) == "count" && len(stmt.Args) == 1 && isStar(stmt.Args[0]) {
+					list = append(list, nullCountStar(stmt))
+				}
+			}
+			return true
+		})
+	}
+	return list, nil
+}
+
+func checkNullJoinFilter(stmt ast.Statement) ([]rules.LintMessage, error) {
+	return inspect(stmt, nullJoinFilter)
+}
+
+// nullJoinFilter checks the conditions of the where clause rejecting the null
+// values of the optional side of an outer join: these conditions discard the
+// rows without match and the outer join becomes an inner join.
+func nullJoinFilter(stmt ast.SelectStatement) ([]rules.LintMessage, error) {
+	tables := getOptionalTables(stmt)
+	if len(tables) == 0 {
+		return nil, nil
+	}
+	var list []rules.LintMessage
+	for _, c := range getConjuncts(stmt.Where) {
+		if acceptNull(c) {
+			continue
+		}
+		for _, n := range getColumnRefs(c) {
+			if len(n.Parts) < 2 {
+				continue
+			}
+			if q := getColumnQualifier(n); slices.Contains(tables, q) {
+				list = append(list, nullOuterFilter(c, q))
+				break
+			}
+		}
+	}
+	return list, nil
+}
+
+// acceptNull reports whether the given condition can be true when a column is
+// null: it tests a value with IS NULL or uses a function handling null.
+func acceptNull(stmt ast.Statement) bool {
+	var ok bool
+	ast.Inspect(stmt, func(stmt ast.Statement, _ ast.Path) bool {
+		switch stmt := stmt.(type) {
+		case ast.Not, ast.SelectStatement:
+			return false
+		case ast.Is:
+			ok = ok || isNull(stmt.Value)
+		case ast.Call:
+			ok = ok || slices.Contains(nullSafeFuncs, strings.ToLower(stmt.GetIdent()))
+		}
+		return !ok
+	})
+	return ok
+}
+
+// getOptionalTables gives the qualifiers of the tables that are on the side of
+// an outer join where rows without match are filled with null
+func getOptionalTables(stmt ast.SelectStatement) []string {
+	var (
+		list []string
+		seen []string
+	)
+	for _, t := range stmt.Tables {
+		j, ok := t.(ast.Join)
+		if !ok {
+			if q := getTableQualifier(t); q != "" {
+				seen = append(seen, q)
+			}
+			continue
+		}
+		q := getTableQualifier(j.Table)
+		switch kind := strings.ToUpper(j.Type); {
+		case strings.HasPrefix(kind, "LEFT"):
+			list = append(list, q)
+		case strings.HasPrefix(kind, "RIGHT"):
+			list = append(list, seen...)
+		case strings.HasPrefix(kind, "FULL"):
+			list = append(list, seen...)
+			list = append(list, q)
+		}
+		seen = append(seen, q)
+	}
+	return slices.DeleteFunc(slices.Compact(list), func(q string) bool {
+		return q == ""
+	})
+}
+
+// getConjuncts splits the given condition on its AND operators
+func getConjuncts(stmt ast.Statement) []ast.Statement {
+	switch s := stmt.(type) {
+	case nil:
+		return nil
+	case ast.Group:
+		if b, ok := s.Statement.(ast.Binary); ok && b.Op == "AND" {
+			return getConjuncts(b)
+		}
+	case ast.Binary:
+		if s.Op == "AND" {
+			return append(getConjuncts(s.Left), getConjuncts(s.Right)...)
+		}
+	}
+	return []ast.Statement{stmt}
+}
+
+func unwrapGroup(stmt ast.Statement) ast.Statement {
+	for {
+		g, ok := stmt.(ast.Group)
+		if !ok {
+			return stmt
+		}
+		stmt = g.Statement
+	}
+}
+
+func isNull(stmt ast.Statement) bool {
+	v, ok := stmt.(ast.Value)
+	return ok && v.Null()
+}
+
+func nullComparison(stmt ast.Statement) rules.LintMessage {
+	msg := rules.LintMessage{
+		Severity: rules.Error,
+		Message:  "comparison with null is always unknown (use is null)",
+		Rule:     ruleNullCompare,
+	}
+	return locate(stmt, msg)
+}
+
+func nullInList(stmt ast.Statement) rules.LintMessage {
+	msg := rules.LintMessage{
+		Severity: rules.Error,
+		Message:  "not in with a null value never matches",
+		Rule:     ruleNullNotIn,
+	}
+	return locate(stmt, msg)
+}
+
+func nullInSubquery(stmt ast.Statement) rules.LintMessage {
+	msg := rules.LintMessage{
+		Severity: rules.Warning,
+		Message:  "not in with a subquery giving null never matches (use not exists or filter null)",
+		Rule:     ruleNullNotIn,
+	}
+	return locate(stmt, msg)
+}
+
+func nullCountStar(stmt ast.Statement) rules.LintMessage {
+	msg := rules.LintMessage{
+		Severity: rules.Warning,
+		Message:  "count(*) counts the rows without match of the outer join (count a column of the joined table)",
+		Rule:     ruleNullCount,
+	}
+	return locate(stmt, msg)
+}
+
+func nullOuterFilter(stmt ast.Statement, table string) rules.LintMessage {
+	msg := rules.LintMessage{
+		Severity: rules.Warning,
+		Message:  fmt.Sprintf("%s: filter on the optional side of an outer join makes it an inner join", table),
+		Rule:     ruleNullJoinFilter,
+	}
+	return locate(stmt, msg)
+}
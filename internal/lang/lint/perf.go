@@ -0,0 +1,465 @@
+package lint
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/midbel/sweet/internal/lang/ast"
+	"github.com/midbel/sweet/internal/lang/format"
+	"github.com/midbel/sweet/internal/rules"
+)
+
+func checkPredicateFunction(stmt ast.Statement) ([]rules.LintMessage, error) {
+	var list []rules.LintMessage
+	ast.Inspect(stmt, func(stmt ast.Statement, _ ast.Path) bool {
+		switch stmt := stmt.(type) {
+		case ast.SelectStatement:
+			list = append(list, predicateFunction(stmt.Where)...)
+			for _, t := range stmt.Tables {
+				if j, ok := t.(ast.Join); ok {
+					list = append(list, predicateFunction(j.Where)...)
+				}
+			}
+		case ast.UpdateStatement:
+			list = append(list, predicateFunction(stmt.Where)...)
+		case ast.DeleteStatement:
+			list = append(list, predicateFunction(stmt.Where)...)
+		}
+		return true
+	})
+	return list, nil
+}
+
+// predicateFunction checks the comparisons of a predicate where a column is
+// given to a function: an index of the column can not be used.
+func predicateFunction(stmt ast.Statement) []rules.LintMessage {
+	var list []rules.LintMessage
+	ast.Inspect(stmt, func(stmt ast.Statement, _ ast.Path) bool {
+		var sides []ast.Statement
+		switch s := stmt.(type) {
+		case ast.SelectStatement, ast.UnionStatement, ast.IntersectStatement, ast.ExceptStatement:
+			return false
+		case ast.Binary:
+			if s.IsRelation() || !isComparison(s.Op) {
+				return true
+			}
+			sides = append(sides, s.Left, s.Right)
+		case ast.In:
+			sides = append(sides, s.Ident)
+		case ast.Between:
+			sides = append(sides, s.Ident)
+		default:
+			return true
+		}
+		for _, s := range sides {
+			if fn := getWrappingFunction(s); fn != "" {
+				list = append(list, predicateWithFunction(stmt, fn))
+			}
+		}
+		return false
+	})
+	return list
+}
+
+// getWrappingFunction gives the name of the function or of the cast applied
+// to a column
+func getWrappingFunction(stmt ast.Statement) string {
+	switch s := unwrapGroup(stmt).(type) {
+	case ast.Call:
+		if s.IsAggregate() || len(getColumnRefs(s)) == 0 {
+			return ""
+		}
+		return strings.ToLower(s.GetIdent())
+	case ast.Cast:
+		if len(getColumnRefs(s.Ident)) == 0 {
+			return ""
+		}
+		return "cast"
+	default:
+		return ""
+	}
+}
+
+func isComparison(op string) bool {
+	switch op {
+	case "=", "<>", "!=", "<", "<=", ">", ">=", "LIKE", "ILIKE":
+		return true
+	default:
+		return false
+	}
+}
+
+func checkLeadingWildcard(stmt ast.Statement) ([]rules.LintMessage, error) {
+	return inspect(stmt, leadingWildcard)
+}
+
+func leadingWildcard(stmt ast.Binary) ([]rules.LintMessage, error) {
+	if stmt.Op != "LIKE" && stmt.Op != "ILIKE" {
+		return nil, nil
+	}
+	v, ok := stmt.Right.(ast.Value)
+	if !ok || !strings.HasPrefix(v.Literal, "%") {
+		return nil, nil
+	}
+	return makeArray(likeLeadingWildcard(stmt)), nil
+}
+
+// checkSelectStar checks the queries selecting all the columns of their tables
+// in views, inserts and subqueries where the columns should be known. A
+// subquery of EXISTS is not checked.
+func checkSelectStar(stmt ast.Statement) ([]rules.LintMessage, error) {
+	var list []rules.LintMessage
+	ast.Inspect(stmt, func(stmt ast.Statement, path ast.Path) bool {
+		q, ok := stmt.(ast.SelectStatement)
+		if !ok || !slices.ContainsFunc(q.Columns, isStar) {
+			return true
+		}
+		switch {
+		case ast.Inside[ast.Exists](path):
+		case ast.Inside[ast.CreateViewStatement](path):
+			list = append(list, selectStar(q, "view", false))
+		case ast.Inside[ast.SelectStatement](path):
+			list = append(list, selectStar(q, "subquery", inJoin(path)))
+		case ast.Inside[ast.InsertStatement](path):
+			list = append(list, selectStar(q, "insert", false))
+		}
+		return true
+	})
+	return list, nil
+}
+
+func checkOrColumns(stmt ast.Statement) ([]rules.LintMessage, error) {
+	return inspect(stmt, orColumns)
+}
+
+// orColumns checks the conditions of the where clause made of OR on several
+// columns: a single index can not be used to select the rows.
+func orColumns(stmt ast.SelectStatement) ([]rules.LintMessage, error) {
+	var list []rules.LintMessage
+	for _, c := range getConjuncts(stmt.Where) {
+		var (
+			cols []string
+			ok   = true
+		)
+		for _, d := range getDisjuncts(c) {
+			col := getPredicateColumn(d)
+			if col == "" {
+				ok = false
+				break
+			}
+			if !slices.Contains(cols, col) {
+				cols = append(cols, col)
+			}
+		}
+		if ok && len(cols) > 1 {
+			list = append(list, orOnColumns(c, cols))
+		}
+	}
+	return list, nil
+}
+
+// getDisjuncts splits the given condition on its OR operators
+func getDisjuncts(stmt ast.Statement) []ast.Statement {
+	if b, ok := unwrapGroup(stmt).(ast.Binary); ok && b.Op == "OR" {
+		return append(getDisjuncts(b.Left), getDisjuncts(b.Right)...)
+	}
+	return []ast.Statement{stmt}
+}
+
+// getPredicateColumn gives the column tested by a simple predicate comparing
+// a column with a value
+func getPredicateColumn(stmt ast.Statement) string {
+	var ident ast.Statement
+	switch s := unwrapGroup(stmt).(type) {
+	case ast.Binary:
+		if !isComparison(s.Op) {
+			return ""
+		}
+		if _, ok := s.Right.(ast.Value); ok {
+			ident = s.Left
+		} else if _, ok := s.Left.(ast.Value); ok {
+			ident = s.Right
+		}
+	case ast.In:
+		ident = s.Ident
+	case ast.Between:
+		ident = s.Ident
+	case ast.Is:
+		ident = s.Ident
+	}
+	n, ok := ident.(ast.Name)
+	if !ok {
+		return ""
+	}
+	return strings.ToLower(n.Ident())
+}
+
+func checkOrderSubquery(stmt ast.Statement) ([]rules.LintMessage, error) {
+	var list []rules.LintMessage
+	ast.Inspect(stmt, func(stmt ast.Statement, path ast.Path) bool {
+		q, ok := stmt.(ast.SelectStatement)
+		if !ok || len(q.Orders) == 0 || q.Limit != nil {
+			return true
+		}
+		switch {
+		case ast.Inside[ast.SelectStatement](path):
+			list = append(list, orderInSubquery(q, "subquery", inJoin(path)))
+		case ast.Inside[ast.CteStatement](path):
+			list = append(list, orderInSubquery(q, "cte", false))
+		}
+		return true
+	})
+	return list, nil
+}
+
+func checkDistinctGroup(stmt ast.Statement) ([]rules.LintMessage, error) {
+	return inspect(stmt, distinctGroup)
+}
+
+func distinctGroup(stmt ast.SelectStatement) ([]rules.LintMessage, error) {
+	if !stmt.Distinct || len(stmt.Groups) == 0 {
+		return nil, nil
+	}
+	return makeArray(distinctWithGroup(stmt)), nil
+}
+
+func checkUnionAll(stmt ast.Statement) ([]rules.LintMessage, error) {
+	return inspect(stmt, unionAll)
+}
+
+// unionAll checks the UNION whose queries can not give the same rows: they
+// select different literals in a column or filter a column on different
+// values. Removing the duplicates is then useless.
+func unionAll(stmt ast.UnionStatement) ([]rules.LintMessage, error) {
+	if stmt.All {
+		return nil, nil
+	}
+	left, ok := unwrapGroup(stmt.Left).(ast.SelectStatement)
+	if !ok {
+		return nil, nil
+	}
+	right, ok := unwrapGroup(stmt.Right).(ast.SelectStatement)
+	if !ok {
+		return nil, nil
+	}
+	if disjointColumns(left, right) || disjointFilters(left, right) {
+		return makeArray(unionWithoutAll(stmt)), nil
+	}
+	return nil, nil
+}
+
+func disjointColumns(left, right ast.SelectStatement) bool {
+	if len(left.Columns) != len(right.Columns) {
+		return false
+	}
+	for i := range left.Columns {
+		x, ok1 := getLiteralValue(left.Columns[i])
+		y, ok2 := getLiteralValue(right.Columns[i])
+		if ok1 && ok2 && x != y {
+			return true
+		}
+	}
+	return false
+}
+
+func disjointFilters(left, right ast.SelectStatement) bool {
+	values := make(map[string]string)
+	for _, c := range getConjuncts(left.Where) {
+		if col, val, ok := getEqualityFilter(c); ok {
+			values[col] = val
+		}
+	}
+	for _, c := range getConjuncts(right.Where) {
+		col, val, ok := getEqualityFilter(c)
+		if !ok {
+			continue
+		}
+		if other, ok := values[col]; ok && other != val {
+			return true
+		}
+	}
+	return false
+}
+
+// getEqualityFilter gives the column and the value of a predicate col = value
+func getEqualityFilter(stmt ast.Statement) (string, string, bool) {
+	b, ok := unwrapGroup(stmt).(ast.Binary)
+	if !ok || b.Op != "=" {
+		return "", "", false
+	}
+	n, ok := b.Left.(ast.Name)
+	if !ok {
+		return "", "", false
+	}
+	v, ok := getLiteralValue(b.Right)
+	return strings.ToLower(n.Name()), v, ok
+}
+
+func getLiteralValue(stmt ast.Statement) (string, bool) {
+	if a, ok := stmt.(ast.Alias); ok {
+		stmt = a.Statement
+	}
+	v, ok := stmt.(ast.Value)
+	if !ok || v.Null() {
+		return "", false
+	}
+	return v.Literal, true
+}
+
+func checkCorrelatedSubquery(stmt ast.Statement) ([]rules.LintMessage, error) {
+	return inspect(stmt, correlatedSubquery)
+}
+
+// correlatedSubquery checks the subqueries of the select list that use the
+// columns of the tables of the query: they are run for each row.
+func correlatedSubquery(stmt ast.SelectStatement) ([]rules.LintMessage, error) {
+	outer := getQueryTables(stmt)
+	if len(outer) == 0 {
+		return nil, nil
+	}
+	var list []rules.LintMessage
+	for _, c := range stmt.Columns {
+		ast.Inspect(c, func(stmt ast.Statement, _ ast.Path) bool {
+			q, ok := stmt.(ast.SelectStatement)
+			if !ok {
+				return true
+			}
+			if isCorrelated(q, outer) {
+				list = append(list, correlatedInSelect(q))
+			}
+			return false
+		})
+	}
+	return list, nil
+}
+
+func isCorrelated(stmt ast.SelectStatement, outer []string) bool {
+	var (
+		inner = getQueryTables(stmt)
+		found bool
+	)
+	ast.Inspect(stmt, func(stmt ast.Statement, _ ast.Path) bool {
+		n, ok := stmt.(ast.Name)
+		if !ok || len(n.Parts) < 2 {
+			return !found
+		}
+		q := getColumnQualifier(n)
+		found = slices.Contains(outer, q) && !slices.Contains(inner, q)
+		return !found
+	})
+	return found
+}
+
+// getQueryTables gives the qualifiers of the tables of the from clause of a
+// query, joined tables included
+func getQueryTables(stmt ast.SelectStatement) []string {
+	var list []string
+	for _, t := range stmt.Tables {
+		if j, ok := t.(ast.Join); ok {
+			t = j.Table
+		}
+		if q := getTableQualifier(t); q != "" {
+			list = append(list, q)
+		}
+	}
+	return list
+}
+
+// inJoin reports whether the node of the given path is a table of a join
+func inJoin(path ast.Path) bool {
+	for i := len(path) - 1; i >= 0; i-- {
+		switch path[i].(type) {
+		case ast.Alias, ast.Group:
+		case ast.Join:
+			return true
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// suggest adds to the message the rewrite rule of the formatter fixing it
+func suggest(msg rules.LintMessage, rule format.RewriteRule) rules.LintMessage {
+	msg.Message = fmt.Sprintf("%s (format -rewrite %s)", msg.Message, strings.Join(rule.Names(), ","))
+	return msg
+}
+
+func predicateWithFunction(stmt ast.Statement, fn string) rules.LintMessage {
+	msg := rules.LintMessage{
+		Severity: rules.Warning,
+		Message:  fmt.Sprintf("%s applied to a column prevents the use of an index", fn),
+		Rule:     rulePerfFunction,
+	}
+	return locate(stmt, msg)
+}
+
+func likeLeadingWildcard(stmt ast.Statement) rules.LintMessage {
+	msg := rules.LintMessage{
+		Severity: rules.Warning,
+		Message:  "like pattern starting with a wildcard prevents the use of an index",
+		Rule:     rulePerfWildcard,
+	}
+	return locate(stmt, msg)
+}
+
+func selectStar(stmt ast.Statement, where string, join bool) rules.LintMessage {
+	msg := rules.LintMessage{
+		Severity: rules.Warning,
+		Message:  fmt.Sprintf("select * in %s, columns should be listed", where),
+		Rule:     rulePerfSelectStar,
+	}
+	if join {
+		msg = suggest(msg, format.RewriteWithCte)
+	}
+	return locate(stmt, msg)
+}
+
+func orOnColumns(stmt ast.Statement, cols []string) rules.LintMessage {
+	msg := rules.LintMessage{
+		Severity: rules.Info,
+		Message:  fmt.Sprintf("or on columns %s prevents the use of a single index (use union)", strings.Join(cols, ", ")),
+		Rule:     rulePerfOrColumns,
+	}
+	return locate(stmt, msg)
+}
+
+func orderInSubquery(stmt ast.Statement, where string, join bool) rules.LintMessage {
+	msg := rules.LintMessage{
+		Severity: rules.Warning,
+		Message:  fmt.Sprintf("order by without limit in %s is useless", where),
+		Rule:     rulePerfOrderSubquery,
+	}
+	if join {
+		msg = suggest(msg, format.RewriteWithCte)
+	}
+	return locate(stmt, msg)
+}
+
+func distinctWithGroup(stmt ast.Statement) rules.LintMessage {
+	msg := rules.LintMessage{
+		Severity: rules.Warning,
+		Message:  "distinct with group by is useless",
+		Rule:     rulePerfDistinctGroup,
+	}
+	return locate(stmt, msg)
+}
+
+func unionWithoutAll(stmt ast.Statement) rules.LintMessage {
+	msg := rules.LintMessage{
+		Severity: rules.Info,
+		Message:  "queries of union can not give the same rows (use union all)",
+		Rule:     rulePerfUnionAll,
+	}
+	return locate(stmt, msg)
+}
+
+func correlatedInSelect(stmt ast.Statement) rules.LintMessage {
+	msg := rules.LintMessage{
+		Severity: rules.Warning,
+		Message:  "correlated subquery in select list is run for each row (use a join)",
+		Rule:     rulePerfCorrelated,
+	}
+	return locate(stmt, msg)
+}
@@ -0,0 +1,346 @@
+package lint
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/midbel/sweet/internal/lang/ast"
+	"github.com/midbel/sweet/internal/rules"
+)
+
+func checkUnusedVariable(stmt ast.Statement) ([]rules.LintMessage, error) {
+	return inspect(stmt, unusedVariable)
+}
+
+func unusedVariable(stmt ast.CreateProcedureStatement) ([]rules.LintMessage, error) {
+	var (
+		list []rules.LintMessage
+		refs = getVariableRefs(stmt.Body)
+	)
+	for _, d := range getDeclares(stmt.Body) {
+		if !slices.Contains(refs, strings.ToLower(d.Ident)) {
+			list = append(list, variableUnused(d))
+		}
+	}
+	return list, nil
+}
+
+func checkUnassignedVariable(stmt ast.Statement) ([]rules.LintMessage, error) {
+	return inspect(stmt, unassignedVariable)
+}
+
+// unassignedVariable checks the variables read before a value has been given
+// to them either by their declaration or by a SET. The statements of the body
+// are visited in the order they appear.
+func unassignedVariable(stmt ast.CreateProcedureStatement) ([]rules.LintMessage, error) {
+	var (
+		list     []rules.LintMessage
+		assigned = make(map[string]bool)
+		visit    func(ast.Statement, ast.Path) bool
+	)
+	visit = func(stmt ast.Statement, _ ast.Path) bool {
+		switch stmt := stmt.(type) {
+		case ast.Declare:
+			if stmt.Value != nil {
+				ast.Inspect(stmt.Value, visit)
+			}
+			assigned[strings.ToLower(stmt.Ident)] = stmt.Value != nil
+			return false
+		case ast.Set:
+			ast.Inspect(stmt.Expr, visit)
+			if _, ok := assigned[strings.ToLower(stmt.Ident)]; ok {
+				assigned[strings.ToLower(stmt.Ident)] = true
+			}
+			return false
+		case ast.Name:
+			if len(stmt.Parts) != 1 {
+				break
+			}
+			ident := strings.ToLower(stmt.Ident())
+			if ok, found := assigned[ident]; found && !ok {
+				list = append(list, variableUnassigned(stmt))
+				assigned[ident] = true
+			}
+		}
+		return true
+	}
+	ast.Inspect(stmt.Body, visit)
+	return list, nil
+}
+
+func checkUnreachable(stmt ast.Statement) ([]rules.LintMessage, error) {
+	return inspect(stmt, unreachable)
+}
+
+// unreachable checks the statements of a body coming after a RETURN
+func unreachable(stmt ast.List) ([]rules.LintMessage, error) {
+	for i, s := range stmt.Values {
+		if _, ok := unwrapNode(s).(ast.Return); ok && i < len(stmt.Values)-1 {
+			return makeArray(unreachableCode(stmt.Values[i+1])), nil
+		}
+	}
+	return nil, nil
+}
+
+func checkWhileUnchanged(stmt ast.Statement) ([]rules.LintMessage, error) {
+	return inspect(stmt, whileUnchanged)
+}
+
+// whileUnchanged checks the loops whose body changes none of the variables of
+// their condition. Loops exiting with a RETURN and conditions using subqueries
+// are not checked.
+func whileUnchanged(stmt ast.While) ([]rules.LintMessage, error) {
+	if hasSubquery(stmt.Cdt) || hasReturn(stmt.Body) {
+		return nil, nil
+	}
+	vars := getVariableRefs(stmt.Cdt)
+	if len(vars) == 0 {
+		return nil, nil
+	}
+	if slices.ContainsFunc(getAssignments(stmt.Body), func(v string) bool {
+		return slices.Contains(vars, v)
+	}) {
+		return nil, nil
+	}
+	return makeArray(loopUnchanged(stmt)), nil
+}
+
+func checkEmptyIf(stmt ast.Statement) ([]rules.LintMessage, error) {
+	return inspect(stmt, emptyIf)
+}
+
+func emptyIf(stmt ast.If) ([]rules.LintMessage, error) {
+	var list []rules.LintMessage
+	if isEmptyBody(stmt.Csq) {
+		list = append(list, emptyBranch(stmt, "then"))
+	}
+	if stmt.Alt != nil && isEmptyBody(stmt.Alt) {
+		list = append(list, emptyBranch(stmt, "else"))
+	}
+	return list, nil
+}
+
+func checkShadowedParameter(stmt ast.Statement) ([]rules.LintMessage, error) {
+	return inspect(stmt, shadowedParameter)
+}
+
+func shadowedParameter(stmt ast.CreateProcedureStatement) ([]rules.LintMessage, error) {
+	var list []rules.LintMessage
+	for _, d := range getDeclares(stmt.Body) {
+		ok := slices.ContainsFunc(stmt.Parameters, func(p ast.Statement) bool {
+			param, ok := p.(ast.ProcedureParameter)
+			return ok && strings.EqualFold(param.Name, d.Ident)
+		})
+		if ok {
+			list = append(list, parameterShadowed(d))
+		}
+	}
+	return list, nil
+}
+
+func checkOutParameter(stmt ast.Statement) ([]rules.LintMessage, error) {
+	return inspect(stmt, outParameter)
+}
+
+func outParameter(stmt ast.CreateProcedureStatement) ([]rules.LintMessage, error) {
+	var (
+		list []rules.LintMessage
+		set  = getAssignments(stmt.Body)
+	)
+	for _, p := range stmt.Parameters {
+		param, ok := p.(ast.ProcedureParameter)
+		if !ok || param.Mode != ast.ModeOut {
+			continue
+		}
+		if !slices.Contains(set, strings.ToLower(param.Name)) {
+			list = append(list, parameterUnassigned(param))
+		}
+	}
+	return list, nil
+}
+
+// checkNestedTransaction checks the calls made inside a transaction block to
+// the procedures of the script that commit or rollback by themselves
+func checkNestedTransaction(stmt ast.Statement) ([]rules.LintMessage, error) {
+	s, ok := stmt.(script)
+	if !ok {
+		return nil, nil
+	}
+	var procs []string
+	for _, stmt := range s.statements {
+		p, ok := unwrapNode(stmt).(ast.CreateProcedureStatement)
+		if ok && hasTransaction(p.Body) {
+			procs = append(procs, getTableQualifier(p.Name))
+		}
+	}
+	if len(procs) == 0 {
+		return nil, nil
+	}
+	var (
+		list  []rules.LintMessage
+		check = func(stmt ast.CallStatement) ([]rules.LintMessage, error) {
+			if !slices.Contains(procs, getTableQualifier(stmt.Ident)) {
+				return nil, nil
+			}
+			return makeArray(transactionInProcedure(stmt)), nil
+		}
+	)
+	for _, stmt := range s.statements {
+		switch stmt := unwrapNode(stmt).(type) {
+		case ast.StartTransaction, ast.List:
+			others, err := inspect(stmt, check)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, others...)
+		}
+	}
+	return list, nil
+}
+
+func hasTransaction(stmt ast.Statement) bool {
+	var found bool
+	ast.Inspect(stmt, func(stmt ast.Statement, _ ast.Path) bool {
+		switch stmt.(type) {
+		case ast.StartTransaction, ast.Commit, ast.Rollback, ast.Savepoint, ast.ReleaseSavepoint, ast.RollbackSavepoint:
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+func hasReturn(stmt ast.Statement) bool {
+	var found bool
+	ast.Inspect(stmt, func(stmt ast.Statement, _ ast.Path) bool {
+		if _, ok := stmt.(ast.Return); ok {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+func hasSubquery(stmt ast.Statement) bool {
+	var found bool
+	ast.Inspect(stmt, func(stmt ast.Statement, _ ast.Path) bool {
+		if _, ok := stmt.(ast.SelectStatement); ok {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+func isEmptyBody(stmt ast.Statement) bool {
+	list, ok := stmt.(ast.List)
+	return ok && len(list.Values) == 0
+}
+
+func getDeclares(stmt ast.Statement) []ast.Declare {
+	var list []ast.Declare
+	ast.Inspect(stmt, func(stmt ast.Statement, _ ast.Path) bool {
+		if d, ok := stmt.(ast.Declare); ok {
+			list = append(list, d)
+		}
+		return true
+	})
+	return list
+}
+
+// getVariableRefs gives the names of the variables read by the given statement
+func getVariableRefs(stmt ast.Statement) []string {
+	var list []string
+	ast.Inspect(stmt, func(stmt ast.Statement, _ ast.Path) bool {
+		if n, ok := stmt.(ast.Name); ok && len(n.Parts) == 1 {
+			list = append(list, strings.ToLower(n.Ident()))
+		}
+		return true
+	})
+	return list
+}
+
+// getAssignments gives the names of the variables given a value by SET
+func getAssignments(stmt ast.Statement) []string {
+	var list []string
+	ast.Inspect(stmt, func(stmt ast.Statement, _ ast.Path) bool {
+		if s, ok := stmt.(ast.Set); ok {
+			list = append(list, strings.ToLower(s.Ident))
+		}
+		return true
+	})
+	return list
+}
+
+func variableUnused(stmt ast.Declare) rules.LintMessage {
+	msg := rules.LintMessage{
+		Severity: rules.Warning,
+		Message:  fmt.Sprintf("%s: variable declared but never used", stmt.Ident),
+		Rule:     ruleProcedureUnused,
+	}
+	return locate(stmt, msg)
+}
+
+func variableUnassigned(stmt ast.Name) rules.LintMessage {
+	msg := rules.LintMessage{
+		Severity: rules.Error,
+		Message:  fmt.Sprintf("%s: variable used before being assigned", stmt.Ident()),
+		Rule:     ruleProcedureUnassigned,
+	}
+	return locate(stmt, msg)
+}
+
+func unreachableCode(stmt ast.Statement) rules.LintMessage {
+	msg := rules.LintMessage{
+		Severity: rules.Warning,
+		Message:  "unreachable statement after return",
+		Rule:     ruleProcedureUnreachable,
+	}
+	return locate(stmt, msg)
+}
+
+func loopUnchanged(stmt ast.While) rules.LintMessage {
+	msg := rules.LintMessage{
+		Severity: rules.Error,
+		Message:  "variables of the loop condition are never modified by its body",
+		Rule:     ruleProcedureWhile,
+	}
+	return locate(stmt, msg)
+}
+
+func emptyBranch(stmt ast.If, branch string) rules.LintMessage {
+	msg := rules.LintMessage{
+		Severity: rules.Warning,
+		Message:  fmt.Sprintf("empty %s branch", branch),
+		Rule:     ruleProcedureEmptyIf,
+	}
+	return locate(stmt, msg)
+}
+
+func parameterShadowed(stmt ast.Declare) rules.LintMessage {
+	msg := rules.LintMessage{
+		Severity: rules.Warning,
+		Message:  fmt.Sprintf("%s: variable shadows a parameter of the procedure", stmt.Ident),
+		Rule:     ruleProcedureShadow,
+	}
+	return locate(stmt, msg)
+}
+
+func parameterUnassigned(stmt ast.ProcedureParameter) rules.LintMessage {
+	msg := rules.LintMessage{
+		Severity: rules.Warning,
+		Message:  fmt.Sprintf("%s: out parameter never assigned", stmt.Name),
+		Rule:     ruleProcedureOutParam,
+	}
+	return locate(stmt, msg)
+}
+
+func transactionInProcedure(stmt ast.CallStatement) rules.LintMessage {
+	msg := rules.LintMessage{
+		Severity: rules.Error,
+		Message:  "procedure ending the transaction is called inside a transaction",
+		Rule:     ruleProcedureTransaction,
+	}
+	return locate(stmt, msg)
+}
@@ -1,167 +1,89 @@
 package lint
 
 import (
-	"errors"
-	"slices"
-
 	"github.com/midbel/sweet/internal/lang/ast"
 	"github.com/midbel/sweet/internal/rules"
 )
 
 func checkRewriteIn(stmt ast.Statement) ([]rules.LintMessage, error) {
-	switch stmt := stmt.(type) {
-	case ast.SelectStatement:
-		return selectRewriteIn(stmt)
-	case ast.UnionStatement:
-		return handleCompoundStatement(stmt.Left, stmt.Right, checkRewriteIn)
-	case ast.IntersectStatement:
-		return handleCompoundStatement(stmt.Left, stmt.Right, checkRewriteIn)
-	case ast.ExceptStatement:
-		return handleCompoundStatement(stmt.Left, stmt.Right, checkRewriteIn)
-	case ast.WithStatement:
-		return handleWithStatement(stmt, checkRewriteIn)
-	case ast.CteStatement:
-		return checkRewriteIn(stmt.Statement)
-	case ast.Join:
-		return joinRewriteIn(stmt)
-	case ast.Group:
-		return checkRewriteIn(stmt.Statement)
-	case ast.Binary:
-		return lintIn(stmt)
-	case ast.In:
-		return lintIn(stmt)
-	default:
-		return nil, ErrNa
-	}
+	return inspect(stmt, lintIn)
 }
 
-func selectRewriteIn(stmt ast.SelectStatement) ([]rules.LintMessage, error) {
-	list, err := lintIn(stmt.Where)
-	if err != nil && !errors.Is(err, ErrNa) {
-		return nil, err
+func lintIn(stmt ast.In) ([]rules.LintMessage, error) {
+	vs, ok := stmt.Value.(ast.List)
+	if !ok || len(vs.Values) != 1 {
+		return nil, nil
 	}
-	others, err := handleSelectStatement(stmt, checkRewriteIn)
-	return slices.Concat(list, others), err
+	return makeArray(rewriteIn(stmt)), nil
 }
 
-func joinRewriteIn(stmt ast.Join) ([]rules.LintMessage, error) {
-	list, err := checkRewriteIn(stmt.Where)
-	if err != nil && !errors.Is(err, ErrNa) {
-		return nil, err
-	}
-	others, err := checkRewriteIn(stmt.Table)
-	return slices.Concat(list, others), err
+func checkRewriteBinary(stmt ast.Statement) ([]rules.LintMessage, error) {
+	return inspect(stmt, lintBinary)
 }
 
-func lintIn(stmt ast.Statement) ([]rules.LintMessage, error) {
-	switch stmt := stmt.(type) {
-	case ast.In:
-		vs, ok := stmt.Value.(ast.List)
-		if !ok {
-			return nil, nil
-		}
-		if len(vs.Values) == 1 {
-			return makeArray(rewriteIn()), nil
-		}
+func lintBinary(bin ast.Binary) ([]rules.LintMessage, error) {
+	if bin.Op != "=" && bin.Op != "<>" {
 		return nil, nil
-	case ast.Binary:
-		if !stmt.IsRelation() {
-			return nil, ErrNa
-		}
-		l1, err1 := lintIn(stmt.Left)
-		if err1 != nil && !errors.Is(err1, ErrNa) {
-			return nil, err1
-		}
-		l2, err2 := lintIn(stmt.Right)
-		if err2 != nil && !errors.Is(err2, ErrNa) {
-			return nil, err2
-		}
-		return slices.Concat(l1, l2), nil
-	default:
-		return nil, ErrNa
 	}
-}
-
-func checkRewriteBinary(stmt ast.Statement) ([]rules.LintMessage, error) {
-	switch stmt := stmt.(type) {
-	case ast.SelectStatement:
-		return selectRewriteBinary(stmt)
-	case ast.UnionStatement:
-		return handleCompoundStatement(stmt.Left, stmt.Right, checkRewriteBinary)
-	case ast.IntersectStatement:
-		return handleCompoundStatement(stmt.Left, stmt.Right, checkRewriteBinary)
-	case ast.ExceptStatement:
-		return handleCompoundStatement(stmt.Left, stmt.Right, checkRewriteBinary)
-	case ast.WithStatement:
-		return handleWithStatement(stmt, checkRewriteBinary)
-	case ast.CteStatement:
-		return checkRewriteBinary(stmt.Statement)
-	case ast.Join:
-		return joinRewriteBinary(stmt)
-	case ast.Group:
-		return checkRewriteBinary(stmt.Statement)
-	default:
-		return nil, ErrNa
+	if v, ok := bin.Right.(ast.Value); ok && v.Constant() {
+		return makeArray(rewriteBinary(bin)), nil
 	}
-}
-
-func selectRewriteBinary(stmt ast.SelectStatement) ([]rules.LintMessage, error) {
-	list, err := lintBinary(stmt.Where)
-	if err != nil && !errors.Is(err, ErrNa) {
-		return nil, err
+	if v, ok := bin.Left.(ast.Value); ok && v.Constant() {
+		return makeArray(rewriteBinary(bin)), nil
 	}
-	others, err := handleSelectStatement(stmt, checkRewriteBinary)
-	return slices.Concat(list, others), err
+	return nil, nil
 }
 
-func joinRewriteBinary(stmt ast.Join) ([]rules.LintMessage, error) {
-	list, err := checkRewriteBinary(stmt.Where)
-	if err != nil && !errors.Is(err, ErrNa) {
-		return nil, err
-	}
-	others, err := checkRewriteBinary(stmt.Table)
-	return slices.Concat(list, others), err
+func checkRewriteNot(stmt ast.Statement) ([]rules.LintMessage, error) {
+	return inspect(stmt, lintNot)
 }
 
-func lintBinary(stmt ast.Statement) ([]rules.LintMessage, error) {
-	bin, ok := stmt.(ast.Binary)
-	if !ok {
-		return nil, ErrNa
+// lintNot checks for negations that can be written with the negated form of
+// an operator: NOT (a = b), NOT (a IN (...)), NOT NOT a...
+func lintNot(stmt ast.Not) ([]rules.LintMessage, error) {
+	inner, group := stmt.Statement, false
+	if g, ok := inner.(ast.Group); ok {
+		inner, group = g.Statement, true
 	}
-	if bin.IsRelation() {
-		l1, err1 := lintBinary(bin.Left)
-		if err1 != nil && !errors.Is(err1, ErrNa) {
-			return nil, err1
+	switch inner := inner.(type) {
+	case ast.Not:
+		return makeArray(rewriteNot(stmt)), nil
+	case ast.Binary:
+		switch inner.Op {
+		case "=", "<>", "!=", "<", "<=", ">", ">=":
+			return makeArray(rewriteNot(stmt)), nil
 		}
-		l2, err2 := lintBinary(bin.Right)
-		if err2 != nil && !errors.Is(err2, ErrNa) {
-			return nil, err2
+	case ast.In, ast.Is, ast.Between:
+		if group {
+			return makeArray(rewriteNot(stmt)), nil
 		}
-		return slices.Concat(l1, l2), nil
 	}
-	if bin.Op == "=" || bin.Op == "<>" {
-		if v, ok := bin.Right.(ast.Value); ok && v.Constant() {
-			return makeArray(rewriteBinary()), nil
-		}
-		if v, ok := bin.Left.(ast.Value); ok && v.Constant() {
-			return makeArray(rewriteBinary()), nil
-		}
+	return nil, nil
+}
+
+func rewriteNot(stmt ast.Statement) rules.LintMessage {
+	msg := rules.LintMessage{
+		Severity: rules.Warning,
+		Message:  "negation should be rewritten with the negated operator",
+		Rule:     ruleRewriteExprNot,
 	}
-	return nil, ErrNa
+	return locate(stmt, msg)
 }
 
-func rewriteIn() rules.LintMessage {
-	return rules.LintMessage{
+func rewriteIn(stmt ast.Statement) rules.LintMessage {
+	msg := rules.LintMessage{
 		Severity: rules.Warning,
 		Message:  "in predicate should be rewritten",
 		Rule:     ruleRewriteExprIn,
 	}
+	return locate(stmt, msg)
 }
 
-func rewriteBinary() rules.LintMessage {
-	return rules.LintMessage{
+func rewriteBinary(stmt ast.Statement) rules.LintMessage {
+	msg := rules.LintMessage{
 		Severity: rules.Warning,
 		Message:  "expression should be rewritten",
 		Rule:     ruleRewriteExpr,
 	}
+	return locate(stmt, msg)
 }
@@ -31,6 +31,56 @@ const (
 	ruleRewriteExprNot         = "rewrite.expr.not"
 	ruleInconsistentUseAs      = "inconsistent.use.as"
 	ruleInconsistentUseOrder   = "inconsistent.use.order"
+	ruleIdentQuoteSensitive    = "ident.quote.sensitive"
+	ruleSafetyWhereMissing     = "safety.where.missing"
+	ruleSafetyWhereAlways      = "safety.where.always"
+	ruleSafetyDestructive      = "safety.destructive"
+	ruleSafetyCartesian        = "safety.join.cartesian"
+	ruleSafetyMergeMatched     = "safety.merge.matched"
+	ruleNullCompare            = "null.compare"
+	ruleNullNotIn              = "null.not.in"
+	ruleNullCount              = "null.count"
+	ruleNullJoinFilter         = "null.join.filter"
+	rulePerfFunction           = "perf.predicate.function"
+	rulePerfWildcard           = "perf.predicate.wildcard"
+	rulePerfSelectStar         = "perf.select.star"
+	rulePerfOrColumns          = "perf.predicate.or"
+	rulePerfOrderSubquery      = "perf.subquery.order"
+	rulePerfCorrelated         = "perf.subquery.correlated"
+	rulePerfDistinctGroup      = "perf.distinct.group"
+	rulePerfUnionAll           = "perf.union.all"
+	ruleNamingTable            = "naming.table"
+	ruleNamingTableForm        = "naming.table.form"
+	ruleNamingColumn           = "naming.column"
+	ruleNamingConstraint       = "naming.constraint"
+	ruleNamingIndex            = "naming.index"
+	ruleNamingCte              = "naming.cte"
+	ruleNamingAlias            = "naming.alias"
+	ruleNamingAliasShort       = "naming.alias.short"
+	ruleNamingKeyword          = "naming.keyword"
+	ruleMigrationNotNull       = "migration.column.notnull"
+	ruleMigrationType          = "migration.column.type"
+	ruleMigrationDrop          = "migration.drop"
+	ruleMigrationRename        = "migration.rename"
+	ruleMigrationConcurrent    = "migration.index.concurrently"
+	ruleMigrationExists        = "migration.exists"
+	ruleMigrationPrimaryKey    = "migration.table.primary"
+	ruleMigrationForeignIndex  = "migration.foreign.index"
+	ruleSecurityGrantAll       = "security.grant.all"
+	ruleSecurityGrantPublic    = "security.grant.public"
+	ruleSecurityGrantOption    = "security.grant.option"
+	ruleSecurityRevoke         = "security.revoke.unmatched"
+	ruleSecurityDefiner        = "security.procedure.definer"
+	ruleSecurityDynamicSql     = "security.execute.dynamic"
+	ruleSecurityCredentials    = "security.credentials"
+	ruleProcedureUnused        = "procedure.variable.unused"
+	ruleProcedureUnassigned    = "procedure.variable.unassigned"
+	ruleProcedureUnreachable   = "procedure.unreachable"
+	ruleProcedureWhile         = "procedure.while.unchanged"
+	ruleProcedureEmptyIf       = "procedure.if.empty"
+	ruleProcedureShadow        = "procedure.parameter.shadow"
+	ruleProcedureOutParam      = "procedure.parameter.out"
+	ruleProcedureTransaction   = "procedure.transaction"
 )
 
 type RuleFunc = rules.RuleFunc[ast.Statement]
@@ -51,15 +101,65 @@ var allRules = map[string]RuleFunc{
 	ruleSubqueryNotAllow:       checkSubqueriesNotAllow,
 	ruleSubqueryColsMismatched: checkResultSubquery,
 	ruleExprUnqualified:        checkForUnqualifiedNames,
-	ruleExprAggregate:          nil,
-	ruleExprInvalid:            nil,
+	ruleExprAggregate:          checkGroupBy,
+	ruleExprInvalid:            checkInvalidExpr,
 	ruleConstExprJoin:          checkJoin,
 	ruleConstExprBin:           checkConstantBinary,
-	ruleRewriteExpr:            nil,
+	ruleRewriteExpr:            checkRewriteBinary,
 	ruleRewriteExprIn:          checkRewriteIn,
-	ruleRewriteExprNot:         nil,
+	ruleRewriteExprNot:         checkRewriteNot,
 	ruleInconsistentUseAs:      checkAsUsage,
 	ruleInconsistentUseOrder:   checkDirectionUsage,
+	ruleIdentQuoteSensitive:    defaultRule(ruleIdentQuoteSensitive),
+	ruleSafetyWhereMissing:     checkWhereMissing,
+	ruleSafetyWhereAlways:      checkWhereAlways,
+	ruleSafetyDestructive:      checkDestructive,
+	ruleSafetyCartesian:        checkCartesianProduct,
+	ruleSafetyMergeMatched:     checkMergeMatched,
+	ruleNullCompare:            checkNullCompare,
+	ruleNullNotIn:              checkNullNotIn,
+	ruleNullCount:              checkNullCount,
+	ruleNullJoinFilter:         checkNullJoinFilter,
+	rulePerfFunction:           checkPredicateFunction,
+	rulePerfWildcard:           checkLeadingWildcard,
+	rulePerfSelectStar:         checkSelectStar,
+	rulePerfOrColumns:          checkOrColumns,
+	rulePerfOrderSubquery:      checkOrderSubquery,
+	rulePerfCorrelated:         checkCorrelatedSubquery,
+	rulePerfDistinctGroup:      checkDistinctGroup,
+	rulePerfUnionAll:           checkUnionAll,
+	ruleNamingTable:            defaultRule(ruleNamingTable),
+	ruleNamingTableForm:        defaultRule(ruleNamingTableForm),
+	ruleNamingColumn:           defaultRule(ruleNamingColumn),
+	ruleNamingConstraint:       defaultRule(ruleNamingConstraint),
+	ruleNamingIndex:            defaultRule(ruleNamingIndex),
+	ruleNamingCte:              defaultRule(ruleNamingCte),
+	ruleNamingAlias:            defaultRule(ruleNamingAlias),
+	ruleNamingAliasShort:       defaultRule(ruleNamingAliasShort),
+	ruleNamingKeyword:          defaultRule(ruleNamingKeyword),
+	ruleMigrationNotNull:       checkNotNullColumn,
+	ruleMigrationType:          checkColumnType,
+	ruleMigrationDrop:          checkDropObject,
+	ruleMigrationRename:        checkRenameObject,
+	ruleMigrationConcurrent:    defaultRule(ruleMigrationConcurrent),
+	ruleMigrationExists:        checkIfExists,
+	ruleMigrationPrimaryKey:    checkPrimaryKey,
+	ruleMigrationForeignIndex:  checkForeignIndex,
+	ruleSecurityGrantAll:       checkGrantAll,
+	ruleSecurityGrantPublic:    checkGrantPublic,
+	ruleSecurityGrantOption:    checkGrantOption,
+	ruleSecurityRevoke:         checkRevokeGrant,
+	ruleSecurityDefiner:        checkSecurityDefiner,
+	ruleSecurityDynamicSql:     checkDynamicSql,
+	ruleSecurityCredentials:    checkCredentials,
+	ruleProcedureUnused:        checkUnusedVariable,
+	ruleProcedureUnassigned:    checkUnassignedVariable,
+	ruleProcedureUnreachable:   checkUnreachable,
+	ruleProcedureWhile:         checkWhileUnchanged,
+	ruleProcedureEmptyIf:       checkEmptyIf,
+	ruleProcedureShadow:        checkShadowedParameter,
+	ruleProcedureOutParam:      checkOutParameter,
+	ruleProcedureTransaction:   checkNestedTransaction,
 }
 
 func GetRuleNames() []string {
@@ -70,24 +170,18 @@ func GetRuleNames() []string {
 	return ns
 }
 
+// getRulesByName gives the rule with the given name or all the rules of the
+// group when the name is the one of a group (alias, cte.columns...)
 func getRulesByName(rule string) ([]registeredRule, error) {
-	var (
-		set   []registeredRule
-		group string
-		parts = strings.Split(rule, ".")
-	)
-	if len(parts) == 3 {
-		fn, ok := allRules[rule]
-		if !ok {
-			return nil, fmt.Errorf("no such rule %s", rule)
-		}
+	var set []registeredRule
+	if fn, ok := allRules[rule]; ok {
 		r := registeredRule{
 			Name: rule,
 			Func: fn,
 		}
 		return append(set, r), nil
 	}
-	group = strings.Join(parts[:len(parts)-1], ".")
+	group := rule + "."
 	for k, fn := range allRules {
 		if !strings.HasPrefix(k, group) {
 			continue
@@ -98,6 +192,9 @@ func getRulesByName(rule string) ([]registeredRule, error) {
 		}
 		set = append(set, r)
 	}
+	if len(set) == 0 {
+		return nil, fmt.Errorf("no such rule %s", rule)
+	}
 	return set, nil
 }
 
@@ -119,6 +216,28 @@ func getDefaultRules() rules.Map[ast.Statement] {
 		ruleSubqueryColsMismatched,
 		ruleInconsistentUseAs,
 		ruleInconsistentUseOrder,
+		ruleSafetyWhereMissing,
+		ruleSafetyWhereAlways,
+		ruleSafetyDestructive,
+		ruleSafetyCartesian,
+		ruleSafetyMergeMatched,
+		ruleNullCompare,
+		ruleNullNotIn,
+		ruleNullJoinFilter,
+		rulePerfFunction,
+		rulePerfWildcard,
+		rulePerfSelectStar,
+		rulePerfOrderSubquery,
+		rulePerfDistinctGroup,
+		ruleMigrationNotNull,
+		ruleSecurityGrantPublic,
+		ruleSecurityDynamicSql,
+		ruleSecurityCredentials,
+		ruleProcedureUnused,
+		ruleProcedureUnassigned,
+		ruleProcedureUnreachable,
+		ruleProcedureEmptyIf,
+		ruleProcedureOutParam,
 	}
 	all := make(rules.Map[ast.Statement])
 	for _, n := range list {
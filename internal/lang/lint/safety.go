@@ -0,0 +1,278 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/midbel/sweet/internal/lang/ast"
+	"github.com/midbel/sweet/internal/rules"
+)
+
+// migrationMarker is the comment marking a statement as part of a migration
+// where destructive statements are expected
+const migrationMarker = "sweet:migration"
+
+func checkWhereMissing(stmt ast.Statement) ([]rules.LintMessage, error) {
+	var list []rules.LintMessage
+	ast.Inspect(stmt, func(stmt ast.Statement, _ ast.Path) bool {
+		switch stmt := stmt.(type) {
+		case ast.MatchStatement:
+			return false
+		case ast.UpdateStatement:
+			if stmt.Where == nil {
+				list = append(list, whereMissing(stmt, "update"))
+			}
+		case ast.DeleteStatement:
+			if stmt.Where == nil {
+				list = append(list, whereMissing(stmt, "delete"))
+			}
+		}
+		return true
+	})
+	return list, nil
+}
+
+func checkWhereAlways(stmt ast.Statement) ([]rules.LintMessage, error) {
+	var list []rules.LintMessage
+	ast.Inspect(stmt, func(stmt ast.Statement, _ ast.Path) bool {
+		switch stmt := stmt.(type) {
+		case ast.UpdateStatement:
+			if isAlwaysTrue(stmt.Where) {
+				list = append(list, whereAlways(stmt.Where, "update"))
+			}
+		case ast.DeleteStatement:
+			if isAlwaysTrue(stmt.Where) {
+				list = append(list, whereAlways(stmt.Where, "delete"))
+			}
+		}
+		return true
+	})
+	return list, nil
+}
+
+// isAlwaysTrue reports whether the given condition is true for every row:
+// TRUE, 1 = 1, x = x or a disjunction with one of them
+func isAlwaysTrue(stmt ast.Statement) bool {
+	switch s := stmt.(type) {
+	case ast.Group:
+		return isAlwaysTrue(s.Statement)
+	case ast.Value:
+		return s.True()
+	case ast.Binary:
+		switch s.Op {
+		case "OR":
+			return isAlwaysTrue(s.Left) || isAlwaysTrue(s.Right)
+		case "AND":
+			return isAlwaysTrue(s.Left) && isAlwaysTrue(s.Right)
+		case "=", "<=", ">=":
+		default:
+			return false
+		}
+		if left, ok := s.Left.(ast.Value); ok {
+			right, ok := s.Right.(ast.Value)
+			return ok && left.Literal == right.Literal && !left.Null()
+		}
+		if left, ok := s.Left.(ast.Name); ok {
+			right, ok := s.Right.(ast.Name)
+			return ok && strings.EqualFold(left.Ident(), right.Ident())
+		}
+		return false
+	default:
+		return false
+	}
+}
+
+func checkDestructive(stmt ast.Statement) ([]rules.LintMessage, error) {
+	if n, ok := stmt.(ast.Node); ok {
+		for _, c := range n.Before {
+			if strings.TrimSpace(c) == migrationMarker {
+				return nil, nil
+			}
+		}
+		stmt = n.Statement
+	}
+	var list []rules.LintMessage
+	switch stmt := stmt.(type) {
+	case ast.TruncateStatement:
+		list = append(list, destructiveStatement(stmt, "truncate"))
+	case ast.DropTableStatement:
+		list = append(list, destructiveStatement(stmt, "drop table"))
+	case ast.DropViewStatement:
+		list = append(list, destructiveStatement(stmt, "drop view"))
+	}
+	return list, nil
+}
+
+func checkCartesianProduct(stmt ast.Statement) ([]rules.LintMessage, error) {
+	return inspect(stmt, selectCartesianProduct)
+}
+
+// selectCartesianProduct checks that every table given in the from clause
+// separated by a comma is linked to the first one by a predicate of the where
+// clause or of the joins.
+func selectCartesianProduct(q ast.SelectStatement) ([]rules.LintMessage, error) {
+	var (
+		tables = make(map[string]string)
+		comma  []ast.Statement
+		preds  = []ast.Statement{q.Where}
+	)
+	for _, t := range q.Tables {
+		if j, ok := t.(ast.Join); ok {
+			preds = append(preds, j.Where)
+			if n := getTableQualifier(j.Table); n != "" {
+				tables[n] = n
+			}
+			continue
+		}
+		n := getTableQualifier(t)
+		if n == "" {
+			return nil, nil
+		}
+		tables[n] = n
+		comma = append(comma, t)
+	}
+	if len(comma) < 2 {
+		return nil, nil
+	}
+	var find func(string) string
+	find = func(n string) string {
+		if p, ok := tables[n]; ok && p != n {
+			tables[n] = find(p)
+			return tables[n]
+		}
+		return n
+	}
+	for _, p := range preds {
+		pairs, ok := getJoinedTables(p)
+		if !ok {
+			return nil, nil
+		}
+		for _, p := range pairs {
+			tables[find(p[0])] = find(p[1])
+		}
+	}
+	var (
+		list  []rules.LintMessage
+		first = find(getTableQualifier(comma[0]))
+	)
+	for _, t := range comma[1:] {
+		if find(getTableQualifier(t)) != first {
+			list = append(list, cartesianProduct(t, getTableQualifier(t)))
+		}
+	}
+	return list, nil
+}
+
+// getJoinedTables gives the pairs of tables compared in the given predicate.
+// It fails when a column of a comparison is not qualified.
+func getJoinedTables(stmt ast.Statement) ([][2]string, bool) {
+	var (
+		list [][2]string
+		ok   = true
+	)
+	ast.Inspect(stmt, func(stmt ast.Statement, _ ast.Path) bool {
+		switch s := stmt.(type) {
+		case ast.SelectStatement:
+			return false
+		case ast.Binary:
+			if s.IsRelation() {
+				return true
+			}
+			left, right := getColumnRefs(s.Left), getColumnRefs(s.Right)
+			for _, x := range left {
+				for _, y := range right {
+					if len(x.Parts) < 2 || len(y.Parts) < 2 {
+						ok = false
+						continue
+					}
+					list = append(list, [2]string{getColumnQualifier(x), getColumnQualifier(y)})
+				}
+			}
+			return false
+		}
+		return true
+	})
+	return list, ok
+}
+
+func getColumnQualifier(n ast.Name) string {
+	return strings.ToLower(n.Parts[len(n.Parts)-2])
+}
+
+func getTableQualifier(stmt ast.Statement) string {
+	switch s := stmt.(type) {
+	case ast.Alias:
+		return strings.ToLower(s.Alias)
+	case ast.Name:
+		return strings.ToLower(s.Name())
+	default:
+		return ""
+	}
+}
+
+func checkMergeMatched(stmt ast.Statement) ([]rules.LintMessage, error) {
+	return inspect(stmt, mergeMatched)
+}
+
+func mergeMatched(stmt ast.MergeStatement) ([]rules.LintMessage, error) {
+	var list []rules.LintMessage
+	if isAlwaysTrue(stmt.Join) {
+		list = append(list, mergeUnconditional(stmt.Join, "on clause always true"))
+	}
+	for _, a := range stmt.Actions {
+		m, ok := a.(ast.MatchStatement)
+		if !ok || m.Condition != nil {
+			continue
+		}
+		switch m.Statement.(type) {
+		case ast.UpdateStatement, ast.DeleteStatement:
+			list = append(list, mergeUnconditional(stmt, "when matched without condition"))
+		}
+	}
+	return list, nil
+}
+
+func whereMissing(stmt ast.Statement, kw string) rules.LintMessage {
+	msg := rules.LintMessage{
+		Severity: rules.Error,
+		Message:  fmt.Sprintf("%s without where clause affects all rows", kw),
+		Rule:     ruleSafetyWhereMissing,
+	}
+	return locate(stmt, msg)
+}
+
+func whereAlways(stmt ast.Statement, kw string) rules.LintMessage {
+	msg := rules.LintMessage{
+		Severity: rules.Error,
+		Message:  fmt.Sprintf("%s with a where clause always true affects all rows", kw),
+		Rule:     ruleSafetyWhereAlways,
+	}
+	return locate(stmt, msg)
+}
+
+func destructiveStatement(stmt ast.Statement, kw string) rules.LintMessage {
+	msg := rules.LintMessage{
+		Severity: rules.Warning,
+		Message:  fmt.Sprintf("%s outside of a migration (mark it with -- %s)", kw, migrationMarker),
+		Rule:     ruleSafetyDestructive,
+	}
+	return locate(stmt, msg)
+}
+
+func cartesianProduct(stmt ast.Statement, table string) rules.LintMessage {
+	msg := rules.LintMessage{
+		Severity: rules.Error,
+		Message:  fmt.Sprintf("%s: no join predicate, cartesian product", table),
+		Rule:     ruleSafetyCartesian,
+	}
+	return locate(stmt, msg)
+}
+
+func mergeUnconditional(stmt ast.Statement, reason string) rules.LintMessage {
+	msg := rules.LintMessage{
+		Severity: rules.Warning,
+		Message:  fmt.Sprintf("merge: %s", reason),
+		Rule:     ruleSafetyMergeMatched,
+	}
+	return locate(stmt, msg)
+}
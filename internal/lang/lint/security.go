@@ -0,0 +1,337 @@
+package lint
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/midbel/sweet/internal/lang/ast"
+	"github.com/midbel/sweet/internal/ora"
+	"github.com/midbel/sweet/internal/rules"
+)
+
+// credentialColumns are the parts of the names of the columns holding
+// passwords, tokens or any other secrets
+var credentialColumns = []string{
+	"password",
+	"passwd",
+	"pwd",
+	"secret",
+	"token",
+	"apikey",
+	"api_key",
+}
+
+const publicRole = "public"
+
+func checkGrantAll(stmt ast.Statement) ([]rules.LintMessage, error) {
+	return inspect(stmt, func(stmt ast.GrantStatement) ([]rules.LintMessage, error) {
+		if len(stmt.Privileges) > 0 {
+			return nil, nil
+		}
+		return makeArray(grantAllPrivileges(stmt)), nil
+	})
+}
+
+func checkGrantPublic(stmt ast.Statement) ([]rules.LintMessage, error) {
+	return inspect(stmt, func(stmt ast.GrantStatement) ([]rules.LintMessage, error) {
+		ok := slices.ContainsFunc(stmt.Users, func(u string) bool {
+			return strings.EqualFold(u, publicRole)
+		})
+		if !ok {
+			return nil, nil
+		}
+		return makeArray(grantToPublic(stmt)), nil
+	})
+}
+
+func checkGrantOption(stmt ast.Statement) ([]rules.LintMessage, error) {
+	return inspect(stmt, func(stmt ast.GrantStatement) ([]rules.LintMessage, error) {
+		if !stmt.GrantOption {
+			return nil, nil
+		}
+		return makeArray(grantWithOption(stmt)), nil
+	})
+}
+
+// checkRevokeGrant checks that the privileges revoked by the script have been
+// granted before on the same object to the same roles
+func checkRevokeGrant(stmt ast.Statement) ([]rules.LintMessage, error) {
+	s, ok := stmt.(script)
+	if !ok {
+		return nil, nil
+	}
+	var (
+		list   []rules.LintMessage
+		grants []ast.GrantStatement
+	)
+	for _, stmt := range s.statements {
+		switch stmt := unwrapNode(stmt).(type) {
+		case ast.GrantStatement:
+			grants = append(grants, stmt)
+		case ast.RevokeStatement:
+			ok := slices.ContainsFunc(grants, func(g ast.GrantStatement) bool {
+				return isRevokeOf(stmt, g)
+			})
+			if !ok {
+				list = append(list, revokeWithoutGrant(stmt))
+			}
+		}
+	}
+	return list, nil
+}
+
+// isRevokeOf reports whether every privilege and role of the revoke have been
+// given by the grant. A grant of all privileges gives every privilege.
+func isRevokeOf(revoke ast.RevokeStatement, grant ast.GrantStatement) bool {
+	if !strings.EqualFold(revoke.Object, grant.Object) {
+		return false
+	}
+	contains := func(list, others []string) bool {
+		for _, o := range others {
+			ok := slices.ContainsFunc(list, func(str string) bool {
+				return strings.EqualFold(str, o)
+			})
+			if !ok {
+				return false
+			}
+		}
+		return true
+	}
+	if !contains(grant.Users, revoke.Users) {
+		return false
+	}
+	if len(grant.Privileges) == 0 {
+		return true
+	}
+	return len(revoke.Privileges) > 0 && contains(grant.Privileges, revoke.Privileges)
+}
+
+func checkSecurityDefiner(stmt ast.Statement) ([]rules.LintMessage, error) {
+	return inspect(stmt, func(stmt ast.CreateProcedureStatement) ([]rules.LintMessage, error) {
+		if stmt.Security != "DEFINER" {
+			return nil, nil
+		}
+		return makeArray(securityDefiner(stmt)), nil
+	})
+}
+
+func checkDynamicSql(stmt ast.Statement) ([]rules.LintMessage, error) {
+	return enterScope(nil).dynamicSql(stmt), nil
+}
+
+// scope holds the variables declared by a procedure, a block or a package. A
+// variable is dynamic when it holds the result of a concatenation.
+type scope struct {
+	parent  *scope
+	dynamic map[string]bool
+	text    map[string]bool
+}
+
+func enterScope(parent *scope) *scope {
+	return &scope{
+		parent:  parent,
+		dynamic: make(map[string]bool),
+		text:    make(map[string]bool),
+	}
+}
+
+// dynamicSql checks the statements executed in stmt that are built by
+// concatenating strings, either directly or through a variable. The variables
+// declared by the procedures and blocks found in stmt are only visible in them.
+func (s *scope) dynamicSql(stmt ast.Statement) []rules.LintMessage {
+	var (
+		list []rules.LintMessage
+		root = true
+	)
+	ast.Inspect(stmt, func(stmt ast.Statement, _ ast.Path) bool {
+		first := root
+		root = false
+		switch stmt := stmt.(type) {
+		case ast.CreateProcedureStatement, ora.Block, ora.CreatePackageStatement:
+			if first {
+				return true
+			}
+			list = append(list, enterScope(s).dynamicSql(stmt)...)
+			return false
+		case ast.ProcedureParameter:
+			s.declare(stmt.Name, stmt.Type, nil)
+		case ast.Declare:
+			s.declare(stmt.Ident, stmt.Type, stmt.Value)
+		case ast.Set:
+			s.assign(stmt.Ident, stmt.Expr)
+		case ast.ExecuteStatement:
+			if s.isConcat(stmt.Statement) {
+				list = append(list, dynamicSqlConcat(stmt))
+			}
+			return false
+		}
+		return true
+	})
+	return list
+}
+
+func (s *scope) declare(ident string, typ ast.Type, value ast.Statement) {
+	ident = strings.ToLower(ident)
+	s.dynamic[ident] = s.isConcat(value)
+	s.text[ident] = isTextType(typ) || s.isText(value)
+}
+
+func (s *scope) assign(ident string, value ast.Statement) {
+	ident = strings.ToLower(ident)
+	for e := s; e != nil; e = e.parent {
+		if _, ok := e.dynamic[ident]; ok {
+			e.dynamic[ident] = s.isConcat(value)
+			return
+		}
+	}
+	s.dynamic[ident] = s.isConcat(value)
+}
+
+// lookup gives the scope where the given variable is declared
+func (s *scope) lookup(ident string) *scope {
+	ident = strings.ToLower(ident)
+	for e := s; e != nil; e = e.parent {
+		if _, ok := e.dynamic[ident]; ok {
+			return e
+		}
+	}
+	return nil
+}
+
+// isConcat reports whether the given expression concatenates strings or is a
+// variable holding the result of a concatenation. The + operator is only taken
+// as a concatenation when one of its operands is a string.
+func (s *scope) isConcat(stmt ast.Statement) bool {
+	switch e := unwrapGroup(stmt).(type) {
+	case ast.Binary:
+		if e.Op == "||" || (e.Op == "+" && (s.isText(e.Left) || s.isText(e.Right))) {
+			return true
+		}
+		return s.isConcat(e.Left) || s.isConcat(e.Right)
+	case ast.Call:
+		return strings.EqualFold(e.GetIdent(), "concat") || strings.EqualFold(e.GetIdent(), "format")
+	case ast.Name:
+		v := s.lookup(e.Ident())
+		return v != nil && v.dynamic[strings.ToLower(e.Ident())]
+	default:
+		return false
+	}
+}
+
+// isText reports whether the given expression gives a string
+func (s *scope) isText(stmt ast.Statement) bool {
+	switch e := unwrapGroup(stmt).(type) {
+	case ast.Value:
+		_, err := strconv.ParseFloat(e.Literal, 64)
+		return err != nil && !e.Constant()
+	case ast.Name:
+		ident := strings.ToLower(e.Ident())
+		v := s.lookup(ident)
+		return v != nil && (v.text[ident] || v.dynamic[ident])
+	case ast.Binary, ast.Call:
+		return s.isConcat(e)
+	default:
+		return false
+	}
+}
+
+func isTextType(typ ast.Type) bool {
+	name := strings.ToUpper(typ.Name)
+	return strings.Contains(name, "CHAR") || strings.Contains(name, "TEXT") || strings.Contains(name, "CLOB")
+}
+
+func checkCredentials(stmt ast.Statement) ([]rules.LintMessage, error) {
+	return inspect(stmt, hardcodedCredential)
+}
+
+// hardcodedCredential checks the comparisons of a column holding secrets with
+// a literal value
+func hardcodedCredential(stmt ast.Binary) ([]rules.LintMessage, error) {
+	if !isComparison(stmt.Op) {
+		return nil, nil
+	}
+	check := func(left, right ast.Statement) bool {
+		n, ok := left.(ast.Name)
+		if !ok || len(n.Parts) == 0 || !isCredentialColumn(n.Parts[len(n.Parts)-1]) {
+			return false
+		}
+		v, ok := right.(ast.Value)
+		return ok && !v.Constant()
+	}
+	if check(stmt.Left, stmt.Right) || check(stmt.Right, stmt.Left) {
+		return makeArray(credentialLiteral(stmt)), nil
+	}
+	return nil, nil
+}
+
+func isCredentialColumn(name string) bool {
+	name = strings.ToLower(name)
+	return slices.ContainsFunc(credentialColumns, func(str string) bool {
+		return strings.Contains(name, str)
+	})
+}
+
+func grantAllPrivileges(stmt ast.GrantStatement) rules.LintMessage {
+	msg := rules.LintMessage{
+		Severity: rules.Warning,
+		Message:  fmt.Sprintf("%s: grant all privileges (grant only the privileges needed)", stmt.Object),
+		Rule:     ruleSecurityGrantAll,
+	}
+	return locate(stmt, msg)
+}
+
+func grantToPublic(stmt ast.GrantStatement) rules.LintMessage {
+	msg := rules.LintMessage{
+		Severity: rules.Error,
+		Message:  fmt.Sprintf("%s: privileges granted to every role with public", stmt.Object),
+		Rule:     ruleSecurityGrantPublic,
+	}
+	return locate(stmt, msg)
+}
+
+func grantWithOption(stmt ast.GrantStatement) rules.LintMessage {
+	msg := rules.LintMessage{
+		Severity: rules.Warning,
+		Message:  fmt.Sprintf("%s: with grant option lets the grantees give the privileges to other roles", stmt.Object),
+		Rule:     ruleSecurityGrantOption,
+	}
+	return locate(stmt, msg)
+}
+
+func revokeWithoutGrant(stmt ast.RevokeStatement) rules.LintMessage {
+	msg := rules.LintMessage{
+		Severity: rules.Warning,
+		Message:  fmt.Sprintf("%s: revoke does not match a previous grant", stmt.Object),
+		Rule:     ruleSecurityRevoke,
+	}
+	return locate(stmt, msg)
+}
+
+func securityDefiner(stmt ast.CreateProcedureStatement) rules.LintMessage {
+	msg := rules.LintMessage{
+		Severity: rules.Warning,
+		Message:  "procedure runs with the privileges of its owner (security definer)",
+		Rule:     ruleSecurityDefiner,
+	}
+	return locate(stmt, msg)
+}
+
+func dynamicSqlConcat(stmt ast.ExecuteStatement) rules.LintMessage {
+	msg := rules.LintMessage{
+		Severity: rules.Error,
+		Message:  "statement executed is built by concatenation (use parameters to avoid sql injection)",
+		Rule:     ruleSecurityDynamicSql,
+	}
+	return locate(stmt, msg)
+}
+
+func credentialLiteral(stmt ast.Statement) rules.LintMessage {
+	msg := rules.LintMessage{
+		Severity: rules.Error,
+		Message:  "credential compared with a literal value (use a parameter)",
+		Rule:     ruleSecurityCredentials,
+	}
+	return locate(stmt, msg)
+}
@@ -0,0 +1,145 @@
+package lint
+
+import (
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/midbel/sweet/internal/rules"
+	"github.com/midbel/sweet/internal/token"
+)
+
+const (
+	directivePrefix   = "sweet:"
+	directiveNextLine = "disable-next-line"
+	directiveDisable  = "disable"
+	directiveEnable   = "enable"
+	directiveFile     = "disable-file"
+)
+
+// suppression silences the messages of the rules matching its pattern found
+// between two lines. An end of zero means the end of the file.
+type suppression struct {
+	rule  string
+	start int
+	end   int
+}
+
+func (s suppression) match(msg rules.LintMessage) bool {
+	if msg.Position.Line < s.start || (s.end > 0 && msg.Position.Line > s.end) {
+		return false
+	}
+	return matchRule(s.rule, msg.Rule)
+}
+
+// suppressions are the suppressions given by the comments of a file. The
+// directives opened by a disable are kept apart until their enable is found.
+type suppressions struct {
+	file string
+	list []suppression
+	open []suppression
+}
+
+// newSuppressions gives the suppressions of the input read by r. The name of
+// the input is used to keep the messages of the included files.
+func newSuppressions(r io.Reader) *suppressions {
+	var sups suppressions
+	if n, ok := r.(interface{ Name() string }); ok {
+		sups.file = n.Name()
+	}
+	return &sups
+}
+
+// Add collects the sweet: directive found in the given comments:
+//
+//	-- sweet:disable-next-line alias.missing
+//	-- sweet:disable alias.* ... -- sweet:enable
+//	-- sweet:disable-file
+//
+// A directive without rules applies to every rule.
+func (s *suppressions) Add(comments []token.Token) {
+	for _, tok := range comments {
+		kind, patterns, ok := parseDirective(tok.Literal)
+		if !ok {
+			continue
+		}
+		line := tok.Position.Line
+		switch kind {
+		case directiveNextLine:
+			for _, p := range patterns {
+				s.list = append(s.list, suppression{rule: p, start: line + 1, end: line + 1})
+			}
+		case directiveFile:
+			for _, p := range patterns {
+				s.list = append(s.list, suppression{rule: p})
+			}
+		case directiveDisable:
+			for _, p := range patterns {
+				s.open = append(s.open, suppression{rule: p, start: line})
+			}
+		case directiveEnable:
+			s.open = slices.DeleteFunc(s.open, func(sup suppression) bool {
+				if patterns[0] != "" && !slices.Contains(patterns, sup.rule) {
+					return false
+				}
+				sup.end = line
+				s.list = append(s.list, sup)
+				return true
+			})
+		}
+	}
+}
+
+// parseDirective gives the kind and the rule patterns of a sweet: directive.
+// The patterns are separated by blanks or commas.
+func parseDirective(comment string) (string, []string, bool) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(comment), directivePrefix)
+	if !ok {
+		return "", nil, false
+	}
+	fields := strings.FieldsFunc(rest, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+	if len(fields) == 0 {
+		return "", nil, false
+	}
+	switch kind := strings.ToLower(fields[0]); kind {
+	case directiveNextLine, directiveDisable, directiveEnable, directiveFile:
+		patterns := fields[1:]
+		if len(patterns) == 0 {
+			patterns = append(patterns, "")
+		}
+		for i := range patterns {
+			patterns[i] = strings.TrimSuffix(strings.ToLower(patterns[i]), "*")
+			patterns[i] = strings.TrimSuffix(patterns[i], ".")
+		}
+		return kind, patterns, true
+	default:
+		return "", nil, false
+	}
+}
+
+// Filter removes the messages silenced by the directives of the file. The
+// messages of other files, such as included ones, are kept.
+func (s *suppressions) Filter(list []rules.LintMessage) []rules.LintMessage {
+	if len(s.list) == 0 && len(s.open) == 0 {
+		return list
+	}
+	match := func(msg rules.LintMessage) func(suppression) bool {
+		return func(sup suppression) bool {
+			return sup.match(msg)
+		}
+	}
+	return slices.DeleteFunc(list, func(msg rules.LintMessage) bool {
+		if msg.File != s.file {
+			return false
+		}
+		return slices.ContainsFunc(s.list, match(msg)) || slices.ContainsFunc(s.open, match(msg))
+	})
+}
+
+// matchRule reports whether the rule is the one given by the pattern or is a
+// rule of the group given by the pattern. An empty pattern matches every rule.
+func matchRule(pattern, rule string) bool {
+	return pattern == "" || rule == pattern || strings.HasPrefix(rule, pattern+".")
+}
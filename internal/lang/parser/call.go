@@ -40,3 +40,31 @@ func (p *Parser) ParseCall() (ast.Statement, error) {
 	p.Next()
 	return stmt, err
 }
+
+func (p *Parser) ParseExecute() (ast.Statement, error) {
+	var (
+		stmt ast.ExecuteStatement
+		err  error
+	)
+	stmt.Immediate = p.IsKeyword("EXECUTE IMMEDIATE")
+	p.Next()
+	if stmt.Statement, err = p.StartExpression(); err != nil {
+		return nil, err
+	}
+	if !p.IsKeyword("USING") {
+		return stmt, nil
+	}
+	p.Next()
+	for {
+		arg, err := p.StartExpression()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Using = append(stmt.Using, arg)
+		if !p.Is(token.Comma) {
+			break
+		}
+		p.Next()
+	}
+	return stmt, nil
+}
@@ -0,0 +1,39 @@
+package parser
+
+import (
+	"github.com/midbel/sweet/internal/lang"
+	"github.com/midbel/sweet/internal/scanner"
+)
+
+func init() {
+	lang.Register(lang.Dialect{
+		Name:      lang.DefaultDialect,
+		Keywords:  lang.GetKeywords(),
+		Formatter: lang.GetFormatter(),
+		Folding:   lang.FoldUpper,
+		NewParser: newParser,
+	})
+	lang.Register(lang.Dialect{
+		Name:     "postgres",
+		Aliases:  []string{"pg", "pgsql", "postgresql"},
+		Keywords: lang.GetKeywords(),
+		Tokenizers: []scanner.Tokenizer{
+			scanner.EscapedString('E'),
+		},
+		Formatter: lang.GetFormatter(),
+		Rules:     []string{"migration.index.concurrently"},
+		Folding:   lang.FoldLower,
+		NewParser: newParser,
+	})
+	lang.Register(lang.Dialect{
+		Name:      "sqlite",
+		Aliases:   []string{"lite"},
+		Keywords:  lang.GetKeywords(),
+		Formatter: lang.GetFormatter(),
+		NewParser: newParser,
+	})
+}
+
+func newParser(scan *scanner.Scanner) (lang.Parser, error) {
+	return ParseWithScanner(scan)
+}
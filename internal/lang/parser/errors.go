@@ -2,8 +2,10 @@ package parser
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
+	"github.com/midbel/sweet/internal/lang"
 	"github.com/midbel/sweet/internal/token"
 )
 
@@ -19,28 +21,116 @@ const (
 	unknownOperator    = "unknown operator"
 	macroOptionUnknown = "macro option unknown"
 	syntaxError        = "syntax error"
+	statementExpected  = "keyword expected to start statement"
+	unsupportedKeyword = "unsupported keyword given"
 )
 
+var reasonCodes = map[string]string{
+	defaultReason:      lang.CodeSyntax,
+	syntaxError:        lang.CodeSyntax,
+	missingOpenParen:   lang.CodeMissingParen,
+	missingCloseParen:  lang.CodeMissingParen,
+	keywordAfterComma:  lang.CodeUnexpectedKeyword,
+	missingOperator:    lang.CodeUnknownOperator,
+	unknownOperator:    lang.CodeUnknownOperator,
+	identExpected:      lang.CodeIdentExpected,
+	valueExpected:      lang.CodeValueExpected,
+	missingEol:         lang.CodeMissingEol,
+	macroOptionUnknown: lang.CodeMacro,
+	statementExpected:  lang.CodeKeywordExpected,
+	unsupportedKeyword: lang.CodeUnsupportedKeyword,
+}
+
 func keywordExpected(kw ...string) string {
 	return fmt.Sprintf("expected %s keyword(s)", strings.Join(kw, "|"))
 }
 
-type ParseError struct {
-	token.Token
-	Reason  string
-	Context string
-	Query   string
+// describe gives the code of an error and the list of tokens that were
+// expected from its reason. Reasons built by keywordExpected, including the
+// ones written in the same form by the dialects, give the keywords they list.
+func describe(reason string) (string, []string) {
+	if code, ok := reasonCodes[reason]; ok {
+		return code, nil
+	}
+	if str, ok := strings.CutPrefix(reason, "expected "); ok {
+		if str, ok = strings.CutSuffix(str, " keyword(s)"); ok {
+			return lang.CodeKeywordExpected, strings.Split(str, "|")
+		}
+	}
+	return lang.CodeSyntax, nil
 }
 
-func (e ParseError) Literal() string {
-	return e.Token.Literal
+// suggest looks for the keyword that the user most likely wanted to write
+// instead of the given token. The expected keywords are preferred when known.
+func suggest(tok token.Token, candidates []string) string {
+	if tok.Type != token.Ident && tok.Type != token.Keyword {
+		return ""
+	}
+	var (
+		str = strings.ToUpper(tok.Literal)
+		all []string
+	)
+	for _, kw := range lang.GetKeywords() {
+		all = append(all, strings.ToUpper(strings.Join(kw, " ")))
+	}
+	for _, c := range all {
+		if strings.HasPrefix(c, str+" ") {
+			return fmt.Sprintf("did you mean %s?", c)
+		}
+	}
+	if len(str) < 3 {
+		return ""
+	}
+	if len(candidates) == 0 {
+		candidates = all
+	}
+	var (
+		best  string
+		limit = max(1, len(str)/3)
+	)
+	for _, c := range candidates {
+		if d := distance(str, c); d > 0 && d <= limit {
+			best, limit = c, d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf("did you mean %s?", best)
 }
 
-func (e ParseError) Position() token.Position {
-	return e.Token.Position
+// distance computes the levenshtein distance between two strings
+func distance(str1, str2 string) int {
+	var (
+		fst  = []rune(str1)
+		snd  = []rune(str2)
+		prev = make([]int, len(snd)+1)
+		curr = make([]int, len(snd)+1)
+	)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := range fst {
+		curr[0] = i + 1
+		for j := range snd {
+			cost := 1
+			if fst[i] == snd[j] {
+				cost = 0
+			}
+			curr[j+1] = min(prev[j+1]+1, curr[j]+1, prev[j]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(snd)]
 }
 
-func (e ParseError) Error() string {
-	pos := e.Token.Position
-	return fmt.Sprintf("[%s] at %d:%d, %s", e.Context, pos.Line, pos.Column, e.Reason)
+func (p *Parser) statementKeywords() []string {
+	var list []string
+	for kw := range p.keywords {
+		list = append(list, kw)
+	}
+	slices.Sort(list)
+	return list
 }
+
+type ParseError = lang.ParseError
@@ -6,14 +6,18 @@ import (
 )
 
 func (p *Parser) StartExpression() (ast.Statement, error) {
+	start := p.curr.Position
 	expr, err := p.parseExpression(powLowest)
 	if err != nil {
 		return nil, err
 	}
-	if p.withAlias {
-		return p.ParseAlias(expr)
+	if !p.withAlias {
+		return expr, nil
 	}
-	return expr, nil
+	if expr, err = p.ParseAlias(expr); err != nil {
+		return nil, err
+	}
+	return p.Locate(start, expr), nil
 }
 
 func (p *Parser) stopExpression(pow int) bool {
@@ -32,20 +36,23 @@ func (p *Parser) stopExpression(pow int) bool {
 func (p *Parser) parseExpression(pow int) (ast.Statement, error) {
 	fn, err := p.getPrefixExpr()
 	if err != nil {
-		return nil, err
+		return nil, p.Unexpected("expression", valueExpected)
 	}
+	start := p.curr.Position
 	left, err := fn()
 	if err != nil {
 		return nil, err
 	}
+	left = p.Locate(start, left)
 	for !p.stopExpression(pow) {
 		fn, err := p.getInfixExpr()
 		if err != nil {
-			return nil, err
+			return nil, p.Unexpected("expression", unknownOperator)
 		}
 		if left, err = fn(left); err != nil {
 			return nil, err
 		}
+		left = p.Locate(start, left)
 	}
 	return left, nil
 }
@@ -179,11 +186,12 @@ func (p *Parser) parseIn(ident ast.Statement) (ast.Statement, error) {
 	if p.Is(token.Lparen) && p.PeekIs(token.Keyword) && p.GetPeekLiteral() == "SELECT" {
 		in.Value, err = p.parseExpression(powLowest)
 	} else if p.Is(token.Lparen) {
-		p.Next()
 		var (
-			list ast.List
-			val  ast.Statement
+			list  ast.List
+			val   ast.Statement
+			start = p.curr.Position
 		)
+		p.Next()
 		for !p.Done() && !p.Is(token.Rparen) {
 			val, err = p.parseExpression(powLowest)
 			if err != nil {
@@ -204,8 +212,8 @@ func (p *Parser) parseIn(ident ast.Statement) (ast.Statement, error) {
 		if !p.Is(token.Rparen) {
 			return nil, p.Unexpected("in", missingCloseParen)
 		}
-		in.Value = list
 		p.Next()
+		in.Value = p.Locate(start, list)
 	} else {
 		in.Value, err = p.ParseIdentifier()
 	}
@@ -406,7 +414,7 @@ func (p *Parser) parseUnary() (ast.Statement, error) {
 		}
 	case p.IsKeyword("NOT"):
 		p.Next()
-		stmt, err = p.StartExpression()
+		stmt, err = p.parseExpression(powRel)
 		if err != nil {
 			return nil, err
 		}
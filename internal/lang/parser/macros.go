@@ -42,28 +42,31 @@ func (p *Parser) ParseFormatMacro() error {
 	if !p.Is(token.Ident) && !p.Is(token.Number) && !p.Is(token.Literal) && !p.Is(token.Keyword) {
 		return p.Unexpected("format", valueExpected)
 	}
-	value := strings.ToLower(p.GetCurrLiteral())
+	var (
+		value = strings.ToLower(p.GetCurrLiteral())
+		cfg   = p.section("format")
+	)
 	switch key {
 	case "as", "comma", "quote", "compact", "space", "keepspace":
 		v, err := strconv.ParseBool(value)
 		if err != nil {
 			return err
 		}
-		p.Config.Set(key, v)
+		cfg.Set(key, v)
 	case "comment":
-		p.Config.Set(key, value == "keep")
+		cfg.Set(key, value == "keep")
 	case "newline":
-		p.Config.Set("crlf", value == "crlf")
+		cfg.Set("crlf", value == "crlf")
 	case "upperize":
-		p.Config.Add("upperize", value)
+		cfg.Add("upperize", value)
 	case "rewrite":
-		p.Config.Add("rewrite", value)
+		cfg.Add("rewrite", value)
 	case "indent":
 		v, err := strconv.ParseInt(value, 10, 64)
 		if err != nil {
 			return err
 		}
-		p.Config.Set(key, v)
+		cfg.Set(key, v)
 	default:
 		return p.Unexpected("format", macroOptionUnknown)
 	}
@@ -86,9 +89,12 @@ func (p *Parser) ParseLintMacro() error {
 	if !p.Is(token.Ident) && !p.Is(token.Keyword) && !p.Is(token.Number) {
 		return p.Unexpected("lint", identExpected)
 	}
-	val := p.GetCurrLiteral()
+	var (
+		val = p.GetCurrLiteral()
+		cfg = p.section("lint")
+	)
 	if val = strings.ToLower(val); val == "on" || val == "off" {
-		p.Config.Set(rule, val == "on")
+		cfg.Set(rule, val == "on")
 		p.Next()
 		if !p.Is(token.EOL) {
 			return p.Unexpected("lint", missingEol)
@@ -102,11 +108,11 @@ func (p *Parser) ParseLintMacro() error {
 		p.Next()
 	}
 	if p.Is(token.Number) {
-		prio, _ := strconv.Atoi(p.GetCurrLiteral())
+		prio, _ := strconv.ParseInt(p.GetCurrLiteral(), 10, 64)
 		sub.Set("priority", prio)
 		p.Next()
 	}
-	p.Config.Set(rule, sub)
+	cfg.Set(rule, sub)
 	if !p.Is(token.EOL) {
 		return p.Unexpected("macro", missingEol)
 	}
@@ -114,6 +120,17 @@ func (p *Parser) ParseLintMacro() error {
 	return nil
 }
 
+// section gives the settings of the given section, format or lint, creating
+// it on first use
+func (p *Parser) section(name string) *config.Config {
+	if cfg, ok := p.Config.Get(name).(*config.Config); ok {
+		return cfg
+	}
+	cfg := config.Make()
+	p.Config.Set(name, cfg)
+	return cfg
+}
+
 func (p *Parser) ParseIncludeMacro() error {
 	p.Next()
 
@@ -129,12 +146,12 @@ func (p *Parser) ParseIncludeMacro() error {
 	if err != nil {
 		return err
 	}
-	defer r.Close()
-
 	f, err := p.frame.Sub(r)
 	if err != nil {
+		r.Close()
 		return err
 	}
+	f.closer = r
 	p.stack = append(p.stack, p.frame)
 	p.frame = f
 
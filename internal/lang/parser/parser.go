@@ -3,8 +3,10 @@ package parser
 import (
 	"errors"
 	"io"
+	"math"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 
@@ -32,6 +34,14 @@ type Parser struct {
 
 	withAlias bool
 
+	extensions []func()
+
+	recover bool
+	errs    []ParseError
+
+	skipRows bool
+	comments bool
+
 	queries map[string]ast.Statement
 	values  map[string]ast.Statement
 }
@@ -65,6 +75,49 @@ func ParseWithScanner(scan *scanner.Scanner) (*Parser, error) {
 	return &p, p.start()
 }
 
+func (p *Parser) Settings() *config.Config {
+	return p.Config
+}
+
+// SetRecover enables or disables the recovery mode. When enabled, syntax
+// errors are collected and the parser synchronizes on the next clause or
+// statement instead of giving up on the current statement.
+func (p *Parser) SetRecover(recover bool) {
+	p.recover = recover
+}
+
+// SkipRows enables or disables the skipping of the rows of INSERT statements.
+// When enabled, the VALUES of an INSERT are replaced by an ast.SkippedRows.
+func (p *Parser) SkipRows(skip bool) {
+	p.skipRows = skip
+}
+
+func (p *Parser) Errors() []ParseError {
+	return p.errs
+}
+
+// KeepComments enables or disables the collection of the comments given by
+// Comments. When disabled, the comments read before the first statement are
+// dropped and the next ones are not kept.
+func (p *Parser) KeepComments(keep bool) {
+	p.comments = keep
+}
+
+func (p *Parser) Comments() []token.Token {
+	f := p.root()
+	list := f.comments
+	f.comments = nil
+	return list
+}
+
+// root gives the frame of the parsed file when an included file is read
+func (p *Parser) root() *frame {
+	if len(p.stack) > 0 {
+		return p.stack[0]
+	}
+	return p.frame
+}
+
 func (p *Parser) DefineVars(file string) error {
 	r, err := os.Open(file)
 	if err != nil {
@@ -93,12 +146,27 @@ func (p *Parser) start() error {
 	return nil
 }
 
+// Parse gives the next statement of the input. When the input could not be
+// read up to its end, the read error is returned in place of the statement
+// reaching the point where reading stopped, so that a truncated input is never
+// taken as a complete one.
 func (p *Parser) Parse() (ast.Statement, error) {
 	if p.Done() {
+		if err := p.scan.Err(); err != nil {
+			return nil, err
+		}
 		return nil, io.EOF
 	}
+	if f := p.root(); !p.comments && f.keep {
+		f.keep = false
+		f.comments = nil
+	}
 	p.reset()
+	p.scan.Mark(p.curr.Offset)
 	stmt, err := p.parse()
+	if e := p.scan.Err(); e != nil && p.Done() {
+		return nil, e
+	}
 	return stmt, err
 }
 
@@ -116,13 +184,18 @@ func (p *Parser) ParseStatement() (ast.Statement, error) {
 		return nil, io.EOF
 	}
 	if !p.Is(token.Keyword) {
-		return nil, p.Unexpected("statement", "keyword expected to start statement")
+		return nil, p.Unexpected("statement", statementExpected)
 	}
 	fn, ok := p.keywords[p.GetCurrLiteral()]
 	if !ok {
-		return nil, p.Unexpected("statement", "unsupported keyword given")
+		return nil, p.Unexpected("statement", unsupportedKeyword)
+	}
+	start := p.curr.Position
+	stmt, err := fn()
+	if err != nil {
+		return nil, err
 	}
-	return fn()
+	return p.Locate(start, stmt), nil
 }
 
 func (p *Parser) Level() int {
@@ -152,9 +225,13 @@ func (p *Parser) QueryEnds() bool {
 	return p.Is(token.EOL) || p.Done()
 }
 
+// Done reports whether the input has been fully parsed. The frame of an
+// included file is left once it is done, unless reading it failed so that its
+// error can be returned.
 func (p *Parser) Done() bool {
-	if p.frame.Done() {
+	if p.frame.Done() && p.frame.scan.Err() == nil {
 		if n := len(p.stack); n > 0 {
+			p.frame.Close()
 			p.frame = p.stack[n-1]
 			p.stack = p.stack[:n-1]
 		}
@@ -184,17 +261,104 @@ func (p *Parser) parse() (ast.Statement, error) {
 		}
 		return p.Parse()
 	}
-	return p.parseItem(func() (ast.Statement, error) {
-		stmt, err := p.ParseStatement()
-		if err != nil {
-			return nil, err
-		}
-		if !p.Is(token.EOL) {
-			return nil, p.Unexpected("statement", missingEol)
+	var (
+		count     = len(p.errs)
+		start     = p.curr.Offset
+		end       int
+		stmt, err = p.parseItem(func() (ast.Statement, error) {
+			stmt, err := p.ParseStatement()
+			if err != nil {
+				return nil, err
+			}
+			if !p.Is(token.EOL) {
+				return nil, p.Unexpected("statement", missingEol)
+			}
+			end = p.curr.Offset + 1
+			p.Next()
+			return stmt, nil
+		})
+	)
+	if !p.recover {
+		return stmt, err
+	}
+	if err != nil {
+		p.addError(err)
+		for !p.Done() && !p.Is(token.EOL) {
+			p.Next()
 		}
+		end = p.curr.Offset + 1
 		p.Next()
+	}
+	if len(p.errs) == count {
 		return stmt, nil
-	})
+	}
+	for i := count; i < len(p.errs); i++ {
+		p.errs[i].Query, p.errs[i].QueryLine = p.scan.Source(end)
+	}
+	query := strings.TrimSpace(p.scan.Text(start, end))
+	invalid := ast.Invalid{
+		Statement: stmt,
+		Query:     query,
+		Err:       p.errs[count],
+	}
+	return invalid, nil
+}
+
+// RecoverClause is used to continue parsing a statement after an error has
+// been detected in one of its clauses. The tokens are skipped until one of the
+// given keywords or the end of the statement is found. Without recovery mode,
+// the error is returned as is.
+func (p *Parser) RecoverClause(err error, kw ...string) (ast.Statement, error) {
+	if !p.recover || err == nil {
+		return nil, err
+	}
+	p.addError(err)
+	var (
+		done  = p.KwCheck(slices.Clone(kw)...)
+		depth int
+	)
+	for !p.Done() && !p.Is(token.EOL) {
+		if depth == 0 && (done() || (p.Nested() && p.Is(token.Rparen))) {
+			break
+		}
+		switch {
+		case p.Is(token.Lparen):
+			depth++
+		case p.Is(token.Rparen):
+			depth--
+		}
+		p.Next()
+	}
+	stmt := ast.Invalid{
+		Err: err,
+	}
+	return stmt, nil
+}
+
+// Locate sets the span of the given node from the start position up to the
+// last token read
+func (p *Parser) Locate(start token.Position, stmt ast.Statement) ast.Statement {
+	return ast.Locate(stmt, p.SpanFrom(start))
+}
+
+func (p *Parser) SpanFrom(start token.Position) ast.Span {
+	return ast.Span{
+		File:  p.file,
+		Start: start,
+		End:   p.prev.End,
+	}
+}
+
+func (p *Parser) addError(err error) {
+	var perr ParseError
+	if !errors.As(err, &perr) {
+		perr = ParseError{
+			Token:   p.Curr(),
+			Reason:  err.Error(),
+			Context: "statement",
+		}
+	}
+	p.errs = append(p.errs, perr)
 }
 
 func (p *Parser) parseItem(parse ParseFunc) (ast.Statement, error) {
@@ -210,6 +374,7 @@ func (p *Parser) parseItem(parse ParseFunc) (ast.Statement, error) {
 	if node.Statement, err = parse(); err != nil && !errors.Is(err, errDone) {
 		return nil, err
 	}
+	node.Statement = p.Locate(pos, node.Statement)
 	if p.Is(token.Comment) && pos.Column < p.curr.Column {
 		node.After = p.GetCurrLiteral()
 		p.Next()
@@ -262,6 +427,13 @@ func (p *Parser) UnregisterInfix(literal string, kind rune) {
 	p.infix.Unregister(literal, kind)
 }
 
+// ExtendFuncSet registers a function that is called each time the default
+// prefix and infix functions are installed. Dialects use it to add their own
+// operators to expressions.
+func (p *Parser) ExtendFuncSet(fn func()) {
+	p.extensions = append(p.extensions, fn)
+}
+
 func (p *Parser) parseColumnsList() ([]string, error) {
 	if !p.Is(token.Lparen) {
 		return nil, nil
@@ -296,16 +468,41 @@ func (p *Parser) IsKeyword(kw string) bool {
 }
 
 func (p *Parser) Unexpected(ctx, reason string) error {
+	var (
+		tok  = p.Curr()
+		code string
+	)
+	if tok.Type == token.Invalid && tok.Reason != "" {
+		reason = tok.Reason
+		code = lang.CodeInvalidToken
+	}
 	if reason == "" {
 		reason = defaultReason
 	}
 	err := ParseError{
 		Reason:  reason,
-		Token:   p.Curr(),
+		Token:   tok,
+		End:     tok.End,
+		File:    p.file,
 		Context: ctx,
 	}
-	p.restore()
-	err.Query = p.scan.Query()
+	if code == "" {
+		code, err.Expected = describe(reason)
+		candidates := err.Expected
+		if ctx == "statement" && candidates == nil {
+			candidates = p.statementKeywords()
+		}
+		err.Hint = suggest(tok, candidates)
+	}
+	err.Code = code
+	if !p.recover {
+		p.restore()
+	}
+	end := p.curr.Offset
+	if p.Done() {
+		end = math.MaxInt
+	}
+	err.Query, err.QueryLine = p.scan.Source(end)
 	return err
 }
 
@@ -356,6 +553,7 @@ func (p *Parser) setParseFunc() {
 	p.RegisterParseFunc("DELETE FROM", p.ParseDelete)
 	p.RegisterParseFunc("TRUNCATE", p.ParseTruncate)
 	p.RegisterParseFunc("TRUNCATE TABLE", p.ParseTruncate)
+	p.RegisterParseFunc("COPY", p.ParseCopy)
 	p.RegisterParseFunc("UPDATE", p.ParseUpdate)
 	p.RegisterParseFunc("MERGE", p.ParseMerge)
 	p.RegisterParseFunc("MERGE INTO", p.ParseMerge)
@@ -363,6 +561,8 @@ func (p *Parser) setParseFunc() {
 	p.RegisterParseFunc("WITH", p.parseWith)
 	p.RegisterParseFunc("CASE", p.ParseCase)
 	p.RegisterParseFunc("CALL", p.ParseCall)
+	p.RegisterParseFunc("EXECUTE", p.ParseExecute)
+	p.RegisterParseFunc("EXECUTE IMMEDIATE", p.ParseExecute)
 	p.RegisterParseFunc("IF", p.parseIf)
 	p.RegisterParseFunc("WHILE", p.parseWhile)
 	p.RegisterParseFunc("DECLARE", p.ParseDeclare)
@@ -370,12 +570,20 @@ func (p *Parser) setParseFunc() {
 	p.RegisterParseFunc("RETURN", p.parseReturn)
 	p.RegisterParseFunc("BEGIN", p.ParseBegin)
 	p.RegisterParseFunc("START TRANSACTION", p.parseStartTransaction)
+	p.RegisterParseFunc("COMMIT", p.parseCommit)
+	p.RegisterParseFunc("ROLLBACK", p.parseRollback)
+	p.RegisterParseFunc("SAVEPOINT", p.parseSavepoint)
+	p.RegisterParseFunc("RELEASE", p.parseReleaseSavepoint)
+	p.RegisterParseFunc("RELEASE SAVEPOINT", p.parseReleaseSavepoint)
+	p.RegisterParseFunc("ROLLBACK TO SAVEPOINT", p.parseRollbackSavepoint)
 	p.RegisterParseFunc("CREATE VIEW", p.ParseCreateView)
 	p.RegisterParseFunc("CREATE TEMP VIEW", p.ParseCreateView)
 	p.RegisterParseFunc("CREATE TEMPORARY VIEW", p.ParseCreateView)
 	p.RegisterParseFunc("CREATE TABLE", p.ParseCreateTable)
 	p.RegisterParseFunc("CREATE TEMP TABLE", p.ParseCreateTable)
 	p.RegisterParseFunc("CREATE TEMPORARY TABLE", p.ParseCreateTable)
+	p.RegisterParseFunc("CREATE INDEX", p.ParseCreateIndex)
+	p.RegisterParseFunc("CREATE UNIQUE INDEX", p.ParseCreateIndex)
 	p.RegisterParseFunc("CREATE PROCEDURE", p.ParseCreateProcedure)
 	p.RegisterParseFunc("CREATE OR REPLACE PROCEDURE", p.ParseCreateProcedure)
 	p.RegisterParseFunc("ALTER TABLE", p.ParseAlterTable)
@@ -450,6 +658,10 @@ func (p *Parser) setDefaultFuncSet() {
 	prefix.Register("EXISTS", token.Keyword, p.parseExists)
 
 	p.prefix.Push(prefix)
+
+	for _, fn := range p.extensions {
+		fn()
+	}
 }
 
 func (p *Parser) toggleAlias() {
@@ -462,16 +674,25 @@ func (p *Parser) unsetFuncSet() {
 }
 
 type frame struct {
-	scan *scanner.Scanner
+	scan   *scanner.Scanner
+	closer io.Closer
 
 	file string
+	prev token.Token
 	curr token.Token
 	peek token.Token
+
+	// comments are kept by the frame of the parsed file until the parser
+	// knows whether they are needed
+	keep     bool
+	comments []token.Token
 }
 
 func createFrameFromScanner(scan *scanner.Scanner) (*frame, error) {
 	f := &frame{
 		scan: scan,
+		file: scan.File(),
+		keep: true,
 	}
 	f.Next()
 	f.Next()
@@ -491,7 +712,20 @@ func (f *frame) Sub(r io.Reader) (*frame, error) {
 	if err != nil {
 		return nil, err
 	}
-	return createFrameFromScanner(scan)
+	sub, err := createFrameFromScanner(scan)
+	if err == nil {
+		sub.keep = false
+		sub.comments = nil
+	}
+	return sub, err
+}
+
+// Close closes the file read by the frame of an included file
+func (f *frame) Close() error {
+	if f.closer == nil {
+		return nil
+	}
+	return f.closer.Close()
 }
 
 func (f *frame) Base() string {
@@ -523,8 +757,12 @@ func (f *frame) GetPeekType() rune {
 }
 
 func (f *frame) Next() {
+	f.prev = f.curr
 	f.curr = f.peek
 	f.peek = f.scan.Scan()
+	if f.keep && f.peek.Type == token.Comment {
+		f.comments = append(f.comments, f.peek)
+	}
 }
 
 func (f *frame) Done() bool {
@@ -5,10 +5,15 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"testing/iotest"
 
+	"github.com/midbel/sweet/internal/lang"
+	"github.com/midbel/sweet/internal/lang/ast"
 	"github.com/midbel/sweet/internal/lang/parser"
+	"github.com/midbel/sweet/internal/scanner"
 )
 
 func TestParserShouldFail(t *testing.T) {
@@ -28,6 +33,444 @@ func TestParserShouldFail(t *testing.T) {
 	}
 }
 
+func TestParserRecover(t *testing.T) {
+	query := `select a, b from t1 where a = 1;
+select a, from t2 where x == 1 group by a;
+insert into t4 values (1, 2;
+update t6 set a = 1 where b = 2;`
+
+	p, err := parser.ParseWithScanner(mustScan(t, query))
+	if err != nil {
+		t.Fatalf("fail to create parser: %s", err)
+	}
+	p.SetRecover(true)
+
+	var (
+		count   int
+		invalid int
+	)
+	for {
+		stmt, err := p.Parse()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected error in recovery mode: %s", err)
+		}
+		count++
+		if x, ok := stmt.(ast.Invalid); ok {
+			invalid++
+			if x.Query == "" {
+				t.Errorf("invalid statement without query")
+			}
+		}
+	}
+	if count != 4 {
+		t.Errorf("statements count mismatched! want 4, got %d", count)
+	}
+	if invalid != 2 {
+		t.Errorf("invalid statements count mismatched! want 2, got %d", invalid)
+	}
+	if n := len(p.Errors()); n != 3 {
+		t.Errorf("errors count mismatched! want 3, got %d", n)
+	}
+}
+
+func TestParserDiagnostic(t *testing.T) {
+	tests := []struct {
+		Query string
+		Code  string
+		Hint  string
+	}{
+		{Query: "selec a from t;", Code: lang.CodeKeywordExpected, Hint: "did you mean SELECT?"},
+		{Query: "select a from t group a;", Code: lang.CodeMissingEol, Hint: "did you mean GROUP BY?"},
+		{Query: "select 1e+ from t;", Code: lang.CodeInvalidToken},
+	}
+	for _, c := range tests {
+		p, err := parser.ParseWithScanner(mustScan(t, c.Query))
+		if err != nil {
+			t.Fatalf("fail to create parser: %s", err)
+		}
+		_, err = p.Parse()
+
+		var perr lang.ParseError
+		if !errors.As(err, &perr) {
+			t.Errorf("%s: expected parse error, got %v", c.Query, err)
+			continue
+		}
+		if perr.Code != c.Code {
+			t.Errorf("%s: code mismatched! want %s, got %s", c.Query, c.Code, perr.Code)
+		}
+		if perr.Hint != c.Hint {
+			t.Errorf("%s: hint mismatched! want %q, got %q", c.Query, c.Hint, perr.Hint)
+		}
+		if perr.End.Offset < perr.Position().Offset {
+			t.Errorf("%s: invalid span %d-%d", c.Query, perr.Position().Offset, perr.End.Offset)
+		}
+	}
+}
+
+func TestParserSpan(t *testing.T) {
+	query := `select a,
+  t.b as c
+from t
+where a = 1;`
+
+	p, err := parser.ParseWithScanner(mustScan(t, query))
+	if err != nil {
+		t.Fatalf("fail to create parser: %s", err)
+	}
+	stmt, err := p.Parse()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	sel, ok := stmt.(ast.SelectStatement)
+	if !ok {
+		t.Fatalf("select expected! got %T", stmt)
+	}
+	tests := []struct {
+		Node  ast.Statement
+		Start string
+		End   string
+	}{
+		{Node: sel, Start: "1:1", End: "4:12"},
+		{Node: sel.Columns[0], Start: "1:8", End: "1:9"},
+		{Node: sel.Columns[1], Start: "2:3", End: "2:11"},
+		{Node: sel.Tables[0], Start: "3:6", End: "3:7"},
+		{Node: sel.Where, Start: "4:7", End: "4:12"},
+	}
+	for _, c := range tests {
+		span := ast.SpanOf(c.Node)
+		if got := span.Start.String(); got != c.Start {
+			t.Errorf("%T: start mismatched! want %s, got %s", c.Node, c.Start, got)
+		}
+		if got := span.End.String(); got != c.End {
+			t.Errorf("%T: end mismatched! want %s, got %s", c.Node, c.End, got)
+		}
+	}
+}
+
+func TestParserWalk(t *testing.T) {
+	query := `delete from t where a in (select a from u where b = 1);`
+
+	p, err := parser.ParseWithScanner(mustScan(t, query))
+	if err != nil {
+		t.Fatalf("fail to create parser: %s", err)
+	}
+	stmt, err := p.Parse()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var count int
+	ast.Inspect(stmt, func(n ast.Statement, path ast.Path) bool {
+		if _, ok := n.(ast.SelectStatement); !ok {
+			return true
+		}
+		count++
+		if !ast.Inside[ast.DeleteStatement](path) {
+			t.Errorf("select should be inside delete")
+		}
+		return true
+	})
+	if count != 1 {
+		t.Fatalf("subquery not visited! want 1 select, got %d", count)
+	}
+
+	rename := func(n ast.Statement) ast.Statement {
+		if v, ok := n.(ast.Value); ok && v.Literal == "1" {
+			v.Literal = "2"
+			return v
+		}
+		return n
+	}
+	var (
+		other = ast.Rewrite(stmt, rename)
+		want  []string
+		got   []string
+	)
+	collect := func(list *[]string) func(ast.Statement, ast.Path) bool {
+		return func(n ast.Statement, _ ast.Path) bool {
+			if v, ok := n.(ast.Value); ok {
+				*list = append(*list, v.Literal)
+			}
+			return true
+		}
+	}
+	ast.Inspect(stmt, collect(&want))
+	ast.Inspect(other, collect(&got))
+	if len(want) != 1 || want[0] != "1" {
+		t.Errorf("original statement modified: %v", want)
+	}
+	if len(got) != 1 || got[0] != "2" {
+		t.Errorf("value not rewritten: %v", got)
+	}
+}
+
+func TestParserJSON(t *testing.T) {
+	query := `with x(a) as (select a from t)
+select x.a, cast(b as int), case when c > 0 then 'p' else 'n' end
+from x join u on x.a = u.a and u.b in (1, 2)
+where d is not null
+order by x.a desc;`
+
+	p, err := parser.ParseWithScanner(mustScan(t, query))
+	if err != nil {
+		t.Fatalf("fail to create parser: %s", err)
+	}
+	stmt, err := p.Parse()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	data, err := ast.Marshal(stmt)
+	if err != nil {
+		t.Fatalf("fail to encode statement: %s", err)
+	}
+	got, err := ast.Unmarshal(data)
+	if err != nil {
+		t.Fatalf("fail to decode statement: %s", err)
+	}
+	if !reflect.DeepEqual(stmt, got) {
+		t.Errorf("statement mismatched after decoding!\nwant %+v\ngot  %+v", stmt, got)
+	}
+	if _, err := ast.Decode(strings.NewReader(`{"version": 0, "statements": []}`)); err == nil {
+		t.Errorf("unsupported version should be rejected")
+	}
+}
+
+func TestParserJSONInvalid(t *testing.T) {
+	p, err := parser.ParseWithScanner(mustScan(t, "select a, from t where x == 1;"))
+	if err != nil {
+		t.Fatalf("fail to create parser: %s", err)
+	}
+	p.SetRecover(true)
+
+	stmt, err := p.Parse()
+	if err != nil {
+		t.Fatalf("unexpected error in recovery mode: %s", err)
+	}
+	want, ok := stmt.(ast.Invalid)
+	if !ok {
+		t.Fatalf("invalid statement expected! got %T", stmt)
+	}
+	data, err := ast.Marshal(stmt)
+	if err != nil {
+		t.Fatalf("fail to encode statement: %s", err)
+	}
+	other, err := ast.Unmarshal(data)
+	if err != nil {
+		t.Fatalf("fail to decode statement: %s", err)
+	}
+	got, ok := other.(ast.Invalid)
+	if !ok {
+		t.Fatalf("invalid statement expected after decoding! got %T", other)
+	}
+	if got.Query != want.Query {
+		t.Errorf("query mismatched! want %q, got %q", want.Query, got.Query)
+	}
+	if want.Err != nil && (got.Err == nil || got.Err.Error() != want.Err.Error()) {
+		t.Errorf("error mismatched! want %v, got %v", want.Err, got.Err)
+	}
+}
+
+func TestParserReadError(t *testing.T) {
+	var (
+		errRead = errors.New("read error")
+		input   = io.MultiReader(strings.NewReader("select a from t;\nselect b"), iotest.ErrReader(errRead))
+	)
+	scan, err := scanner.Scan(input, lang.GetKeywords())
+	if err != nil {
+		t.Fatalf("fail to create scanner: %s", err)
+	}
+	p, err := parser.ParseWithScanner(scan)
+	if err != nil {
+		t.Fatalf("fail to create parser: %s", err)
+	}
+	if _, err := p.Parse(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := p.Parse(); !errors.Is(err, errRead) {
+		t.Errorf("read error expected! got %v", err)
+	}
+}
+
+func TestParserSkipData(t *testing.T) {
+	query := `copy users (id, name) from stdin;
+1	it's ; select
+\.
+insert into t (a, b) values (1, 'x'), (2, (3)), (4, 'y;');
+delete from t;`
+
+	p, err := parser.ParseWithScanner(mustScan(t, query))
+	if err != nil {
+		t.Fatalf("fail to create parser: %s", err)
+	}
+	p.SkipRows(true)
+
+	var list []ast.Statement
+	for {
+		stmt, err := p.Parse()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		list = append(list, stmt)
+	}
+	if len(list) != 3 {
+		t.Fatalf("statements count mismatched! want 3, got %d", len(list))
+	}
+	if c, ok := list[0].(ast.CopyStatement); !ok {
+		t.Errorf("copy expected! got %T", list[0])
+	} else if len(c.Data) != 0 {
+		t.Errorf("rows of copy should have been skipped! got %q", c.Data)
+	}
+	ins, ok := list[1].(ast.InsertStatement)
+	if !ok {
+		t.Fatalf("insert expected! got %T", list[1])
+	}
+	if rows, ok := ins.Values.(ast.SkippedRows); !ok || rows.Count != 3 {
+		t.Errorf("3 rows should have been skipped! got %+v", ins.Values)
+	}
+	if _, ok := list[2].(ast.DeleteStatement); !ok {
+		t.Errorf("delete expected! got %T", list[2])
+	}
+}
+
+func TestParserCopy(t *testing.T) {
+	query := `copy users (id, name) from stdin with csv header delimiter as ';';
+1;it's ; select
+2;bob
+\.
+delete from t;`
+
+	p, err := parser.ParseWithScanner(mustScan(t, query))
+	if err != nil {
+		t.Fatalf("fail to create parser: %s", err)
+	}
+	stmt, err := p.Parse()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	c, ok := stmt.(ast.CopyStatement)
+	if !ok {
+		t.Fatalf("copy expected! got %T", stmt)
+	}
+	if want := []string{"1;it's ; select", "2;bob"}; !reflect.DeepEqual(c.Data, want) {
+		t.Errorf("rows mismatched! want %q, got %q", want, c.Data)
+	}
+	var options []string
+	for _, o := range c.Options {
+		opt := o.(ast.Assignment)
+		str := opt.Field.(ast.Name).Ident()
+		switch v := opt.Value.(type) {
+		case ast.Name:
+			str += " " + v.Ident()
+		case ast.Value:
+			str += " " + v.Literal
+		}
+		options = append(options, str)
+	}
+	if want := []string{"FORMAT csv", "header", "delimiter ;"}; !reflect.DeepEqual(options, want) {
+		t.Errorf("options mismatched! want %q, got %q", want, options)
+	}
+	if stmt, err = p.Parse(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, ok := stmt.(ast.DeleteStatement); !ok {
+		t.Errorf("delete expected! got %T", stmt)
+	}
+
+	p, err = parser.ParseWithScanner(mustScan(t, "copy users from stdin with csv force not null name;"))
+	if err != nil {
+		t.Fatalf("fail to create parser: %s", err)
+	}
+	if _, err := p.Parse(); err == nil || !strings.Contains(err.Error(), "parenthesis") {
+		t.Errorf("error expected for unsupported option! got %v", err)
+	}
+}
+
+func TestParserUpdate(t *testing.T) {
+	tests := []struct {
+		Query  string
+		Fields []string
+	}{
+		{
+			Query:  "update t set a = 1;",
+			Fields: []string{"a"},
+		},
+		{
+			Query:  "update t set a = 1, b = 2;",
+			Fields: []string{"a", "b"},
+		},
+		{
+			Query:  "update t set a = 1, b = 2, c = c + 1 where d = 3;",
+			Fields: []string{"a", "b", "c"},
+		},
+		{
+			Query:  "update t set (a, b) = (1, 2), c = 3 returning a;",
+			Fields: []string{"(a, b)", "c"},
+		},
+	}
+	for _, c := range tests {
+		p, err := parser.ParseWithScanner(mustScan(t, c.Query))
+		if err != nil {
+			t.Fatalf("fail to create parser: %s", err)
+		}
+		stmt, err := p.Parse()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", c.Query, err)
+			continue
+		}
+		upd, ok := stmt.(ast.UpdateStatement)
+		if !ok {
+			t.Errorf("%s: update expected! got %T", c.Query, stmt)
+			continue
+		}
+		if len(upd.List) != len(c.Fields) {
+			t.Errorf("%s: assignments count mismatched! want %d, got %d", c.Query, len(c.Fields), len(upd.List))
+			continue
+		}
+		for i, s := range upd.List {
+			ass, ok := s.(ast.Assignment)
+			if !ok {
+				t.Errorf("%s: assignment expected! got %T", c.Query, s)
+				continue
+			}
+			if ass.Value == nil {
+				t.Errorf("%s: assignment without value", c.Query)
+			}
+			if got := fieldName(ass.Field); got != c.Fields[i] {
+				t.Errorf("%s: field mismatched! want %s, got %s", c.Query, c.Fields[i], got)
+			}
+		}
+	}
+}
+
+func fieldName(field ast.Statement) string {
+	switch field := field.(type) {
+	case ast.Name:
+		return field.Ident()
+	case ast.List:
+		var parts []string
+		for _, v := range field.Values {
+			parts = append(parts, fieldName(v))
+		}
+		return "(" + strings.Join(parts, ", ") + ")"
+	default:
+		return ""
+	}
+}
+
+func mustScan(t *testing.T, query string) *scanner.Scanner {
+	t.Helper()
+	scan, err := scanner.Scan(strings.NewReader(query), lang.GetKeywords())
+	if err != nil {
+		t.Fatalf("fail to create scanner: %s", err)
+	}
+	return scan
+}
+
 func TestParser(t *testing.T) {
 	files := []string{
 		"select.sql",
@@ -73,3 +516,53 @@ func testFile(t *testing.T, file string) {
 		}
 	}
 }
+
+func TestParserComments(t *testing.T) {
+	query := "-- first\nselect a from t; -- second\n-- third\nselect b from t;"
+	p, err := parser.NewParser(strings.NewReader(query))
+	if err != nil {
+		t.Fatalf("fail to create parser: %s", err)
+	}
+	cm, ok := p.(lang.Commenter)
+	if !ok {
+		t.Fatalf("parser does not give its comments")
+	}
+	cm.KeepComments(true)
+	var got []string
+	for {
+		_, err := p.Parse()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		for _, tok := range cm.Comments() {
+			got = append(got, strings.TrimSpace(tok.Literal))
+		}
+	}
+	want := []string{"first", "second", "third"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("comments mismatched! want %q, got %q", want, got)
+	}
+}
+
+func TestParserCommentsNotKept(t *testing.T) {
+	query := "-- first\nselect a from t; -- second\n-- third\nselect b from t;"
+	p, err := parser.NewParser(strings.NewReader(query))
+	if err != nil {
+		t.Fatalf("fail to create parser: %s", err)
+	}
+	for {
+		_, err := p.Parse()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	if got := p.(lang.Commenter).Comments(); len(got) != 0 {
+		t.Errorf("no comments expected when not asked! got %d", len(got))
+	}
+}
@@ -30,6 +30,10 @@ func (p *Parser) ParseGrant() (ast.Statement, error) {
 	if stmt.Users, err = p.parseGranted(); err != nil {
 		return nil, err
 	}
+	if p.IsKeyword("WITH GRANT OPTION") {
+		p.Next()
+		stmt.GrantOption = true
+	}
 	return stmt, nil
 }
 
@@ -63,7 +67,7 @@ func (p *Parser) ParseRevoke() (ast.Statement, error) {
 
 func (p *Parser) parseGranted() ([]string, error) {
 	var list []string
-	for !p.QueryEnds() && !p.Done() {
+	for !p.QueryEnds() && !p.Done() && !p.IsKeyword("WITH GRANT OPTION") {
 		if !p.Is(token.Ident) {
 			return nil, p.Unexpected("role", identExpected)
 		}
@@ -75,7 +79,7 @@ func (p *Parser) parseGranted() ([]string, error) {
 			if p.QueryEnds() {
 				return nil, p.Unexpected("role", "unexpected comma before end of statement")
 			}
-		case p.QueryEnds():
+		case p.QueryEnds(), p.IsKeyword("WITH GRANT OPTION"):
 		default:
 			return nil, p.Unexpected("role", defaultReason)
 		}
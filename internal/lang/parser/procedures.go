@@ -1,6 +1,8 @@
 package parser
 
 import (
+	"strings"
+
 	"github.com/midbel/sweet/internal/lang/ast"
 	"github.com/midbel/sweet/internal/token"
 )
@@ -21,8 +23,15 @@ func (p *Parser) ParseCreateProcedure() (ast.Statement, error) {
 	if stmt.Parameters, err = p.ParseProcedureParameters(); err != nil {
 		return nil, err
 	}
-	if stmt.Language, err = p.ParseProcedureLanguage(); err != nil {
-		return nil, err
+	for p.IsKeyword("LANGUAGE") || p.isIdentLiteral("SECURITY") {
+		if p.IsKeyword("LANGUAGE") {
+			stmt.Language, err = p.ParseProcedureLanguage()
+		} else {
+			stmt.Security, err = p.ParseProcedureSecurity()
+		}
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	stmt.Body, err = p.ParseProcedureBody()
@@ -43,6 +52,18 @@ func (p *Parser) ParseProcedureLanguage() (string, error) {
 	return lang, nil
 }
 
+// ParseProcedureSecurity parses the SECURITY DEFINER or SECURITY INVOKER
+// clause of a procedure
+func (p *Parser) ParseProcedureSecurity() (string, error) {
+	p.Next()
+	if !p.isIdentLiteral("DEFINER") && !p.isIdentLiteral("INVOKER") {
+		return "", p.Unexpected("procedure", "definer or invoker expected")
+	}
+	security := strings.ToUpper(p.GetCurrLiteral())
+	p.Next()
+	return security, nil
+}
+
 func (p *Parser) ParseProcedureBody() (ast.Statement, error) {
 	if !p.IsKeyword("BEGIN") {
 		return nil, p.Unexpected("procedure", keywordExpected("BEGIN"))
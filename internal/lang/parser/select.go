@@ -9,11 +9,12 @@ import (
 )
 
 func (p *Parser) ParseValues() (ast.Statement, error) {
-	p.Next()
 	var (
-		stmt ast.ValuesStatement
-		err  error
+		stmt  ast.ValuesStatement
+		start = p.curr.Position
+		err   error
 	)
+	p.Next()
 	if !p.Is(token.Lparen) {
 		for !p.Done() && !p.Is(token.EOL) {
 			v, err := p.StartExpression()
@@ -25,14 +26,16 @@ func (p *Parser) ParseValues() (ast.Statement, error) {
 			}
 			stmt.List = append(stmt.List, v)
 		}
+		stmt.Span = p.SpanFrom(start)
 		return stmt, nil
 	}
 	for !p.Done() && !p.Is(token.EOL) {
 		if !p.Is(token.Lparen) {
 			return nil, p.Unexpected("values", missingOpenParen)
 		}
-		p.Next()
 		var list ast.List
+		list.Start = p.curr.Position
+		p.Next()
 		for !p.Done() && !p.Is(token.Rparen) {
 			v, err := p.StartExpression()
 			if err != nil {
@@ -54,16 +57,18 @@ func (p *Parser) ParseValues() (ast.Statement, error) {
 			return nil, p.Unexpected("values", missingCloseParen)
 		}
 		p.Next()
+		list.Span = p.SpanFrom(list.Start)
 		stmt.List = append(stmt.List, list)
 		if !p.Is(token.Comma) {
 			break
 		}
 		p.Next()
 	}
+	stmt.Span = p.SpanFrom(start)
 	return stmt, err
 }
 
-func (p *Parser) parseCompound(stmt ast.Statement) (ast.Statement, error) {
+func (p *Parser) ParseCompound(stmt ast.Statement) (ast.Statement, error) {
 	allDistinct := func() (bool, bool) {
 		p.Next()
 		var (
@@ -75,35 +80,40 @@ func (p *Parser) parseCompound(stmt ast.Statement) (ast.Statement, error) {
 		}
 		return all, distinct
 	}
-	var err error
+	var (
+		next  = p.keywords["SELECT"]
+		start = ast.SpanOf(stmt).Start
+		err   error
+	)
 	switch {
 	case p.IsKeyword("UNION"):
 		u := ast.UnionStatement{
 			Left: stmt,
 		}
 		u.All, u.Distinct = allDistinct()
-		u.Right, err = p.ParseSelect()
-		return u, err
+		u.Right, err = next()
+		return p.Locate(start, u), err
 	case p.IsKeyword("INTERSECT"):
 		i := ast.IntersectStatement{
 			Left: stmt,
 		}
 		i.All, i.Distinct = allDistinct()
-		i.Right, err = p.ParseSelect()
-		return i, err
+		i.Right, err = next()
+		return p.Locate(start, i), err
 	case p.IsKeyword("EXCEPT"):
 		e := ast.ExceptStatement{
 			Left: stmt,
 		}
 		e.All, e.Distinct = allDistinct()
-		e.Right, err = p.ParseSelect()
-		return e, err
+		e.Right, err = next()
+		return p.Locate(start, e), err
 	default:
 		return stmt, err
 	}
 }
 
 func (p *Parser) ParseSelect() (ast.Statement, error) {
+	start := p.curr.Position
 	p.Next()
 	var (
 		stmt ast.SelectStatement
@@ -114,37 +124,79 @@ func (p *Parser) ParseSelect() (ast.Statement, error) {
 		p.Next()
 	}
 	if stmt.Columns, err = p.ParseColumns(); err != nil {
-		return nil, err
+		if stmt.Columns, err = p.recoverSelectList(err); err != nil {
+			return nil, err
+		}
+		if p.QueryEnds() {
+			return stmt, nil
+		}
 	}
 	p.skipComments()
 	if stmt.Tables, err = p.ParseFrom(); err != nil {
-		return nil, err
+		if stmt.Tables, err = p.recoverSelectList(err); err != nil {
+			return nil, err
+		}
 	}
 	p.skipComments()
 	if stmt.Where, err = p.ParseWhere(); err != nil {
-		return nil, err
+		if stmt.Where, err = p.RecoverClause(err, selectClauses...); err != nil {
+			return nil, err
+		}
 	}
 	p.skipComments()
 	if stmt.Groups, err = p.ParseGroupBy(); err != nil {
-		return nil, err
+		if stmt.Groups, err = p.recoverSelectList(err); err != nil {
+			return nil, err
+		}
 	}
 	p.skipComments()
 	if stmt.Having, err = p.ParseHaving(); err != nil {
-		return nil, err
+		if stmt.Having, err = p.RecoverClause(err, selectClauses...); err != nil {
+			return nil, err
+		}
 	}
 	p.skipComments()
 	if stmt.Windows, err = p.ParseWindows(); err != nil {
-		return nil, err
+		if stmt.Windows, err = p.recoverSelectList(err); err != nil {
+			return nil, err
+		}
 	}
 	p.skipComments()
 	if stmt.Orders, err = p.ParseOrderBy(); err != nil {
-		return nil, err
+		if stmt.Orders, err = p.recoverSelectList(err); err != nil {
+			return nil, err
+		}
 	}
 	p.skipComments()
 	if stmt.Limit, err = p.ParseLimit(); err != nil {
+		if stmt.Limit, err = p.RecoverClause(err, selectClauses...); err != nil {
+			return nil, err
+		}
+	}
+	return p.ParseCompound(p.Locate(start, stmt))
+}
+
+var selectClauses = []string{
+	"FROM",
+	"WHERE",
+	"GROUP BY",
+	"HAVING",
+	"WINDOW",
+	"ORDER BY",
+	"LIMIT",
+	"OFFSET",
+	"FETCH",
+	"UNION",
+	"INTERSECT",
+	"EXCEPT",
+}
+
+func (p *Parser) recoverSelectList(err error) ([]ast.Statement, error) {
+	stmt, err := p.RecoverClause(err, selectClauses...)
+	if err != nil {
 		return nil, err
 	}
-	return p.parseCompound(stmt)
+	return []ast.Statement{stmt}, nil
 }
 
 func (p *Parser) ParseColumns() ([]ast.Statement, error) {
@@ -170,11 +222,12 @@ func (p *Parser) ParseColumns() ([]ast.Statement, error) {
 	var (
 		list   []ast.Statement
 		withAs = p.withAlias
+		done   = p.KwCheck("FROM", "INTO")
 	)
 	defer func() {
 		p.withAlias = withAs
 	}()
-	for !p.Done() && !p.IsKeyword("FROM") {
+	for !p.Done() && !done() {
 		p.withAlias = true
 		stmt, err := p.parseItem(get)
 		if err != nil {
@@ -182,8 +235,8 @@ func (p *Parser) ParseColumns() ([]ast.Statement, error) {
 		}
 		list = append(list, stmt)
 	}
-	if !p.IsKeyword("FROM") {
-		return nil, p.Unexpected("select", keywordExpected("FROM"))
+	if !done() {
+		return nil, p.Unexpected("select", keywordExpected("FROM", "INTO"))
 	}
 	if len(list) == 0 {
 		return nil, p.Unexpected("select", "empty select clause")
@@ -223,6 +276,7 @@ func (p *Parser) ParseFrom() ([]ast.Statement, error) {
 		case p.Is(token.Comment):
 		case p.Is(token.Keyword):
 		case p.Is(token.EOL):
+		case p.Nested() && p.Is(token.Rparen):
 		default:
 			return nil, p.Unexpected("FROM", defaultReason)
 		}
@@ -241,9 +295,12 @@ func (p *Parser) ParseFrom() ([]ast.Statement, error) {
 	}
 
 	get = func() (ast.Statement, error) {
-		stmt := ast.Join{
-			Type: p.GetCurrLiteral(),
-		}
+		var (
+			start = p.curr.Position
+			stmt  = ast.Join{
+				Type: p.GetCurrLiteral(),
+			}
+		)
 		p.Next()
 		stmt.Table, err = p.StartExpression()
 		if err != nil {
@@ -257,6 +314,7 @@ func (p *Parser) ParseFrom() ([]ast.Statement, error) {
 		default:
 			return nil, p.Unexpected("join", keywordExpected("ON", "USING"))
 		}
+		stmt.Span = p.SpanFrom(start)
 		return stmt, nil
 	}
 
@@ -283,9 +341,10 @@ func (p *Parser) ParseJoinUsing() (ast.Statement, error) {
 	if !p.Is(token.Lparen) {
 		return nil, p.Unexpected("using", missingOpenParen)
 	}
+	var list ast.List
+	list.Start = p.curr.Position
 	p.Next()
 
-	var list ast.List
 	for !p.Done() && !p.Is(token.Rparen) {
 		stmt, err := p.ParseIdentifier()
 		if err != nil {
@@ -300,6 +359,7 @@ func (p *Parser) ParseJoinUsing() (ast.Statement, error) {
 		return nil, p.Unexpected("using", missingCloseParen)
 	}
 	p.Next()
+	list.Span = p.SpanFrom(list.Start)
 	return list, nil
 }
 
@@ -323,7 +383,15 @@ func (p *Parser) ParseGroupBy() ([]ast.Statement, error) {
 	}
 
 	get := func() (ast.Statement, error) {
-		stmt, err := p.ParseIdentifier()
+		var (
+			stmt ast.Statement
+			err  error
+		)
+		if p.Is(token.Number) {
+			stmt, err = p.ParseLiteral()
+		} else {
+			stmt, err = p.ParseIdentifier()
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -556,6 +624,7 @@ func (p *Parser) ParseOrderBy() ([]ast.Statement, error) {
 			order.Nulls = p.GetCurrLiteral()
 			p.Next()
 		}
+		order.Span = p.SpanFrom(ast.SpanOf(stmt).Start)
 		switch {
 		case p.Is(token.Comma):
 			p.Next()
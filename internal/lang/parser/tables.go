@@ -1,6 +1,8 @@
 package parser
 
 import (
+	"strings"
+
 	"github.com/midbel/sweet/internal/lang/ast"
 	"github.com/midbel/sweet/internal/token"
 )
@@ -150,8 +152,56 @@ func (p *Parser) ParseAlterTable() (ast.Statement, error) {
 		)
 		action.Name = p.GetCurrLiteral()
 		p.Next()
+		switch {
+		case p.isIdentLiteral("TYPE"):
+			p.Next()
+			action.Type, err = p.ParseType()
+		case p.IsKeyword("SET"):
+			p.Next()
+			switch {
+			case p.isIdentLiteral("DATA"):
+				p.Next()
+				if !p.isIdentLiteral("TYPE") {
+					return nil, p.Unexpected("alter column", keywordExpected("TYPE"))
+				}
+				p.Next()
+				action.Type, err = p.ParseType()
+			case p.IsKeyword("DEFAULT"):
+				p.Next()
+				action.Default, err = p.StartExpression()
+			case p.IsKeyword("NOT"):
+				p.Next()
+				if !p.IsKeyword("NULL") {
+					return nil, p.Unexpected("alter column", keywordExpected("NULL"))
+				}
+				p.Next()
+				action.NotNull = true
+			default:
+				return nil, p.Unexpected("alter column", defaultReason)
+			}
+		case p.IsKeyword("DROP"):
+			p.Next()
+			switch {
+			case p.IsKeyword("DEFAULT"):
+				p.Next()
+				action.DropDefault = true
+			case p.IsKeyword("NOT"):
+				p.Next()
+				if !p.IsKeyword("NULL") {
+					return nil, p.Unexpected("alter column", keywordExpected("NULL"))
+				}
+				p.Next()
+				action.DropNotNull = true
+			default:
+				return nil, p.Unexpected("alter column", defaultReason)
+			}
+		default:
+			return nil, p.Unexpected("alter column", defaultReason)
+		}
+		if err != nil {
+			return nil, err
+		}
 		stmt.Action = action
-		return nil, err
 	case p.IsKeyword("DROP CONSTRAINT"):
 		p.Next()
 		var exists bool
@@ -267,6 +317,46 @@ func (p *Parser) ParseCreateView() (ast.Statement, error) {
 	return stmt, err
 }
 
+func (p *Parser) ParseCreateIndex() (ast.Statement, error) {
+	var (
+		stmt ast.CreateIndexStatement
+		err  error
+	)
+	stmt.Unique = p.GetCurrLiteral() == "CREATE UNIQUE INDEX"
+	p.Next()
+	if p.isIdentLiteral("CONCURRENTLY") {
+		p.Next()
+		stmt.Concurrently = true
+	}
+	if p.IsKeyword("IF NOT EXISTS") {
+		p.Next()
+		stmt.NotExists = true
+	}
+	if !p.Curr().IsValue() {
+		return nil, p.Unexpected("create index", identExpected)
+	}
+	stmt.Name = p.GetCurrLiteral()
+	p.Next()
+	if !p.IsKeyword("ON") {
+		return nil, p.Unexpected("create index", keywordExpected("ON"))
+	}
+	p.Next()
+	if stmt.Table, err = p.ParseTableName(); err != nil {
+		return nil, err
+	}
+	if !p.Is(token.Lparen) {
+		return nil, p.Unexpected("create index", missingOpenParen)
+	}
+	stmt.Columns, err = p.parseColumnsList()
+	return stmt, err
+}
+
+// isIdentLiteral reports whether the current token is an identifier used as a
+// keyword by some statements only
+func (p *Parser) isIdentLiteral(kw string) bool {
+	return p.Is(token.Ident) && strings.EqualFold(p.GetCurrLiteral(), kw)
+}
+
 func (p *Parser) ParseTableName() (ast.Statement, error) {
 	return p.ParseIdentifier()
 }
@@ -276,12 +366,14 @@ func (p *Parser) ParseColumnDef(ctp CreateTableParser) (ast.Statement, error) {
 		def ast.ColumnDef
 		err error
 	)
+	def.Start = p.curr.Position
 	def.Name = p.GetCurrLiteral()
 	p.Next()
 	if def.Type, err = p.ParseType(); err != nil {
 		return nil, err
 	}
 	if p.Is(token.Comma) {
+		def.Span = p.SpanFrom(def.Start)
 		return def, nil
 	}
 	for !p.QueryEnds() && !p.Done() && !p.Is(token.Comma) && !p.Is(token.Rparen) {
@@ -291,6 +383,7 @@ func (p *Parser) ParseColumnDef(ctp CreateTableParser) (ast.Statement, error) {
 		}
 		def.Constraints = append(def.Constraints, cst)
 	}
+	def.Span = p.SpanFrom(def.Start)
 	return def, err
 }
 
@@ -300,8 +393,9 @@ func (p *Parser) ParseConstraint(column bool) (ast.Statement, error) {
 
 func (p *Parser) parseConstraintWithKeyword(keyword string, required, column bool) (ast.Statement, error) {
 	var (
-		cst ast.Constraint
-		err error
+		cst   ast.Constraint
+		start = p.curr.Position
+		err   error
 	)
 	if p.IsKeyword(keyword) {
 		p.Next()
@@ -310,6 +404,7 @@ func (p *Parser) parseConstraintWithKeyword(keyword string, required, column boo
 	} else if required && !p.IsKeyword(keyword) {
 		return nil, p.Unexpected("constraint", defaultReason)
 	}
+	pos := p.curr.Position
 	switch {
 	case p.IsKeyword("PRIMARY KEY"):
 		cst.Statement, err = p.ParsePrimaryKeyConstraint(column)
@@ -334,6 +429,8 @@ func (p *Parser) parseConstraintWithKeyword(keyword string, required, column boo
 	default:
 		return nil, p.Unexpected("constraint", defaultReason)
 	}
+	cst.Statement = p.Locate(pos, cst.Statement)
+	cst.Span = p.SpanFrom(start)
 	return cst, err
 }
 
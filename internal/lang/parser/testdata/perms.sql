@@ -1,2 +1,3 @@
 grant select, insert, update on employees to foo, bar;
-revoke select, insert, update on employees from foo;
\ No newline at end of file
+revoke select, insert, update on employees from foo;
+grant select on employees to foo with grant option;
@@ -17,4 +17,12 @@ BEGIN
 	END IF;
 	SELECT * FROM employes;
 	RETURN 0;
-END;
\ No newline at end of file
+END;
+CREATE PROCEDURE PURGE(
+	IN tbl VARCHAR(32)
+)
+LANGUAGE SQL
+SECURITY DEFINER
+BEGIN
+	EXECUTE IMMEDIATE 'DELETE FROM employes WHERE dept = ?' USING tbl;
+END;
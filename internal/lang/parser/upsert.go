@@ -1,6 +1,8 @@
 package parser
 
 import (
+	"strings"
+
 	"github.com/midbel/sweet/internal/lang/ast"
 	"github.com/midbel/sweet/internal/token"
 )
@@ -89,7 +91,7 @@ func (p *Parser) parseMergeMatched(cdt ast.Statement) (ast.Statement, error) {
 		p.Next()
 		var upd ast.UpdateStatement
 		for !p.QueryEnds() && !p.IsKeyword("WHEN MATCHED") && !p.IsKeyword("WHEN NOT MATCHED") {
-			s, err := p.parseAssignment()
+			s, err := p.ParseAssignment()
 			if err != nil {
 				return nil, err
 			}
@@ -195,6 +197,180 @@ func (p *Parser) ParseTruncate() (ast.Statement, error) {
 	return stmt, nil
 }
 
+// ParseCopy parses the COPY statement of PostgreSQL. The rows given after a
+// COPY FROM STDIN are read by the scanner as soon as the end of the statement
+// is seen, before they are read as tokens. They are dropped when the rows are
+// skipped.
+func (p *Parser) ParseCopy() (ast.Statement, error) {
+	p.Next()
+	var (
+		stmt  ast.CopyStatement
+		stdin bool
+		err   error
+	)
+	next := func() {
+		if stdin && p.PeekIs(token.EOL) {
+			if p.skipRows {
+				p.scan.SkipLines(`\.`)
+			} else {
+				stmt.Data = p.scan.ReadLines(`\.`)
+			}
+		}
+		p.Next()
+	}
+	if p.Is(token.Lparen) {
+		p.Next()
+		if stmt.Table, err = p.ParseStatement(); err != nil {
+			return nil, err
+		}
+		if !p.Is(token.Rparen) {
+			return nil, p.Unexpected("copy", missingCloseParen)
+		}
+		p.Next()
+	} else {
+		if stmt.Table, err = p.ParseIdentifier(); err != nil {
+			return nil, err
+		}
+		if stmt.Columns, err = p.parseColumnsList(); err != nil {
+			return nil, err
+		}
+	}
+	switch {
+	case p.IsKeyword("FROM"):
+	case p.IsKeyword("TO"):
+		stmt.To = true
+	default:
+		return nil, p.Unexpected("copy", keywordExpected("FROM", "TO"))
+	}
+	p.Next()
+
+	switch lit := strings.ToUpper(p.GetCurrLiteral()); {
+	case p.Is(token.Literal):
+		stmt.Target, err = p.ParseLiteral()
+		if err != nil {
+			return nil, err
+		}
+	case p.Is(token.Ident) && (lit == "STDIN" || lit == "STDOUT"):
+		var (
+			name  ast.Name
+			start = p.curr.Position
+		)
+		stdin = !stmt.To
+		name.Parts = append(name.Parts, lit)
+		next()
+		name.Span = p.SpanFrom(start)
+		stmt.Target = name
+	default:
+		return nil, p.Unexpected("copy", "expected file name, STDIN or STDOUT")
+	}
+
+	if p.IsKeyword("WITH") {
+		next()
+	}
+	if !p.Is(token.Lparen) {
+		stmt.Options, err = p.parseCopyLegacyOptions(next)
+		return stmt, err
+	}
+	p.Next()
+	for !p.Done() && !p.Is(token.Rparen) {
+		var (
+			opt   ast.Assignment
+			start = p.curr.Position
+		)
+		if !p.Is(token.Ident) && !p.Is(token.Keyword) {
+			return nil, p.Unexpected("copy", identExpected)
+		}
+		name := ast.Name{
+			Parts: []string{p.GetCurrLiteral()},
+		}
+		p.Next()
+		name.Span = p.SpanFrom(start)
+		opt.Field = name
+		switch {
+		case p.Is(token.Comma) || p.Is(token.Rparen):
+		case p.Is(token.Ident):
+			opt.Value, err = p.ParseIdentifier()
+		default:
+			opt.Value, err = p.ParseLiteral()
+		}
+		if err != nil {
+			return nil, err
+		}
+		opt.Span = p.SpanFrom(start)
+		stmt.Options = append(stmt.Options, opt)
+		if p.Is(token.Comma) {
+			p.Next()
+		}
+	}
+	if !p.Is(token.Rparen) {
+		return nil, p.Unexpected("copy", missingCloseParen)
+	}
+	next()
+	return stmt, nil
+}
+
+// parseCopyLegacyOptions parses the options of COPY given without parenthesis,
+// such as WITH CSV HEADER. They are given back in the form of the options
+// given between parenthesis.
+func (p *Parser) parseCopyLegacyOptions(next func()) ([]ast.Statement, error) {
+	var list []ast.Statement
+	for !p.Done() && !p.Is(token.EOL) {
+		var (
+			opt   ast.Assignment
+			start = p.curr.Position
+			ident = p.GetCurrLiteral()
+			name  = strings.ToUpper(ident)
+		)
+		if !p.Is(token.Ident) && !p.Is(token.Keyword) {
+			return nil, p.Unexpected("copy", identExpected)
+		}
+		switch name {
+		case "BINARY", "CSV":
+			next()
+			opt.Field = ast.Name{
+				Span:  p.SpanFrom(start),
+				Parts: []string{"FORMAT"},
+			}
+			opt.Value = ast.Name{
+				Span:  p.SpanFrom(start),
+				Parts: []string{strings.ToLower(ident)},
+			}
+		case "HEADER":
+			next()
+			opt.Field = ast.Name{
+				Span:  p.SpanFrom(start),
+				Parts: []string{ident},
+			}
+		case "DELIMITER", "NULL", "QUOTE", "ESCAPE", "ENCODING":
+			p.Next()
+			opt.Field = ast.Name{
+				Span:  p.SpanFrom(start),
+				Parts: []string{ident},
+			}
+			if p.IsKeyword("AS") {
+				p.Next()
+			}
+			if !p.Is(token.Literal) {
+				return nil, p.Unexpected("copy", "expected string literal")
+			}
+			var (
+				pos   = p.curr.Position
+				value = ast.Value{
+					Literal: p.GetCurrLiteral(),
+				}
+			)
+			next()
+			value.Span = p.SpanFrom(pos)
+			opt.Value = value
+		default:
+			return nil, p.Unexpected("copy", "unsupported option (give the options between parenthesis)")
+		}
+		opt.Span = p.SpanFrom(start)
+		list = append(list, opt)
+	}
+	return list, nil
+}
+
 func (p *Parser) ParseReturning() (ast.Statement, error) {
 	if !p.IsKeyword("RETURNING") {
 		return nil, nil
@@ -252,31 +428,32 @@ func (p *Parser) ParseUpdate() (ast.Statement, error) {
 		return nil, err
 	}
 	stmt.Return, err = p.ParseReturning()
-	return nil, err
+	return stmt, err
 }
 
 func (p *Parser) ParseUpdateList() ([]ast.Statement, error) {
 	var list []ast.Statement
 	for !p.Done() && !p.Is(token.EOL) && !p.IsKeyword("WHERE") && !p.IsKeyword("FROM") && !p.IsKeyword("RETURNING") {
-		stmt, err := p.parseAssignment()
+		stmt, err := p.ParseAssignment()
 		if err != nil {
 			return nil, err
 		}
+		list = append(list, stmt)
 		if p.Is(token.EOL) {
 			break
 		}
 		if err := p.EnsureEnd("update", token.Comma, token.Keyword); err != nil {
 			return nil, err
 		}
-		list = append(list, stmt)
 	}
 	return list, nil
 }
 
-func (p *Parser) parseAssignment() (ast.Statement, error) {
+func (p *Parser) ParseAssignment() (ast.Statement, error) {
 	var (
-		ass ast.Assignment
-		err error
+		ass   ast.Assignment
+		start = p.curr.Position
+		err   error
 	)
 	switch {
 	case p.Is(token.Ident):
@@ -326,12 +503,14 @@ func (p *Parser) parseAssignment() (ast.Statement, error) {
 			return nil, p.Unexpected("update", missingCloseParen)
 		}
 		p.Next()
+		ass.Value = list
 	} else {
 		ass.Value, err = p.StartExpression()
 		if err != nil {
 			return nil, err
 		}
 	}
+	ass.Span = p.SpanFrom(start)
 	return ass, nil
 }
 
@@ -354,6 +533,8 @@ func (p *Parser) ParseInsert() (ast.Statement, error) {
 	switch {
 	case p.IsKeyword("SELECT") || p.IsKeyword("WITH"):
 		stmt.Values, err = p.ParseStatement()
+	case p.IsKeyword("VALUES") && p.skipRows:
+		stmt.Values, err = p.skipValues()
 	case p.IsKeyword("VALUES"):
 		stmt.Values, err = p.ParseValues()
 	default:
@@ -369,6 +550,37 @@ func (p *Parser) ParseInsert() (ast.Statement, error) {
 	return stmt, err
 }
 
+// skipValues counts the rows of the VALUES clause of an INSERT without building
+// a node for each of them
+func (p *Parser) skipValues() (ast.Statement, error) {
+	var (
+		stmt  ast.SkippedRows
+		start = p.curr.Position
+		depth int
+	)
+	p.Next()
+	for !p.Done() && !p.Is(token.EOL) {
+		if depth == 0 && (p.IsKeyword("ON CONFLICT") || p.IsKeyword("RETURNING")) {
+			break
+		}
+		switch {
+		case p.Is(token.Lparen):
+			if depth == 0 {
+				stmt.Count++
+			}
+			depth++
+		case p.Is(token.Rparen):
+			depth--
+		}
+		p.Next()
+	}
+	if depth != 0 {
+		return nil, p.Unexpected("values", missingCloseParen)
+	}
+	stmt.Span = p.SpanFrom(start)
+	return stmt, nil
+}
+
 func (p *Parser) ParseUpsert() (ast.Statement, error) {
 	if !p.IsKeyword("ON CONFLICT") {
 		return nil, nil
@@ -412,17 +624,17 @@ func (p *Parser) ParseUpsert() (ast.Statement, error) {
 func (p *Parser) ParseUpsertList() ([]ast.Statement, error) {
 	var list []ast.Statement
 	for !p.Done() && !p.Is(token.EOL) && !p.IsKeyword("WHERE") && !p.IsKeyword("RETURNING") {
-		stmt, err := p.parseAssignment()
+		stmt, err := p.ParseAssignment()
 		if err != nil {
 			return nil, err
 		}
+		list = append(list, stmt)
 		if p.Is(token.EOL) {
 			break
 		}
 		if err := p.EnsureEnd("update", token.Comma, token.Keyword); err != nil {
 			return nil, err
 		}
-		list = append(list, stmt)
 	}
 	return list, nil
 }
@@ -8,7 +8,10 @@ import (
 )
 
 func (p *Parser) ParsePlaceholder() (ast.Statement, error) {
-	var stmt ast.Placeholder
+	var (
+		stmt  ast.Placeholder
+		start = p.curr.Position
+	)
 	switch {
 	case p.Is(token.Placeholder):
 		p.Next()
@@ -17,6 +20,7 @@ func (p *Parser) ParsePlaceholder() (ast.Statement, error) {
 			Parts: []string{p.GetCurrLiteral()},
 		}
 		p.Next()
+		stmt.Statement = p.Locate(start, stmt.Statement)
 	case p.Is(token.PositionHolder):
 		if _, err := strconv.Atoi(p.GetCurrLiteral()); err != nil {
 			return nil, err
@@ -25,17 +29,24 @@ func (p *Parser) ParsePlaceholder() (ast.Statement, error) {
 			Literal: p.GetCurrLiteral(),
 		}
 		p.Next()
+		stmt.Statement = p.Locate(start, stmt.Statement)
 	default:
 		return nil, p.Unexpected("placeholder", defaultReason)
 	}
+	stmt.Span = p.SpanFrom(start)
 	return stmt, nil
 }
 
 func (p *Parser) ParseLiteral() (ast.Statement, error) {
-	stmt := ast.Value{
-		Literal: p.GetCurrLiteral(),
-	}
+	var (
+		start = p.curr.Position
+		stmt  = ast.Value{
+			Literal: p.GetCurrLiteral(),
+			Prefix:  p.Curr().Prefix,
+		}
+	)
 	p.Next()
+	stmt.Span = p.SpanFrom(start)
 	return stmt, nil
 }
 
@@ -52,9 +63,13 @@ func (p *Parser) ParseConstant() (ast.Statement, error) {
 }
 
 func (p *Parser) ParseIdentifier() (ast.Statement, error) {
-	var name ast.Name
+	var (
+		name  ast.Name
+		start = p.curr.Position
+	)
 	for p.PeekIs(token.Dot) {
 		name.Parts = append(name.Parts, p.GetCurrLiteral())
+		name.Quoted = append(name.Quoted, p.Curr().Quoted)
 		p.Next()
 		p.Next()
 	}
@@ -62,7 +77,9 @@ func (p *Parser) ParseIdentifier() (ast.Statement, error) {
 		return nil, p.Unexpected("identifier", identExpected)
 	}
 	name.Parts = append(name.Parts, p.GetCurrLiteral())
+	name.Quoted = append(name.Quoted, p.Curr().Quoted)
 	p.Next()
+	name.Span = p.SpanFrom(start)
 	return name, nil
 }
 
@@ -71,7 +88,7 @@ func (p *Parser) ParseIdent() (ast.Statement, error) {
 	if err == nil {
 		stmt, err = p.ParseAlias(stmt)
 	}
-	return stmt, nil
+	return stmt, err
 }
 
 func (p *Parser) ParseAlias(stmt ast.Statement) (ast.Statement, error) {
@@ -81,12 +98,15 @@ func (p *Parser) ParseAlias(stmt ast.Statement) (ast.Statement, error) {
 	}
 	switch p.Curr().Type {
 	case token.Ident, token.Literal, token.Number:
-		stmt = ast.Alias{
+		alias := ast.Alias{
 			Statement: stmt,
 			Alias:     p.GetCurrLiteral(),
 			As:        mandatory,
+			Quoted:    p.Curr().Quoted,
 		}
 		p.Next()
+		alias.Span = p.SpanFrom(ast.SpanOf(stmt).Start)
+		stmt = alias
 	default:
 		if mandatory {
 			return nil, p.Unexpected("alias", identExpected)
@@ -108,7 +128,10 @@ func (p *Parser) ParseCase() (ast.Statement, error) {
 		}
 	}
 	for p.IsKeyword("WHEN") {
-		var when ast.When
+		var (
+			when  ast.When
+			start = p.curr.Position
+		)
 		p.Next()
 		when.Cdt, err = p.StartExpression()
 		if err != nil {
@@ -126,6 +149,7 @@ func (p *Parser) ParseCase() (ast.Statement, error) {
 		if err != nil {
 			return nil, err
 		}
+		when.Span = p.SpanFrom(start)
 		stmt.Body = append(stmt.Body, when)
 	}
 	if p.IsKeyword("ELSE") {
@@ -179,6 +203,7 @@ func (p *Parser) ParseType() (ast.Type, error) {
 	if !p.Is(token.Ident) {
 		return t, p.Unexpected("type", identExpected)
 	}
+	start := p.curr.Position
 	t.Name = p.GetCurrLiteral()
 	p.Next()
 	if p.Is(token.Lparen) {
@@ -203,6 +228,7 @@ func (p *Parser) ParseType() (ast.Type, error) {
 		}
 		p.Next()
 	}
+	t.Span = p.SpanFrom(start)
 	return t, nil
 }
 
@@ -0,0 +1,23 @@
+package ms
+
+import (
+	"github.com/midbel/sweet/internal/lang"
+	"github.com/midbel/sweet/internal/lang/parser"
+	"github.com/midbel/sweet/internal/scanner"
+)
+
+func init() {
+	lang.Register(lang.Dialect{
+		Name:     "mssql",
+		Aliases:  []string{"ms", "tsql", "sqlserver"},
+		Keywords: lang.GetKeywords(),
+		Tokenizers: []scanner.Tokenizer{
+			scanner.QuotedIdent('[', ']'),
+			scanner.PrefixedIdent('#'),
+		},
+		Formatter: GetFormatter(),
+		NewParser: func(scan *scanner.Scanner) (lang.Parser, error) {
+			return parser.ParseWithScanner(scan)
+		},
+	})
+}
@@ -2,6 +2,7 @@ package ms
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/midbel/sweet/internal/lang"
 )
@@ -12,6 +13,10 @@ func (_ tsqlFormatter) Quote(str string) string {
 	return fmt.Sprintf("[%s]", str)
 }
 
+func (_ tsqlFormatter) Keyword(str string) string {
+	return strings.ToLower(str)
+}
+
 func GetFormatter() lang.Formatter {
 	return tsqlFormatter{}
 }
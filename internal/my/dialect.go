@@ -0,0 +1,23 @@
+package my
+
+import (
+	"github.com/midbel/sweet/internal/lang"
+	"github.com/midbel/sweet/internal/lang/parser"
+	"github.com/midbel/sweet/internal/scanner"
+)
+
+func init() {
+	lang.Register(lang.Dialect{
+		Name:     "mysql",
+		Aliases:  []string{"my", "mariadb"},
+		Keywords: lang.GetKeywords(),
+		Tokenizers: []scanner.Tokenizer{
+			scanner.QuotedIdent('`', '`'),
+			scanner.EscapedString(0),
+		},
+		Formatter: GetFormatter(),
+		NewParser: func(scan *scanner.Scanner) (lang.Parser, error) {
+			return parser.ParseWithScanner(scan)
+		},
+	})
+}
@@ -2,6 +2,7 @@ package my
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/midbel/sweet/internal/lang"
 )
@@ -12,6 +13,10 @@ func (_ mysqlFormatter) Quote(str string) string {
 	return fmt.Sprintf("`%s`", str)
 }
 
+func (_ mysqlFormatter) Keyword(str string) string {
+	return strings.ToLower(str)
+}
+
 func GetFormatter() lang.Formatter {
 	return mysqlFormatter{}
 }
@@ -0,0 +1,157 @@
+package ora
+
+import (
+	"github.com/midbel/sweet/internal/lang/ast"
+)
+
+type CreatePackageStatement struct {
+	ast.Span
+	Name    ast.Statement
+	Replace bool
+	Body    bool
+	Declare []ast.Statement
+	Init    ast.Statement
+}
+
+func (s CreatePackageStatement) Keyword() (string, error) {
+	kw := "CREATE PACKAGE"
+	if s.Replace {
+		kw = "CREATE OR REPLACE PACKAGE"
+	}
+	if s.Body {
+		kw += " BODY"
+	}
+	return kw, nil
+}
+
+type CreateProcedureStatement struct {
+	ast.Span
+	ast.CreateProcedureStatement
+	Function bool
+	Return   ast.Type
+}
+
+func (s CreateProcedureStatement) Keyword() (string, error) {
+	if !s.Function {
+		return s.CreateProcedureStatement.Keyword()
+	}
+	if s.Replace {
+		return "CREATE OR REPLACE FUNCTION", nil
+	}
+	return "CREATE FUNCTION", nil
+}
+
+type Block struct {
+	ast.Span
+	Label    string
+	Declare  []ast.Statement
+	Body     ast.Statement
+	Handlers []ast.Statement
+}
+
+func (_ Block) Keyword() (string, error) {
+	return "BEGIN", nil
+}
+
+type Handler struct {
+	ast.Span
+	Exceptions []string
+	Body       ast.Statement
+}
+
+func (_ Handler) Keyword() (string, error) {
+	return "WHEN", nil
+}
+
+type Cursor struct {
+	ast.Span
+	Ident      string
+	Parameters []ast.Statement
+	Query      ast.Statement
+}
+
+func (_ Cursor) Keyword() (string, error) {
+	return "CURSOR", nil
+}
+
+type ForLoop struct {
+	ast.Span
+	Ident  string
+	Cursor ast.Statement
+	Body   ast.Statement
+}
+
+func (_ ForLoop) Keyword() (string, error) {
+	return "FOR", nil
+}
+
+type Loop struct {
+	ast.Span
+	Body ast.Statement
+}
+
+func (_ Loop) Keyword() (string, error) {
+	return "LOOP", nil
+}
+
+type Exit struct {
+	ast.Span
+	Cdt ast.Statement
+}
+
+func (_ Exit) Keyword() (string, error) {
+	return "EXIT", nil
+}
+
+type Raise struct {
+	ast.Span
+	Exception string
+}
+
+func (_ Raise) Keyword() (string, error) {
+	return "RAISE", nil
+}
+
+type Null struct {
+	ast.Span
+}
+
+func (_ Null) Keyword() (string, error) {
+	return "NULL", nil
+}
+
+type SelectStatement struct {
+	ast.Span
+	ast.SelectStatement
+	Into      []ast.Statement
+	StartWith ast.Statement
+	ConnectBy ast.Statement
+	NoCycle   bool
+}
+
+type MergeUpdate struct {
+	ast.Span
+	ast.UpdateStatement
+	Delete ast.Statement
+}
+
+type MergeInsert struct {
+	ast.Span
+	ast.InsertStatement
+}
+
+type Prior struct {
+	ast.Span
+	ast.Statement
+}
+
+type OuterJoin struct {
+	ast.Span
+	ast.Statement
+}
+
+type Attribute struct {
+	ast.Span
+	Ident ast.Statement
+	Name  string
+}
@@ -0,0 +1,36 @@
+package ora
+
+import (
+	"github.com/midbel/sweet/internal/lang"
+	"github.com/midbel/sweet/internal/lang/ast"
+)
+
+func init() {
+	lang.Register(lang.Dialect{
+		Name:       "oracle",
+		Aliases:    []string{"ora", "plsql"},
+		Keywords:   GetKeywords(),
+		Tokenizers: tokenizers(),
+		Formatter:  GetFormatter(),
+		Folding:    lang.FoldUpper,
+		NewParser:  ParseWithScanner,
+	})
+	ast.RegisterType(
+		CreatePackageStatement{},
+		CreateProcedureStatement{},
+		Block{},
+		Handler{},
+		Cursor{},
+		ForLoop{},
+		Loop{},
+		Exit{},
+		Raise{},
+		Null{},
+		SelectStatement{},
+		MergeUpdate{},
+		MergeInsert{},
+		Prior{},
+		OuterJoin{},
+		Attribute{},
+	)
+}
@@ -0,0 +1,22 @@
+package ora
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/midbel/sweet/internal/lang"
+)
+
+type oracleFormatter struct{}
+
+func (_ oracleFormatter) Quote(str string) string {
+	return fmt.Sprintf("\"%s\"", str)
+}
+
+func (_ oracleFormatter) Keyword(str string) string {
+	return strings.ToUpper(str)
+}
+
+func GetFormatter() lang.Formatter {
+	return oracleFormatter{}
+}
@@ -0,0 +1,36 @@
+package ora
+
+import (
+	"github.com/midbel/sweet/internal/keywords"
+	"github.com/midbel/sweet/internal/lang"
+)
+
+var kw = keywords.Set{
+	{"create", "package"},
+	{"create", "package", "body"},
+	{"create", "or", "replace", "package"},
+	{"create", "or", "replace", "package", "body"},
+	{"create", "function"},
+	{"create", "or", "replace", "function"},
+	{"procedure"},
+	{"function"},
+	{"cursor"},
+	{"exception"},
+	{"loop"},
+	{"end", "loop"},
+	{"for"},
+	{"exit"},
+	{"exit", "when"},
+	{"raise"},
+	{"into"},
+	{"in", "out"},
+	{"start", "with"},
+	{"connect", "by"},
+	{"nocycle"},
+	{"prior"},
+	{"delete"},
+}
+
+func GetKeywords() keywords.Set {
+	return kw.Merge(lang.GetKeywords())
+}
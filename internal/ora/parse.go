@@ -0,0 +1,879 @@
+package ora
+
+import (
+	"io"
+	"strings"
+
+	"github.com/midbel/sweet/internal/lang"
+	"github.com/midbel/sweet/internal/lang/ast"
+	"github.com/midbel/sweet/internal/lang/parser"
+	"github.com/midbel/sweet/internal/scanner"
+	"github.com/midbel/sweet/internal/token"
+)
+
+type Parser struct {
+	*parser.Parser
+}
+
+func Parse(r io.Reader) (lang.Parser, error) {
+	scan, err := Scan(r)
+	if err != nil {
+		return nil, err
+	}
+	return ParseWithScanner(scan)
+}
+
+func ParseWithScanner(scan *scanner.Scanner) (lang.Parser, error) {
+	var (
+		ps  Parser
+		err error
+	)
+	ps.Parser, err = parser.ParseWithScanner(scan)
+	if err != nil {
+		return nil, err
+	}
+
+	ps.RegisterParseFunc("CREATE PACKAGE", ps.ParseCreatePackage)
+	ps.RegisterParseFunc("CREATE PACKAGE BODY", ps.ParseCreatePackage)
+	ps.RegisterParseFunc("CREATE OR REPLACE PACKAGE", ps.ParseCreatePackage)
+	ps.RegisterParseFunc("CREATE OR REPLACE PACKAGE BODY", ps.ParseCreatePackage)
+	ps.RegisterParseFunc("CREATE PROCEDURE", ps.ParseCreateProcedure)
+	ps.RegisterParseFunc("CREATE OR REPLACE PROCEDURE", ps.ParseCreateProcedure)
+	ps.RegisterParseFunc("CREATE FUNCTION", ps.ParseCreateProcedure)
+	ps.RegisterParseFunc("CREATE OR REPLACE FUNCTION", ps.ParseCreateProcedure)
+	ps.RegisterParseFunc("DECLARE", ps.ParseBlock)
+	ps.RegisterParseFunc("BEGIN", ps.ParseBlock)
+	ps.RegisterParseFunc("IF", ps.ParseIf)
+	ps.RegisterParseFunc("WHILE", ps.ParseWhile)
+	ps.RegisterParseFunc("LOOP", ps.ParseLoop)
+	ps.RegisterParseFunc("FOR", ps.ParseFor)
+	ps.RegisterParseFunc("EXIT", ps.ParseExit)
+	ps.RegisterParseFunc("EXIT WHEN", ps.ParseExit)
+	ps.RegisterParseFunc("RAISE", ps.ParseRaise)
+	ps.RegisterParseFunc("NULL", ps.ParseNull)
+	ps.RegisterParseFunc("RETURN", ps.ParseReturn)
+	ps.RegisterParseFunc("SELECT", ps.ParseSelect)
+	ps.RegisterParseFunc("MERGE", ps.ParseMerge)
+	ps.RegisterParseFunc("MERGE INTO", ps.ParseMerge)
+
+	ps.ExtendFuncSet(func() {
+		ps.RegisterPrefix("", token.Ident, ps.ParseIdentifier)
+		ps.RegisterPrefix("PRIOR", token.Keyword, ps.ParsePrior)
+	})
+
+	return &ps, err
+}
+
+func (p *Parser) Parse() (ast.Statement, error) {
+	for p.Is(token.EOL) {
+		p.Next()
+	}
+	return p.Parser.Parse()
+}
+
+func (p *Parser) ParseCreatePackage() (ast.Statement, error) {
+	var (
+		stmt CreatePackageStatement
+		err  error
+	)
+	stmt.Replace = strings.Contains(p.GetCurrLiteral(), "OR REPLACE")
+	stmt.Body = strings.HasSuffix(p.GetCurrLiteral(), "BODY")
+	p.Next()
+
+	if stmt.Name, err = p.Parser.ParseIdentifier(); err != nil {
+		return nil, err
+	}
+	if !p.IsKeyword("IS") && !p.IsKeyword("AS") {
+		return nil, p.Unexpected("package", "expected IS|AS keyword(s)")
+	}
+	p.Next()
+	if stmt.Declare, err = p.ParseDeclarations(p.KwCheck("BEGIN", "END")); err != nil {
+		return nil, err
+	}
+	if p.IsKeyword("BEGIN") {
+		if !stmt.Body {
+			return nil, p.Unexpected("package", "initialization section only allowed in package body")
+		}
+		p.Next()
+		if stmt.Init, err = p.ParseBody(p.KwCheck("END")); err != nil {
+			return nil, err
+		}
+	}
+	if _, err = p.parseEnd("package"); err != nil {
+		return nil, err
+	}
+	return stmt, nil
+}
+
+func (p *Parser) ParseCreateProcedure() (ast.Statement, error) {
+	var stmt CreateProcedureStatement
+	stmt.Replace = strings.Contains(p.GetCurrLiteral(), "OR REPLACE")
+	stmt.Function = strings.HasSuffix(p.GetCurrLiteral(), "FUNCTION")
+	p.Next()
+	return p.parseSubprogram(stmt)
+}
+
+func (p *Parser) ParseSubprogram() (ast.Statement, error) {
+	var stmt CreateProcedureStatement
+	stmt.Function = p.IsKeyword("FUNCTION")
+	p.Next()
+	return p.parseSubprogram(stmt)
+}
+
+func (p *Parser) parseSubprogram(stmt CreateProcedureStatement) (ast.Statement, error) {
+	var err error
+	if stmt.Name, err = p.Parser.ParseIdentifier(); err != nil {
+		return nil, err
+	}
+	if p.Is(token.Lparen) {
+		if stmt.Parameters, err = p.ParseProcedureParameters(); err != nil {
+			return nil, err
+		}
+	}
+	if stmt.Function {
+		if !p.IsKeyword("RETURN") {
+			return nil, p.Unexpected("function", "expected RETURN keyword(s)")
+		}
+		p.Next()
+		if stmt.Return, err = p.ParseType(); err != nil {
+			return nil, err
+		}
+	}
+	if p.Is(token.EOL) {
+		return stmt, nil
+	}
+	if !p.IsKeyword("IS") && !p.IsKeyword("AS") {
+		return nil, p.Unexpected("procedure", "expected IS|AS keyword(s)")
+	}
+	p.Next()
+
+	var block Block
+	if block.Declare, err = p.ParseDeclarations(p.KwCheck("BEGIN")); err != nil {
+		return nil, err
+	}
+	stmt.Body, err = p.parseBlock(block)
+	return stmt, err
+}
+
+func (p *Parser) ParseProcedureParameters() ([]ast.Statement, error) {
+	if err := p.Expect("procedure", token.Lparen); err != nil {
+		return nil, err
+	}
+	var list []ast.Statement
+	for !p.Done() && !p.Is(token.Rparen) {
+		start := p.Curr().Position
+		stmt, err := p.ParseProcedureParameter()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, p.Locate(start, stmt))
+		if err := p.EnsureEnd("procedure", token.Comma, token.Rparen); err != nil {
+			return nil, err
+		}
+	}
+	return list, p.Expect("procedure", token.Rparen)
+}
+
+func (p *Parser) ParseProcedureParameter() (ast.Statement, error) {
+	var (
+		param ast.ProcedureParameter
+		err   error
+	)
+	if !p.Is(token.Ident) {
+		return nil, p.Unexpected("procedure", "a valid identifier is expected")
+	}
+	param.Name = p.GetCurrLiteral()
+	p.Next()
+	switch {
+	case p.IsKeyword("IN"):
+		param.Mode = ast.ModeIn
+	case p.IsKeyword("OUT"):
+		param.Mode = ast.ModeOut
+	case p.IsKeyword("IN OUT"):
+		param.Mode = ast.ModeInOut
+	default:
+	}
+	if param.Mode != 0 {
+		p.Next()
+	}
+	if param.Type, err = p.ParseType(); err != nil {
+		return nil, err
+	}
+	if p.IsKeyword("DEFAULT") || p.Is(token.Assign) {
+		p.Next()
+		param.Default, err = p.StartExpression()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return param, nil
+}
+
+func (p *Parser) ParseType() (ast.Type, error) {
+	if !p.PeekIs(token.Dot) && !p.PeekIs(token.Mod) {
+		return p.Parser.ParseType()
+	}
+	var (
+		t     ast.Type
+		parts []string
+	)
+	for p.PeekIs(token.Dot) {
+		parts = append(parts, p.GetCurrLiteral())
+		p.Next()
+		p.Next()
+	}
+	if !p.Is(token.Ident) {
+		return t, p.Unexpected("type", "a valid identifier is expected")
+	}
+	parts = append(parts, p.GetCurrLiteral())
+	p.Next()
+	if !p.Is(token.Mod) {
+		return t, p.Unexpected("type", "expected %TYPE or %ROWTYPE attribute")
+	}
+	p.Next()
+	attr := strings.ToUpper(p.GetCurrLiteral())
+	if !p.Is(token.Ident) || (attr != "TYPE" && attr != "ROWTYPE") {
+		return t, p.Unexpected("type", "expected %TYPE or %ROWTYPE attribute")
+	}
+	p.Next()
+	t.Name = strings.Join(parts, ".") + "%" + attr
+	return t, nil
+}
+
+func (p *Parser) ParseDeclarations(done func() bool) ([]ast.Statement, error) {
+	var list []ast.Statement
+	for !p.Done() && !done() {
+		var (
+			start = p.Curr().Position
+			stmt  ast.Statement
+			err   error
+		)
+		switch {
+		case p.IsKeyword("CURSOR"):
+			stmt, err = p.ParseCursor()
+		case p.IsKeyword("PROCEDURE") || p.IsKeyword("FUNCTION"):
+			stmt, err = p.ParseSubprogram()
+		case p.Is(token.Ident):
+			stmt, err = p.ParseVariable()
+		default:
+			err = p.Unexpected("declare", "")
+		}
+		if err != nil {
+			return nil, err
+		}
+		stmt = p.Locate(start, stmt)
+		if err = p.Expect("declare", token.EOL); err != nil {
+			return nil, err
+		}
+		list = append(list, stmt)
+	}
+	if !done() {
+		return nil, p.Unexpected("declare", "")
+	}
+	return list, nil
+}
+
+func (p *Parser) ParseVariable() (ast.Statement, error) {
+	var (
+		stmt ast.Declare
+		err  error
+	)
+	stmt.Ident = p.GetCurrLiteral()
+	p.Next()
+	if p.IsKeyword("EXCEPTION") {
+		stmt.Type.Name = p.GetCurrLiteral()
+		p.Next()
+		return stmt, nil
+	}
+	if stmt.Type, err = p.ParseType(); err != nil {
+		return nil, err
+	}
+	if p.IsKeyword("DEFAULT") || p.Is(token.Assign) {
+		p.Next()
+		stmt.Value, err = p.StartExpression()
+	}
+	return stmt, err
+}
+
+func (p *Parser) ParseCursor() (ast.Statement, error) {
+	p.Next()
+	var (
+		stmt Cursor
+		err  error
+	)
+	if !p.Is(token.Ident) {
+		return nil, p.Unexpected("cursor", "a valid identifier is expected")
+	}
+	stmt.Ident = p.GetCurrLiteral()
+	p.Next()
+	if p.Is(token.Lparen) {
+		if stmt.Parameters, err = p.ParseProcedureParameters(); err != nil {
+			return nil, err
+		}
+	}
+	if !p.IsKeyword("IS") {
+		return nil, p.Unexpected("cursor", "expected IS keyword(s)")
+	}
+	p.Next()
+	stmt.Query, err = p.ParseStatement()
+	return stmt, err
+}
+
+func (p *Parser) ParseBlock() (ast.Statement, error) {
+	var (
+		block Block
+		err   error
+	)
+	if p.IsKeyword("DECLARE") {
+		p.Next()
+		if block.Declare, err = p.ParseDeclarations(p.KwCheck("BEGIN")); err != nil {
+			return nil, err
+		}
+	}
+	return p.parseBlock(block)
+}
+
+func (p *Parser) parseBlock(block Block) (ast.Statement, error) {
+	if !p.IsKeyword("BEGIN") {
+		return nil, p.Unexpected("block", "expected BEGIN keyword(s)")
+	}
+	p.Next()
+
+	var err error
+	if block.Body, err = p.ParseBody(p.KwCheck("EXCEPTION", "END")); err != nil {
+		return nil, err
+	}
+	if p.IsKeyword("EXCEPTION") {
+		p.Next()
+		if block.Handlers, err = p.ParseHandlers(); err != nil {
+			return nil, err
+		}
+	}
+	block.Label, err = p.parseEnd("block")
+	return block, err
+}
+
+func (p *Parser) ParseHandlers() ([]ast.Statement, error) {
+	var list []ast.Statement
+	for p.IsKeyword("WHEN") {
+		var (
+			start = p.Curr().Position
+			stmt  Handler
+			err   error
+		)
+		p.Next()
+		for {
+			if !p.Is(token.Ident) {
+				return nil, p.Unexpected("exception", "a valid identifier is expected")
+			}
+			stmt.Exceptions = append(stmt.Exceptions, p.GetCurrLiteral())
+			p.Next()
+			if !p.IsKeyword("OR") {
+				break
+			}
+			p.Next()
+		}
+		if !p.IsKeyword("THEN") {
+			return nil, p.Unexpected("exception", "expected THEN keyword(s)")
+		}
+		p.Next()
+		if stmt.Body, err = p.ParseBody(p.KwCheck("WHEN", "END")); err != nil {
+			return nil, err
+		}
+		list = append(list, p.Locate(start, stmt))
+	}
+	if len(list) == 0 {
+		return nil, p.Unexpected("exception", "expected WHEN keyword(s)")
+	}
+	return list, nil
+}
+
+func (p *Parser) parseEnd(ctx string) (string, error) {
+	if !p.IsKeyword("END") {
+		return "", p.Unexpected(ctx, "expected END keyword(s)")
+	}
+	p.Next()
+	var label string
+	if p.Is(token.Ident) {
+		label = p.GetCurrLiteral()
+		p.Next()
+	}
+	return label, nil
+}
+
+func (p *Parser) ParseBody(done func() bool) (ast.Statement, error) {
+	var list ast.List
+	for !p.Done() && !done() {
+		var (
+			start = p.Curr().Position
+			stmt  ast.Statement
+			err   error
+		)
+		if p.Is(token.Ident) {
+			stmt, err = p.ParseIdentStatement()
+		} else {
+			stmt, err = p.ParseStatement()
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !p.Is(token.EOL) {
+			return nil, p.Unexpected("body", "missing semicolon at end of statement")
+		}
+		list.Values = append(list.Values, p.Locate(start, stmt))
+		p.Next()
+	}
+	if !done() {
+		return nil, p.Unexpected("body", "")
+	}
+	return list, nil
+}
+
+func (p *Parser) ParseIdentStatement() (ast.Statement, error) {
+	ident, err := p.Parser.ParseIdentifier()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case p.Is(token.Assign):
+		p.Next()
+		stmt := ast.Set{
+			Ident: strings.Join(ident.(ast.Name).Parts, "."),
+		}
+		stmt.Expr, err = p.StartExpression()
+		return stmt, err
+	case p.Is(token.Lparen):
+		return p.parseCallArgs(ident)
+	case p.Is(token.EOL):
+		stmt := ast.CallStatement{
+			Ident: ident,
+		}
+		return stmt, nil
+	default:
+		return nil, p.Unexpected("statement", "assignment or procedure call expected")
+	}
+}
+
+func (p *Parser) parseCallArgs(ident ast.Statement) (ast.Statement, error) {
+	p.Next()
+	stmt := ast.CallStatement{
+		Ident: ident,
+	}
+	for !p.Done() && !p.Is(token.Rparen) {
+		if p.PeekIs(token.Arrow) && p.Is(token.Ident) {
+			stmt.Names = append(stmt.Names, p.GetCurrLiteral())
+			p.Next()
+			p.Next()
+		}
+		arg, err := p.StartExpression()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.EnsureEnd("call", token.Comma, token.Rparen); err != nil {
+			return nil, err
+		}
+		stmt.Args = append(stmt.Args, arg)
+	}
+	return stmt, p.Expect("call", token.Rparen)
+}
+
+func (p *Parser) ParseIf() (ast.Statement, error) {
+	p.Next()
+
+	var (
+		stmt ast.If
+		err  error
+	)
+	if stmt.Cdt, err = p.StartExpression(); err != nil {
+		return nil, err
+	}
+	if !p.IsKeyword("THEN") {
+		return nil, p.Unexpected("if", "expected THEN keyword(s)")
+	}
+	p.Next()
+	stmt.Csq, err = p.ParseBody(p.KwCheck("ELSE", "ELSIF", "END IF"))
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case p.IsKeyword("ELSE"):
+		p.Next()
+		stmt.Alt, err = p.ParseBody(p.KwCheck("END IF"))
+	case p.IsKeyword("ELSIF"):
+		stmt.Alt, err = p.ParseIf()
+		return stmt, err
+	case p.IsKeyword("END IF"):
+	default:
+		return nil, p.Unexpected("if", "")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !p.IsKeyword("END IF") {
+		return nil, p.Unexpected("if", "expected END IF keyword(s)")
+	}
+	p.Next()
+	return stmt, nil
+}
+
+func (p *Parser) ParseWhile() (ast.Statement, error) {
+	p.Next()
+	var (
+		stmt ast.While
+		err  error
+	)
+	if stmt.Cdt, err = p.StartExpression(); err != nil {
+		return nil, err
+	}
+	stmt.Body, err = p.parseLoopBody()
+	return stmt, err
+}
+
+func (p *Parser) ParseLoop() (ast.Statement, error) {
+	var (
+		stmt Loop
+		err  error
+	)
+	stmt.Body, err = p.parseLoopBody()
+	return stmt, err
+}
+
+func (p *Parser) ParseFor() (ast.Statement, error) {
+	p.Next()
+	var (
+		stmt ForLoop
+		err  error
+	)
+	if !p.Is(token.Ident) {
+		return nil, p.Unexpected("for", "a valid identifier is expected")
+	}
+	stmt.Ident = p.GetCurrLiteral()
+	p.Next()
+	if !p.IsKeyword("IN") {
+		return nil, p.Unexpected("for", "expected IN keyword(s)")
+	}
+	p.Next()
+	if p.Is(token.Lparen) && p.PeekIs(token.Keyword) {
+		p.Next()
+		if stmt.Cursor, err = p.ParseStatement(); err != nil {
+			return nil, err
+		}
+		if err = p.Expect("for", token.Rparen); err != nil {
+			return nil, err
+		}
+	} else {
+		if stmt.Cursor, err = p.StartExpression(); err != nil {
+			return nil, err
+		}
+	}
+	stmt.Body, err = p.parseLoopBody()
+	return stmt, err
+}
+
+func (p *Parser) parseLoopBody() (ast.Statement, error) {
+	if !p.IsKeyword("LOOP") {
+		return nil, p.Unexpected("loop", "expected LOOP keyword(s)")
+	}
+	p.Next()
+	body, err := p.ParseBody(p.KwCheck("END LOOP"))
+	if err != nil {
+		return nil, err
+	}
+	p.Next()
+	return body, nil
+}
+
+func (p *Parser) ParseExit() (ast.Statement, error) {
+	var (
+		stmt Exit
+		err  error
+		cdt  = p.IsKeyword("EXIT WHEN")
+	)
+	p.Next()
+	if cdt {
+		stmt.Cdt, err = p.StartExpression()
+	}
+	return stmt, err
+}
+
+func (p *Parser) ParseRaise() (ast.Statement, error) {
+	p.Next()
+	var stmt Raise
+	if p.Is(token.Ident) {
+		stmt.Exception = p.GetCurrLiteral()
+		p.Next()
+	}
+	return stmt, nil
+}
+
+func (p *Parser) ParseNull() (ast.Statement, error) {
+	p.Next()
+	return Null{}, nil
+}
+
+func (p *Parser) ParseReturn() (ast.Statement, error) {
+	p.Next()
+	var (
+		stmt ast.Return
+		err  error
+	)
+	if !p.Is(token.EOL) {
+		stmt.Statement, err = p.StartExpression()
+	}
+	return stmt, err
+}
+
+func (p *Parser) ParseSelect() (ast.Statement, error) {
+	start := p.Curr().Position
+	p.Next()
+	var (
+		stmt SelectStatement
+		err  error
+	)
+	if p.IsKeyword("DISTINCT") {
+		stmt.Distinct = true
+		p.Next()
+	}
+	if stmt.Columns, err = p.ParseColumns(); err != nil {
+		return nil, err
+	}
+	if p.IsKeyword("INTO") {
+		if stmt.Into, err = p.ParseInto(); err != nil {
+			return nil, err
+		}
+	}
+	if stmt.Tables, err = p.ParseFrom(); err != nil {
+		return nil, err
+	}
+	if stmt.Where, err = p.ParseWhere(); err != nil {
+		return nil, err
+	}
+	if err = p.ParseHierarchy(&stmt); err != nil {
+		return nil, err
+	}
+	if stmt.Groups, err = p.ParseGroupBy(); err != nil {
+		return nil, err
+	}
+	if stmt.Having, err = p.ParseHaving(); err != nil {
+		return nil, err
+	}
+	if stmt.Windows, err = p.ParseWindows(); err != nil {
+		return nil, err
+	}
+	if stmt.Orders, err = p.ParseOrderBy(); err != nil {
+		return nil, err
+	}
+	if stmt.Limit, err = p.ParseLimit(); err != nil {
+		return nil, err
+	}
+	if len(stmt.Into) == 0 && stmt.StartWith == nil && stmt.ConnectBy == nil {
+		return p.ParseCompound(p.Locate(start, stmt.SelectStatement))
+	}
+	return p.ParseCompound(p.Locate(start, stmt))
+}
+
+func (p *Parser) ParseInto() ([]ast.Statement, error) {
+	p.Next()
+	var list []ast.Statement
+	for !p.Done() && !p.IsKeyword("FROM") {
+		stmt, err := p.Parser.ParseIdentifier()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, stmt)
+		if err := p.EnsureEnd("into", token.Comma, token.Keyword); err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
+
+// ParseHierarchy parses the START WITH and CONNECT BY clauses of a hierarchical
+// query. Both clauses can be given in any order
+func (p *Parser) ParseHierarchy(stmt *SelectStatement) error {
+	var err error
+	for i := 0; i < 2; i++ {
+		switch {
+		case p.IsKeyword("START WITH") && stmt.StartWith == nil:
+			p.Next()
+			stmt.StartWith, err = p.StartExpression()
+		case p.IsKeyword("CONNECT BY") && stmt.ConnectBy == nil:
+			p.Next()
+			if p.IsKeyword("NOCYCLE") {
+				stmt.NoCycle = true
+				p.Next()
+			}
+			stmt.ConnectBy, err = p.StartExpression()
+		default:
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Parser) ParseIdentifier() (ast.Statement, error) {
+	ident, err := p.Parser.ParseIdentifier()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case p.IsKeyword("(+)"):
+		p.Next()
+		return OuterJoin{Statement: ident}, nil
+	case p.Is(token.Mod) && p.PeekIs(token.Ident):
+		p.Next()
+		attr := Attribute{
+			Ident: ident,
+			Name:  strings.ToUpper(p.GetCurrLiteral()),
+		}
+		p.Next()
+		return attr, nil
+	default:
+		return ident, nil
+	}
+}
+
+func (p *Parser) ParsePrior() (ast.Statement, error) {
+	p.Next()
+	ident, err := p.ParseIdentifier()
+	if err != nil {
+		return nil, err
+	}
+	return Prior{Statement: ident}, nil
+}
+
+func (p *Parser) ParseMerge() (ast.Statement, error) {
+	p.Next()
+	var (
+		stmt ast.MergeStatement
+		err  error
+	)
+	if stmt.Target, err = p.ParseIdent(); err != nil {
+		return nil, err
+	}
+	if !p.IsKeyword("USING") {
+		return nil, p.Unexpected("merge", "expected USING keyword(s)")
+	}
+	p.Next()
+	if stmt.Source, err = p.StartExpression(); err != nil {
+		return nil, err
+	}
+	if !p.IsKeyword("ON") {
+		return nil, p.Unexpected("merge", "expected ON keyword(s)")
+	}
+	p.Next()
+	if stmt.Join, err = p.StartExpression(); err != nil {
+		return nil, err
+	}
+	for !p.QueryEnds() && !p.Done() && !p.Is(token.EOL) {
+		var act ast.Statement
+		switch {
+		case p.IsKeyword("WHEN MATCHED"):
+			act, err = p.parseMergeMatched()
+		case p.IsKeyword("WHEN NOT MATCHED"):
+			act, err = p.parseMergeNotMatched()
+		default:
+			err = p.Unexpected("merge", "expected WHEN MATCHED|WHEN NOT MATCHED keyword(s)")
+		}
+		if err != nil {
+			return nil, err
+		}
+		stmt.Actions = append(stmt.Actions, act)
+	}
+	return stmt, nil
+}
+
+func (p *Parser) parseMergeMatched() (ast.Statement, error) {
+	p.Next()
+	if !p.IsKeyword("THEN") {
+		return nil, p.Unexpected("matched", "expected THEN keyword(s)")
+	}
+	p.Next()
+	if !p.IsKeyword("UPDATE") {
+		return nil, p.Unexpected("matched", "expected UPDATE keyword(s)")
+	}
+	p.Next()
+	if !p.IsKeyword("SET") {
+		return nil, p.Unexpected("matched", "expected SET keyword(s)")
+	}
+	p.Next()
+
+	var (
+		stmt ast.MatchStatement
+		upd  MergeUpdate
+		err  error
+		done = p.KwCheck("WHERE", "DELETE", "WHEN MATCHED", "WHEN NOT MATCHED")
+	)
+	for !p.Done() && !p.Is(token.EOL) && !p.QueryEnds() && !done() {
+		ass, err := p.ParseAssignment()
+		if err != nil {
+			return nil, err
+		}
+		upd.List = append(upd.List, ass)
+		if p.Is(token.Comma) {
+			p.Next()
+		}
+	}
+	if stmt.Condition, err = p.ParseWhere(); err != nil {
+		return nil, err
+	}
+	if p.IsKeyword("DELETE") {
+		p.Next()
+		if !p.IsKeyword("WHERE") {
+			return nil, p.Unexpected("matched", "expected WHERE keyword(s)")
+		}
+		if upd.Delete, err = p.ParseWhere(); err != nil {
+			return nil, err
+		}
+	}
+	stmt.Statement = upd
+	return stmt, nil
+}
+
+func (p *Parser) parseMergeNotMatched() (ast.Statement, error) {
+	p.Next()
+	if !p.IsKeyword("THEN") {
+		return nil, p.Unexpected("not matched", "expected THEN keyword(s)")
+	}
+	p.Next()
+	if !p.IsKeyword("INSERT") {
+		return nil, p.Unexpected("not matched", "expected INSERT keyword(s)")
+	}
+	p.Next()
+
+	var (
+		stmt ast.MatchStatement
+		ins  MergeInsert
+		err  error
+	)
+	if p.Is(token.Lparen) {
+		p.Next()
+		for !p.Done() && !p.Is(token.Rparen) {
+			ident, err := p.Parser.ParseIdentifier()
+			if err != nil {
+				return nil, err
+			}
+			ins.Columns = append(ins.Columns, strings.Join(ident.(ast.Name).Parts, "."))
+			if err := p.EnsureEnd("not matched", token.Comma, token.Rparen); err != nil {
+				return nil, err
+			}
+		}
+		if err := p.Expect("not matched", token.Rparen); err != nil {
+			return nil, err
+		}
+	}
+	if !p.IsKeyword("VALUES") {
+		return nil, p.Unexpected("not matched", "expected VALUES keyword(s)")
+	}
+	if ins.Values, err = p.ParseValues(); err != nil {
+		return nil, err
+	}
+	if stmt.Condition, err = p.ParseWhere(); err != nil {
+		return nil, err
+	}
+	stmt.Statement = ins
+	return stmt, nil
+}
@@ -0,0 +1,234 @@
+package ora_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/midbel/sweet/internal/lang/ast"
+	"github.com/midbel/sweet/internal/ora"
+)
+
+func TestParser(t *testing.T) {
+	files := []string{
+		"select.sql",
+		"block.sql",
+		"package.sql",
+	}
+	for _, f := range files {
+		testFile(t, f)
+	}
+}
+
+func TestParseSelect(t *testing.T) {
+	list := testFile(t, "select.sql")
+	if len(list) != 4 {
+		t.Fatalf("number of statements mismatched! want 4, got %d", len(list))
+	}
+	hier, ok := list[0].(ora.SelectStatement)
+	if !ok {
+		t.Fatalf("hierarchical query: ora.SelectStatement expected! got %T", list[0])
+	}
+	if hier.StartWith == nil || hier.ConnectBy == nil || hier.NoCycle {
+		t.Errorf("hierarchical query: start with/connect by mismatched")
+	}
+	if _, ok := hier.ConnectBy.(ast.Binary); !ok {
+		t.Errorf("hierarchical query: ast.Binary expected for connect by! got %T", hier.ConnectBy)
+	}
+	if len(hier.Orders) != 1 {
+		t.Errorf("hierarchical query: order by expected")
+	}
+
+	plain, ok := list[1].(ast.SelectStatement)
+	if !ok {
+		t.Fatalf("plain query: ast.SelectStatement expected! got %T", list[1])
+	}
+	where, ok := plain.Where.(ast.Binary)
+	if !ok {
+		t.Fatalf("plain query: ast.Binary expected for where! got %T", plain.Where)
+	}
+	cmp, ok := where.Left.(ast.Binary)
+	if !ok {
+		t.Fatalf("plain query: ast.Binary expected for join! got %T", where.Left)
+	}
+	if _, ok := cmp.Right.(ora.OuterJoin); !ok {
+		t.Errorf("plain query: ora.OuterJoin expected! got %T", cmp.Right)
+	}
+
+	cycle, ok := list[2].(ora.SelectStatement)
+	if !ok {
+		t.Fatalf("nocycle query: ora.SelectStatement expected! got %T", list[2])
+	}
+	if !cycle.NoCycle || cycle.StartWith == nil {
+		t.Errorf("nocycle query: nocycle/start with mismatched")
+	}
+	if b, ok := cycle.ConnectBy.(ast.Binary); !ok {
+		t.Errorf("nocycle query: ast.Binary expected for connect by! got %T", cycle.ConnectBy)
+	} else if _, ok := b.Left.(ora.Prior); !ok {
+		t.Errorf("nocycle query: ora.Prior expected! got %T", b.Left)
+	}
+
+	merge, ok := list[3].(ast.MergeStatement)
+	if !ok {
+		t.Fatalf("merge: ast.MergeStatement expected! got %T", list[3])
+	}
+	if len(merge.Actions) != 2 {
+		t.Fatalf("merge: number of actions mismatched! want 2, got %d", len(merge.Actions))
+	}
+	matched := merge.Actions[0].(ast.MatchStatement)
+	if upd, ok := matched.Statement.(ora.MergeUpdate); !ok {
+		t.Errorf("merge: ora.MergeUpdate expected! got %T", matched.Statement)
+	} else if len(upd.List) != 1 || upd.Delete == nil || matched.Condition == nil {
+		t.Errorf("merge: update set/where/delete mismatched")
+	}
+	missing := merge.Actions[1].(ast.MatchStatement)
+	if ins, ok := missing.Statement.(ora.MergeInsert); !ok {
+		t.Errorf("merge: ora.MergeInsert expected! got %T", missing.Statement)
+	} else if len(ins.Columns) != 2 || ins.Values == nil || missing.Condition == nil {
+		t.Errorf("merge: insert columns/values/where mismatched")
+	}
+}
+
+func TestParseBlock(t *testing.T) {
+	list := testFile(t, "block.sql")
+	if len(list) != 2 {
+		t.Fatalf("number of statements mismatched! want 2, got %d", len(list))
+	}
+	block, ok := list[0].(ora.Block)
+	if !ok {
+		t.Fatalf("ora.Block expected! got %T", list[0])
+	}
+	var names []string
+	for _, d := range block.Declare {
+		v, ok := d.(ast.Declare)
+		if !ok {
+			t.Fatalf("ast.Declare expected! got %T", d)
+		}
+		names = append(names, v.Ident+" "+v.Type.Name)
+	}
+	want := []string{"v_total NUMBER", "v_name employees.name%TYPE", "e_empty EXCEPTION"}
+	if !slices.Equal(names, want) {
+		t.Errorf("declarations mismatched! want %q, got %q", want, names)
+	}
+	body, ok := block.Body.(ast.List)
+	if !ok {
+		t.Fatalf("ast.List expected for body! got %T", block.Body)
+	}
+	var kinds []string
+	for _, s := range body.Values {
+		kinds = append(kinds, fmt.Sprintf("%T", s))
+	}
+	want = []string{"ora.ForLoop", "ast.While", "ora.Loop", "ast.If"}
+	if !slices.Equal(kinds, want) {
+		t.Errorf("body mismatched! want %q, got %q", want, kinds)
+	}
+	if len(block.Handlers) != 1 {
+		t.Fatalf("number of handlers mismatched! want 1, got %d", len(block.Handlers))
+	}
+	h := block.Handlers[0].(ora.Handler)
+	if want := []string{"e_empty", "NO_DATA_FOUND"}; !slices.Equal(h.Exceptions, want) {
+		t.Errorf("exceptions mismatched! want %q, got %q", want, h.Exceptions)
+	}
+
+	block, ok = list[1].(ora.Block)
+	if !ok {
+		t.Fatalf("ora.Block expected! got %T", list[1])
+	}
+	body = block.Body.(ast.List)
+	if len(body.Values) != 2 {
+		t.Fatalf("number of calls mismatched! want 2, got %d", len(body.Values))
+	}
+	call, ok := body.Values[1].(ast.CallStatement)
+	if !ok {
+		t.Fatalf("ast.CallStatement expected! got %T", body.Values[1])
+	}
+	if want := []string{"p_level", "p_text"}; !slices.Equal(call.Names, want) || len(call.Args) != 2 {
+		t.Errorf("named arguments mismatched! want %q, got %q", want, call.Names)
+	}
+}
+
+func TestParsePackage(t *testing.T) {
+	list := testFile(t, "package.sql")
+	if len(list) != 2 {
+		t.Fatalf("number of statements mismatched! want 2, got %d", len(list))
+	}
+	spec, ok := list[0].(ora.CreatePackageStatement)
+	if !ok {
+		t.Fatalf("ora.CreatePackageStatement expected! got %T", list[0])
+	}
+	if !spec.Replace || spec.Body || len(spec.Declare) != 4 {
+		t.Fatalf("package spec mismatched")
+	}
+	if c, ok := spec.Declare[1].(ora.Cursor); !ok {
+		t.Errorf("ora.Cursor expected! got %T", spec.Declare[1])
+	} else if c.Ident != "c_emp" || len(c.Parameters) != 1 {
+		t.Errorf("cursor mismatched")
+	}
+	for _, d := range spec.Declare[2:] {
+		proc, ok := d.(ora.CreateProcedureStatement)
+		if !ok {
+			t.Errorf("ora.CreateProcedureStatement expected! got %T", d)
+		} else if proc.Body != nil {
+			t.Errorf("no body expected for subprogram specification")
+		}
+	}
+
+	body, ok := list[1].(ora.CreatePackageStatement)
+	if !ok {
+		t.Fatalf("ora.CreatePackageStatement expected! got %T", list[1])
+	}
+	if !body.Body || len(body.Declare) != 2 {
+		t.Fatalf("package body mismatched")
+	}
+	fn, ok := body.Declare[1].(ora.CreateProcedureStatement)
+	if !ok {
+		t.Fatalf("ora.CreateProcedureStatement expected! got %T", body.Declare[1])
+	}
+	if !fn.Function || fn.Return.Name != "NUMBER" {
+		t.Errorf("function mismatched")
+	}
+	block, ok := fn.Body.(ora.Block)
+	if !ok {
+		t.Fatalf("ora.Block expected! got %T", fn.Body)
+	}
+	if block.Label != "count_employees" || len(block.Declare) != 1 {
+		t.Errorf("function body mismatched")
+	}
+}
+
+func testFile(t *testing.T, file string) []ast.Statement {
+	t.Helper()
+
+	r, err := os.Open(filepath.Join("testdata", file))
+	if err != nil {
+		t.Errorf("fail to open file %s (%s)", file, err)
+		return nil
+	}
+	defer r.Close()
+
+	p, err := ora.Parse(r)
+	if err != nil {
+		t.Errorf("fail to create parser for file %s (%s)", file, err)
+		return nil
+	}
+	var list []ast.Statement
+	for {
+		stmt, err := p.Parse()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			t.Errorf("error parsing statement in %s: %s", file, err)
+			continue
+		}
+		if n, ok := stmt.(ast.Node); ok {
+			stmt = n.Statement
+		}
+		list = append(list, stmt)
+	}
+	return list
+}
@@ -0,0 +1,77 @@
+package ora
+
+import (
+	"io"
+	"unicode/utf8"
+
+	"github.com/midbel/sweet/internal/scanner"
+	"github.com/midbel/sweet/internal/token"
+)
+
+func Scan(r io.Reader) (*scanner.Scanner, error) {
+	scan, err := scanner.Scan(r, GetKeywords())
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tokenizers() {
+		scan.Register(t)
+	}
+	return scan, err
+}
+
+func tokenizers() []scanner.Tokenizer {
+	return []scanner.Tokenizer{
+		blockEnd{},
+		outerJoin{},
+		assign{},
+	}
+}
+
+// blockEnd recognizes the slash used by SQL*Plus to terminate a PL/SQL block.
+// It is only a terminator when alone at the start of its line
+type blockEnd struct{}
+
+func (_ blockEnd) Can(curr, peek rune) bool {
+	return curr == '/' && (peek == '\n' || peek == '\r' || peek == utf8.RuneError || peek == 0)
+}
+
+func (_ blockEnd) Scan(scan *scanner.Scanner, tok *token.Token) {
+	tok.Type = token.Slash
+	if tok.Column == 1 {
+		tok.Type = token.EOL
+	}
+	scan.Read()
+}
+
+type outerJoin struct{}
+
+func (_ outerJoin) Can(curr, peek rune) bool {
+	return curr == '(' && peek == '+'
+}
+
+func (_ outerJoin) Scan(scan *scanner.Scanner, tok *token.Token) {
+	scan.Save()
+	scan.Read()
+	scan.Read()
+	if scan.Curr() == ')' {
+		scan.Read()
+		tok.Type = token.Keyword
+		tok.Literal = "(+)"
+		return
+	}
+	scan.Restore()
+	scan.Read()
+	tok.Type = token.Lparen
+}
+
+type assign struct{}
+
+func (_ assign) Can(curr, peek rune) bool {
+	return curr == ':' && peek == '='
+}
+
+func (_ assign) Scan(scan *scanner.Scanner, tok *token.Token) {
+	scan.Read()
+	scan.Read()
+	tok.Type = token.Assign
+}
@@ -0,0 +1,29 @@
+DECLARE
+	v_total NUMBER := 0;
+	v_name employees.name%TYPE;
+	e_empty EXCEPTION;
+BEGIN
+	FOR rec IN (SELECT id, salary FROM employees WHERE ROWNUM <= 10) LOOP
+		v_total := v_total + NVL(rec.salary, 0);
+	END LOOP;
+	WHILE v_total > 1000 LOOP
+		v_total := v_total - 100;
+	END LOOP;
+	LOOP
+		v_total := v_total - 1;
+		EXIT WHEN v_total < 0;
+	END LOOP;
+	IF v_total = 0 THEN
+		RAISE e_empty;
+	END IF;
+EXCEPTION
+	WHEN e_empty OR NO_DATA_FOUND THEN
+		NULL;
+END;
+/
+
+BEGIN
+	log_message;
+	log_message(p_level => 'INFO', p_text => 'done');
+END;
+/
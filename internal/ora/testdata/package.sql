@@ -0,0 +1,37 @@
+CREATE OR REPLACE PACKAGE emp_mgmt AS
+	max_salary employees.salary%TYPE := 10000;
+	CURSOR c_emp (p_dept IN NUMBER) IS SELECT * FROM employees WHERE dept_id = p_dept;
+	PROCEDURE raise_salary(p_id IN employees.id%TYPE, p_pct IN NUMBER DEFAULT 10);
+	FUNCTION count_employees(p_dept NUMBER) RETURN NUMBER;
+END emp_mgmt;
+/
+
+CREATE OR REPLACE PACKAGE BODY emp_mgmt AS
+	PROCEDURE raise_salary(p_id IN employees.id%TYPE, p_pct IN NUMBER DEFAULT 10) IS
+		v_emp employees%ROWTYPE;
+	BEGIN
+		SELECT * INTO v_emp FROM employees WHERE id = p_id;
+		IF v_emp.salary * (1 + p_pct / 100) > max_salary THEN
+			RAISE salary_too_high;
+		ELSIF p_pct = 0 THEN
+			NULL;
+		ELSE
+			UPDATE employees SET salary = salary * (1 + p_pct / 100) WHERE id = p_id;
+		END IF;
+	EXCEPTION
+		WHEN NO_DATA_FOUND THEN
+			dbms_output.put_line('unknown employee');
+		WHEN OTHERS THEN
+			RAISE;
+	END raise_salary;
+
+	FUNCTION count_employees(p_dept NUMBER) RETURN NUMBER IS
+		v_count NUMBER := 0;
+	BEGIN
+		FOR rec IN c_emp(p_dept) LOOP
+			v_count := v_count + 1;
+		END LOOP;
+		RETURN v_count;
+	END count_employees;
+END emp_mgmt;
+/
@@ -0,0 +1,21 @@
+SELECT employee_id, manager_id, DECODE(level, 1, 'root', 'node') AS kind
+FROM employees
+START WITH manager_id IS NULL
+CONNECT BY PRIOR employee_id = manager_id
+ORDER BY employee_id;
+
+SELECT e.name, d.name
+FROM employees e, departments d
+WHERE e.dept_id = d.id(+)
+AND ROWNUM <= 10;
+
+SELECT id FROM categories CONNECT BY NOCYCLE PRIOR id = parent_id START WITH parent_id IS NULL;
+
+MERGE INTO employees e
+USING (SELECT id, salary FROM staging) s
+ON (e.id = s.id)
+WHEN MATCHED THEN
+	UPDATE SET e.salary = s.salary WHERE s.salary > 0
+	DELETE WHERE s.salary IS NULL
+WHEN NOT MATCHED THEN
+	INSERT (id, salary) VALUES (s.id, s.salary) WHERE s.salary > 0;
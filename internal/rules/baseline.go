@@ -0,0 +1,122 @@
+package rules
+
+import (
+	"cmp"
+	"encoding/json"
+	"os"
+	"slices"
+	"sync"
+)
+
+// BaselineEntry is a message recorded in a baseline. Its line is only given
+// for information: messages are matched on their file, rule and text so that
+// the baseline survives the lines added or removed around them.
+type BaselineEntry struct {
+	File    string `json:"file"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+	Line    int    `json:"line"`
+}
+
+type baselineKey struct {
+	File    string
+	Rule    string
+	Message string
+}
+
+// Baseline holds the messages known when it has been recorded. It can be used
+// by multiple goroutines.
+type Baseline struct {
+	mu      sync.Mutex
+	known   map[baselineKey]int
+	entries []BaselineEntry
+}
+
+func NewBaseline() *Baseline {
+	return &Baseline{
+		known: make(map[baselineKey]int),
+	}
+}
+
+// LoadBaseline reads a baseline previously saved in file
+func LoadBaseline(file string) (*Baseline, error) {
+	buf, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var entries []BaselineEntry
+	if err := json.Unmarshal(buf, &entries); err != nil {
+		return nil, err
+	}
+	b := NewBaseline()
+	for _, e := range entries {
+		b.add(e)
+	}
+	return b, nil
+}
+
+// Record adds the given messages to the baseline
+func (b *Baseline) Record(list []LintMessage) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, m := range list {
+		e := BaselineEntry{
+			File:    m.File,
+			Rule:    m.Rule,
+			Message: m.Message,
+			Line:    m.Position.Line,
+		}
+		b.add(e)
+	}
+}
+
+// Filter gives the messages that are not in the baseline. A message found n
+// times in the baseline is only filtered the n first times it is given.
+func (b *Baseline) Filter(list []LintMessage) []LintMessage {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return slices.DeleteFunc(list, func(m LintMessage) bool {
+		k := baselineKey{
+			File:    m.File,
+			Rule:    m.Rule,
+			Message: m.Message,
+		}
+		if b.known[k] == 0 {
+			return false
+		}
+		b.known[k]--
+		return true
+	})
+}
+
+// Save writes the messages of the baseline in file ordered by file and line
+func (b *Baseline) Save(file string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	entries := slices.Clone(b.entries)
+	slices.SortStableFunc(entries, func(a, b BaselineEntry) int {
+		if c := cmp.Compare(a.File, b.File); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Line, b.Line)
+	})
+	if entries == nil {
+		entries = []BaselineEntry{}
+	}
+	buf, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, append(buf, '\n'), 0o644)
+}
+
+func (b *Baseline) add(e BaselineEntry) {
+	k := baselineKey{
+		File:    e.File,
+		Rule:    e.Rule,
+		Message: e.Message,
+	}
+	b.known[k]++
+	b.entries = append(b.entries, e)
+}
@@ -0,0 +1,118 @@
+package rules_test
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/midbel/sweet/internal/rules"
+	"github.com/midbel/sweet/internal/token"
+)
+
+func getMessages() []rules.LintMessage {
+	return []rules.LintMessage{
+		{
+			Rule:     "safety.where.missing",
+			Message:  "delete without where clause affects all rows",
+			File:     "db/query.sql",
+			Position: token.Position{Line: 10, Column: 1},
+		},
+		{
+			Rule:     "alias.missing",
+			Message:  "alias expected",
+			File:     "db/query.sql",
+			Position: token.Position{Line: 3, Column: 8},
+		},
+		{
+			Rule:     "alias.missing",
+			Message:  "alias expected",
+			File:     "db/query.sql",
+			Position: token.Position{Line: 5, Column: 8},
+		},
+		{
+			Rule:     "naming.table",
+			Message:  "name mismatch",
+			File:     "db/changes.sql",
+			Position: token.Position{Line: 1, Column: 14},
+		},
+	}
+}
+
+func TestBaselineSave(t *testing.T) {
+	var (
+		base = rules.NewBaseline()
+		file = filepath.Join(t.TempDir(), "baseline.json")
+	)
+	base.Record(getMessages())
+	if err := base.Save(file); err != nil {
+		t.Fatalf("fail to save baseline: %s", err)
+	}
+	got, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile(filepath.Join("testdata", "baseline.json"))
+	if err != nil {
+		t.Fatalf("fail to read golden file: %s", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("baseline mismatched!\nwant:\n%s\ngot:\n%s", want, got)
+	}
+
+	if err := rules.NewBaseline().Save(file); err != nil {
+		t.Fatalf("fail to save empty baseline: %s", err)
+	}
+	if got, _ := os.ReadFile(file); string(got) != "[]\n" {
+		t.Errorf("empty baseline mismatched! want %q, got %q", "[]\n", got)
+	}
+}
+
+func TestBaselineFilter(t *testing.T) {
+	base, err := rules.LoadBaseline(filepath.Join("testdata", "baseline.json"))
+	if err != nil {
+		t.Fatalf("fail to load baseline: %s", err)
+	}
+	list := getMessages()
+	// the lines of the messages changed but they are still known
+	for i := range list {
+		list[i].Position.Line += 2
+	}
+	list = append(list, rules.LintMessage{
+		Rule:     "alias.missing",
+		Message:  "alias expected",
+		File:     "db/query.sql",
+		Position: token.Position{Line: 20, Column: 8},
+	}, rules.LintMessage{
+		Rule:     "safety.where.missing",
+		Message:  "delete without where clause affects all rows",
+		File:     "db/other.sql",
+		Position: token.Position{Line: 1, Column: 1},
+	})
+	got := base.Filter(list)
+	if len(got) != 2 {
+		t.Fatalf("messages count mismatched! want 2, got %d", len(got))
+	}
+	if got[0].Position.Line != 20 || got[1].File != "db/other.sql" {
+		t.Errorf("unexpected messages kept: %v", got)
+	}
+	if got := base.Filter(getMessages()); len(got) != len(getMessages()) {
+		t.Errorf("known messages filtered twice! want %d, got %d", len(getMessages()), len(got))
+	}
+}
+
+func TestLoadBaseline(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := rules.LoadBaseline(filepath.Join(dir, "missing.json")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("not exist error expected! got %v", err)
+	}
+	file := filepath.Join(dir, "bad.json")
+	if err := os.WriteFile(file, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rules.LoadBaseline(file); err == nil {
+		t.Errorf("error expected for invalid baseline")
+	}
+}
@@ -30,6 +30,8 @@ func GetLevelFromName(name string) Level {
 	switch name {
 	case "", "default":
 		return Default
+	case "debug":
+		return Debug
 	case "info":
 		return Info
 	case "warning":
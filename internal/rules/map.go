@@ -2,6 +2,7 @@ package rules
 
 import (
 	"slices"
+	"strings"
 )
 
 type RuleFunc[T any] func(T) ([]LintMessage, error)
@@ -23,6 +24,9 @@ func (r Map[T]) Get() []RuleFunc[T] {
 		tmp = append(tmp, fn)
 	}
 	slices.SortFunc(tmp, func(a, b RegisteredRule[T]) int {
+		if a.Priority == b.Priority {
+			return strings.Compare(a.Name, b.Name)
+		}
 		return a.Priority - b.Priority
 	})
 	for i := range tmp {
@@ -1,9 +1,26 @@
 package rules
 
+import (
+	"fmt"
+
+	"github.com/midbel/sweet/internal/token"
+)
+
 type LintMessage struct {
 	Severity Level
 	Rule     string
 	Message  string
+	File     string
+	Position token.Position
+}
+
+// Location gives the position of the message as file:line:col. The file is
+// omitted when it is not known.
+func (m LintMessage) Location() string {
+	if m.File == "" {
+		return m.Position.String()
+	}
+	return fmt.Sprintf("%s:%s", m.File, m.Position)
 }
 
 type LintInfo struct {
@@ -0,0 +1,333 @@
+package rules
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+const (
+	FormatText       = "text"
+	FormatJSON       = "json"
+	FormatSarif      = "sarif"
+	FormatCheckstyle = "checkstyle"
+	FormatJUnit      = "junit"
+	FormatGithub     = "github"
+)
+
+// HelpURL is the page documenting the rules
+const HelpURL = "https://github.com/midbel/sweet/blob/main/docs/rules.md"
+
+// Help gives the link to the documentation of the given rule
+func Help(rule string) string {
+	group, _, _ := strings.Cut(rule, ".")
+	return HelpURL + "#" + group
+}
+
+// Report is the messages given by the linter for a file
+type Report struct {
+	File     string
+	Messages []LintMessage
+}
+
+// Streaming reports whether the reports can be rendered one file at a time.
+// Other formats give a single document for all the files.
+func Streaming(format string) bool {
+	switch format {
+	case FormatText, FormatGithub, "":
+		return true
+	default:
+		return false
+	}
+}
+
+// Render writes the messages of the reports in the given format
+func Render(w io.Writer, format string, reports []Report) error {
+	switch format {
+	case FormatText, "":
+		return renderText(w, reports)
+	case FormatGithub:
+		return renderGithub(w, reports)
+	case FormatJSON:
+		return renderJSON(w, reports)
+	case FormatSarif:
+		return renderSarif(w, reports)
+	case FormatCheckstyle:
+		return renderCheckstyle(w, reports)
+	case FormatJUnit:
+		return renderJUnit(w, reports)
+	default:
+		return fmt.Errorf("%s: unsupported lint format", format)
+	}
+}
+
+func renderText(w io.Writer, reports []Report) error {
+	for _, r := range reports {
+		for _, m := range r.Messages {
+			fmt.Fprintf(w, "%s: %s (%s): %s", m.Location(), m.Rule, m.Severity, m.Message)
+			fmt.Fprintln(w)
+		}
+	}
+	return nil
+}
+
+// renderGithub writes the messages as workflow commands so that they are shown
+// as annotations of the pull requests
+func renderGithub(w io.Writer, reports []Report) error {
+	var (
+		data   = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+		escape = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+	)
+	for _, r := range reports {
+		for _, m := range r.Messages {
+			kind := "notice"
+			switch m.Severity {
+			case Error:
+				kind = "error"
+			case Warning:
+				kind = "warning"
+			}
+			fmt.Fprintf(w, "::%s file=%s,line=%d,col=%d,title=%s::%s", kind, escape.Replace(m.File), m.Position.Line, m.Position.Column, escape.Replace(m.Rule), data.Replace(m.Message))
+			fmt.Fprintln(w)
+		}
+	}
+	return nil
+}
+
+type jsonMessage struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	Help     string `json:"help"`
+}
+
+func renderJSON(w io.Writer, reports []Report) error {
+	list := []jsonMessage{}
+	for _, r := range reports {
+		for _, m := range r.Messages {
+			j := jsonMessage{
+				File:     m.File,
+				Line:     m.Position.Line,
+				Column:   m.Position.Column,
+				Rule:     m.Rule,
+				Severity: m.Severity.String(),
+				Message:  m.Message,
+				Help:     Help(m.Rule),
+			}
+			list = append(list, j)
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(list)
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID      string `json:"id"`
+	HelpURI string `json:"helpUri"`
+}
+
+type sarifText struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifText       `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	Physical struct {
+		Artifact struct {
+			URI string `json:"uri"`
+		} `json:"artifactLocation"`
+		Region struct {
+			StartLine   int `json:"startLine,omitempty"`
+			StartColumn int `json:"startColumn,omitempty"`
+		} `json:"region"`
+	} `json:"physicalLocation"`
+}
+
+func renderSarif(w io.Writer, reports []Report) error {
+	run := sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:           "sweet",
+				InformationURI: HelpURL,
+				Rules:          []sarifRule{},
+			},
+		},
+		Results: []sarifResult{},
+	}
+	var seen []string
+	for _, r := range reports {
+		for _, m := range r.Messages {
+			if !slices.Contains(seen, m.Rule) {
+				seen = append(seen, m.Rule)
+				run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: m.Rule, HelpURI: Help(m.Rule)})
+			}
+			res := sarifResult{
+				RuleID:  m.Rule,
+				Level:   "note",
+				Message: sarifText{Text: m.Message},
+			}
+			switch m.Severity {
+			case Error:
+				res.Level = "error"
+			case Warning:
+				res.Level = "warning"
+			}
+			var loc sarifLocation
+			loc.Physical.Artifact.URI = m.File
+			loc.Physical.Region.StartLine = m.Position.Line
+			loc.Physical.Region.StartColumn = m.Position.Column
+			res.Locations = append(res.Locations, loc)
+
+			run.Results = append(run.Results, res)
+		}
+	}
+	doc := sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+type checkstyleError struct {
+	Line     int    `xml:"line,attr"`
+	Column   int    `xml:"column,attr"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+type checkstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []checkstyleError `xml:"error"`
+}
+
+type checkstyleDoc struct {
+	XMLName xml.Name         `xml:"checkstyle"`
+	Version string           `xml:"version,attr"`
+	Files   []checkstyleFile `xml:"file"`
+}
+
+func renderCheckstyle(w io.Writer, reports []Report) error {
+	doc := checkstyleDoc{
+		Version: "4.3",
+	}
+	for _, r := range reports {
+		file := checkstyleFile{
+			Name: r.File,
+		}
+		for _, m := range r.Messages {
+			e := checkstyleError{
+				Line:     m.Position.Line,
+				Column:   m.Position.Column,
+				Severity: m.Severity.String(),
+				Message:  m.Message,
+				Source:   m.Rule,
+			}
+			file.Errors = append(file.Errors, e)
+		}
+		doc.Files = append(doc.Files, file)
+	}
+	return renderXML(w, doc)
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitDoc struct {
+	XMLName xml.Name     `xml:"testsuites"`
+	Suites  []junitSuite `xml:"testsuite"`
+}
+
+// renderJUnit writes a test suite for each file with a failed test for each of
+// its messages. Files without messages have a single successful test.
+func renderJUnit(w io.Writer, reports []Report) error {
+	var doc junitDoc
+	for _, r := range reports {
+		suite := junitSuite{
+			Name: r.File,
+		}
+		for _, m := range r.Messages {
+			c := junitCase{
+				Name:      fmt.Sprintf("%s:%s", m.Rule, m.Position),
+				ClassName: r.File,
+				Failure: &junitFailure{
+					Message: m.Message,
+					Type:    m.Severity.String(),
+					Text:    fmt.Sprintf("%s: %s (%s): %s\n%s", m.Location(), m.Rule, m.Severity, m.Message, Help(m.Rule)),
+				},
+			}
+			suite.Cases = append(suite.Cases, c)
+		}
+		suite.Failures = len(suite.Cases)
+		if len(suite.Cases) == 0 {
+			suite.Cases = append(suite.Cases, junitCase{Name: "lint", ClassName: r.File})
+		}
+		suite.Tests = len(suite.Cases)
+		doc.Suites = append(doc.Suites, suite)
+	}
+	return renderXML(w, doc)
+}
+
+func renderXML(w io.Writer, doc any) error {
+	io.WriteString(w, xml.Header)
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
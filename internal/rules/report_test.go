@@ -0,0 +1,105 @@
+package rules_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/midbel/sweet/internal/rules"
+	"github.com/midbel/sweet/internal/token"
+)
+
+func getReports() []rules.Report {
+	return []rules.Report{
+		{
+			File: "db/query.sql",
+			Messages: []rules.LintMessage{
+				{
+					Severity: rules.Error,
+					Rule:     "safety.where.missing",
+					Message:  "delete without where clause affects all rows",
+					File:     "db/query.sql",
+					Position: token.Position{Line: 1, Column: 1},
+				},
+				{
+					Severity: rules.Warning,
+					Rule:     "alias.missing",
+					Message:  "alias expected for \"e\" <employees> & 100% of the rows",
+					File:     "db/query.sql",
+					Position: token.Position{Line: 3, Column: 8},
+				},
+			},
+		},
+		{
+			File: "empty.sql",
+		},
+		{
+			File: "db/a,b:c.sql",
+			Messages: []rules.LintMessage{
+				{
+					Severity: rules.Info,
+					Rule:     "naming.table",
+					Message:  "name mismatch\non two lines",
+					File:     "db/a,b:c.sql",
+					Position: token.Position{Line: 12, Column: 4},
+				},
+			},
+		},
+	}
+}
+
+func TestRender(t *testing.T) {
+	formats := []string{
+		rules.FormatText,
+		rules.FormatGithub,
+		rules.FormatJSON,
+		rules.FormatSarif,
+		rules.FormatCheckstyle,
+		rules.FormatJUnit,
+	}
+	for _, f := range formats {
+		want, err := os.ReadFile(filepath.Join("testdata", "report."+f))
+		if err != nil {
+			t.Errorf("%s: fail to read golden file: %s", f, err)
+			continue
+		}
+		var buf bytes.Buffer
+		if err := rules.Render(&buf, f, getReports()); err != nil {
+			t.Errorf("%s: fail to render reports: %s", f, err)
+			continue
+		}
+		if got := buf.Bytes(); !bytes.Equal(got, want) {
+			t.Errorf("%s: output mismatched!\nwant:\n%s\ngot:\n%s", f, want, got)
+		}
+	}
+}
+
+func TestRenderEmpty(t *testing.T) {
+	tests := []struct {
+		Format string
+		Want   string
+	}{
+		{
+			Format: rules.FormatText,
+			Want:   "",
+		},
+		{
+			Format: rules.FormatJSON,
+			Want:   "[]\n",
+		},
+	}
+	for _, c := range tests {
+		var buf bytes.Buffer
+		if err := rules.Render(&buf, c.Format, nil); err != nil {
+			t.Errorf("%s: fail to render reports: %s", c.Format, err)
+			continue
+		}
+		if got := buf.String(); got != c.Want {
+			t.Errorf("%s: output mismatched! want %q, got %q", c.Format, c.Want, got)
+		}
+	}
+	if err := rules.Render(&bytes.Buffer{}, "xml", nil); err == nil {
+		t.Errorf("xml: error expected for unsupported format")
+	}
+}
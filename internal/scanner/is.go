@@ -1,5 +1,9 @@
 package scanner
 
+import (
+	"unicode"
+)
+
 const (
 	minus      = '-'
 	comma      = ','
@@ -29,6 +33,7 @@ const (
 	question   = '?'
 	colon      = ':'
 	dollar     = '$'
+	backslash  = '\\'
 )
 
 func IsPlaceholder(r rune) bool {
@@ -48,13 +53,42 @@ func IsComment(r, k rune) bool {
 }
 
 func IsLetter(r rune) bool {
-	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+	return unicode.IsLetter(r)
+}
+
+func IsIdentStart(r rune) bool {
+	return IsLetter(r) || r == underscore
 }
 
 func IsDigit(r rune) bool {
 	return r >= '0' && r <= '9'
 }
 
+func IsHex(r rune) bool {
+	return IsDigit(r) || (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F')
+}
+
+func IsBinary(r rune) bool {
+	return r == '0' || r == '1'
+}
+
+func IsOctal(r rune) bool {
+	return r >= '0' && r <= '7'
+}
+
+// IsStringPrefix reports whether the given runes are the start of a prefixed
+// string literal such as N”, X”, B” or U&”
+func IsStringPrefix(r, k rune) bool {
+	switch unicode.ToUpper(r) {
+	case 'N', 'X', 'B':
+		return k == squote
+	case 'U':
+		return k == ampersand
+	default:
+		return false
+	}
+}
+
 func IsSpace(r rune) bool {
 	return r == space || r == tab
 }
@@ -0,0 +1,155 @@
+package scanner
+
+import (
+	"fmt"
+	"unicode"
+
+	"github.com/midbel/sweet/internal/token"
+)
+
+// QuotedIdent gives a tokenizer for identifiers enclosed between the given
+// delimiters, such as [ident] or `ident`
+func QuotedIdent(open, close rune) Tokenizer {
+	return quotedIdent{
+		open:  open,
+		close: close,
+	}
+}
+
+type quotedIdent struct {
+	open  rune
+	close rune
+}
+
+func (q quotedIdent) Can(curr, _ rune) bool {
+	return curr == q.open
+}
+
+func (q quotedIdent) Scan(scan *Scanner, tok *token.Token) {
+	scan.Read()
+	for !scan.Done() && scan.Curr() != q.close {
+		scan.Write()
+		scan.Read()
+	}
+	tok.Literal = scan.Literal()
+	if scan.Curr() != q.close {
+		tok.Type = token.Invalid
+		return
+	}
+	scan.Read()
+	tok.Type = token.Ident
+	tok.Quoted = true
+}
+
+// EscapedString gives a tokenizer for string literals in which the backslash
+// escapes the character that follows it. When prefix is not zero, only the
+// literals starting with it (ie: E'...') are concerned and the escape
+// sequences are validated.
+func EscapedString(prefix rune) Tokenizer {
+	return escapedString{
+		prefix: unicode.ToUpper(prefix),
+	}
+}
+
+type escapedString struct {
+	prefix rune
+}
+
+func (e escapedString) Can(curr, peek rune) bool {
+	if e.prefix == 0 {
+		return curr == squote
+	}
+	return unicode.ToUpper(curr) == e.prefix && peek == squote
+}
+
+func (e escapedString) Scan(scan *Scanner, tok *token.Token) {
+	if e.prefix != 0 {
+		scan.Read()
+		tok.Prefix = string(e.prefix)
+	}
+	scan.Read()
+	for !scan.Done() {
+		if scan.Curr() == squote {
+			if scan.Peek() != squote {
+				break
+			}
+			scan.Write()
+			scan.Read()
+		} else if scan.Curr() == backslash {
+			scan.Write()
+			scan.Read()
+			if scan.Done() {
+				break
+			}
+			if reason := e.checkEscape(scan); reason != "" && tok.Reason == "" {
+				tok.Reason = reason
+			}
+		}
+		scan.Write()
+		scan.Read()
+	}
+	tok.Literal = scan.Literal()
+	tok.Type = token.Literal
+	if scan.Curr() != squote {
+		tok.Type = token.Invalid
+		tok.Reason = "unterminated string literal"
+		return
+	}
+	scan.Read()
+	if tok.Reason != "" {
+		tok.Type = token.Invalid
+	}
+}
+
+func (e escapedString) checkEscape(scan *Scanner) string {
+	if e.prefix == 0 {
+		return ""
+	}
+	var size int
+	switch scan.Curr() {
+	case 'x':
+		size = 1
+	case 'u':
+		size = 4
+	case 'U':
+		size = 8
+	default:
+		return ""
+	}
+	if !IsHex(scan.Peek()) {
+		return fmt.Sprintf("invalid escape sequence \\%c: hexadecimal digit expected", scan.Curr())
+	}
+	for i := 0; i < size-1; i++ {
+		scan.Write()
+		scan.Read()
+		if !IsHex(scan.Peek()) {
+			return fmt.Sprintf("incomplete escape sequence: %d hexadecimal digits expected", size)
+		}
+	}
+	return ""
+}
+
+// PrefixedIdent gives a tokenizer for identifiers starting with a character
+// that can not start a regular identifier, such as #temp tables in T-SQL
+func PrefixedIdent(prefix rune) Tokenizer {
+	return prefixedIdent{
+		prefix: prefix,
+	}
+}
+
+type prefixedIdent struct {
+	prefix rune
+}
+
+func (p prefixedIdent) Can(curr, peek rune) bool {
+	return curr == p.prefix && (IsIdentStart(peek) || peek == p.prefix)
+}
+
+func (p prefixedIdent) Scan(scan *Scanner, tok *token.Token) {
+	for !scan.Done() && !IsDelim(scan.Curr()) {
+		scan.Write()
+		scan.Read()
+	}
+	tok.Type = token.Ident
+	tok.Literal = scan.Literal()
+}
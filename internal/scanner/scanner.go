@@ -2,8 +2,12 @@ package scanner
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 	"io"
+	"slices"
 	"strings"
+	"unicode"
 	"unicode/utf8"
 
 	"github.com/midbel/sweet/internal/keywords"
@@ -15,29 +19,53 @@ type Tokenizer interface {
 	Scan(*Scanner, *token.Token)
 }
 
+const (
+	chunkSize = 64 << 10
+	// maxKeep is the size of the input kept behind the current position even
+	// when the current statement has not been fully parsed yet
+	maxKeep = 1 << 20
+)
+
+// Scanner reads its input by chunks. Only the part of the input starting at
+// the last mark is kept in memory, so that large files can be scanned with a
+// bounded amount of memory.
 type Scanner struct {
 	tokens []Tokenizer
-	input  []byte
+	file   string
+	reader io.Reader
+	eof    bool
+	err    error
+	// input holds the bytes of the input starting at offset base. line is the
+	// line number of its first byte.
+	input []byte
+	base  int
+	line  int
+	mark  int
 	cursor
 	old cursor
 
 	keywords keywords.Set
 	str      bytes.Buffer
-	query    bytes.Buffer
+
+	trivia bool
+	last   int
+	syntax []token.Token
 }
 
 func Scan(r io.Reader, keywords keywords.Set) (*Scanner, error) {
-	buf, err := io.ReadAll(r)
-	if err != nil {
-		return nil, err
-	}
-	buf, _ = bytes.CutPrefix(buf, []byte{0xef, 0xbb, 0xbf})
 	s := Scanner{
-		input:    buf,
-		keywords: keywords,
+		reader:   r,
+		line:     1,
+		keywords: keywords.Prepare(),
+	}
+	if n, ok := r.(interface{ Name() string }); ok {
+		s.file = n.Name()
 	}
+	if err := s.fill(); err != nil {
+		return nil, err
+	}
+	s.input, _ = bytes.CutPrefix(s.input, []byte{0xef, 0xbb, 0xbf})
 	s.cursor.Position.Line = 1
-	s.keywords.Prepare()
 	s.Read()
 	s.Skip(IsBlank)
 	return &s, nil
@@ -54,6 +82,19 @@ func (s *Scanner) Clone(r io.Reader) (*Scanner, error) {
 	return other, nil
 }
 
+// File gives the name of the file being scanned if the reader given to the
+// scanner has one
+func (s *Scanner) File() string {
+	return s.file
+}
+
+// Err gives the error that stopped the reading of the input before its end.
+// The scanner gives EOF tokens once it happens, so that the error should be
+// checked when an EOF token is found.
+func (s *Scanner) Err() error {
+	return s.err
+}
+
 func (s *Scanner) Keywords() keywords.Set {
 	return s.keywords
 }
@@ -62,17 +103,49 @@ func (s *Scanner) Register(fn Tokenizer) {
 	s.tokens = append(s.tokens, fn)
 }
 
-func (s *Scanner) Query() string {
-	return s.query.String()
+func (s *Scanner) Scan() token.Token {
+	tok := s.scan()
+	if tok.Type == token.EOF {
+		tok.Offset = s.base + len(s.input)
+		tok.End = tok.Position
+	} else {
+		tok.End = s.cursor.end
+	}
+	if s.trivia {
+		s.keep(&tok)
+	}
+	return tok
+}
+
+// KeepTrivia makes the scanner record every token it produces with the blanks
+// found before it, so that the input can be written back from the tokens
+// without losing a single byte. It should be called before the first token is
+// scanned.
+func (s *Scanner) KeepTrivia() {
+	s.trivia = true
 }
 
-func (s *Scanner) Clear() {
-	s.query.Reset()
+// Tokens gives the tokens recorded since KeepTrivia has been called. The last
+// one is the EOF token carrying the trailing blanks once the input has been
+// fully scanned.
+func (s *Scanner) Tokens() []token.Token {
+	return s.syntax
 }
 
-func (s *Scanner) Scan() token.Token {
+func (s *Scanner) keep(tok *token.Token) {
+	if n := len(s.syntax); n > 0 && s.syntax[n-1].Type == token.EOF {
+		return
+	}
+	tok.Leading = s.Text(s.last, tok.Offset)
+	tok.Raw = s.Text(tok.Offset, tok.End.Offset)
+	s.last = max(s.last, tok.End.Offset)
+	s.syntax = append(s.syntax, *tok)
+}
+
+func (s *Scanner) scan() token.Token {
 	defer s.Reset()
 	s.Skip(IsBlank)
+	s.Save()
 
 	var tok token.Token
 	tok.Position = s.cursor.Position
@@ -89,7 +162,9 @@ func (s *Scanner) Scan() token.Token {
 	switch {
 	case IsComment(s.char, s.Peek()):
 		s.scanComment(&tok)
-	case IsLetter(s.char):
+	case IsStringPrefix(s.char, s.Peek()):
+		s.scanPrefixedString(&tok)
+	case IsIdentStart(s.char):
 		s.scanIdent(&tok)
 	case IsIdentQ(s.char):
 		s.scanQuotedIdent(&tok)
@@ -107,6 +182,8 @@ func (s *Scanner) Scan() token.Token {
 		s.scanPlaceholder(&tok)
 	default:
 		tok.Type = token.Invalid
+		tok.Reason = fmt.Sprintf("unexpected character %q", s.char)
+		s.Read()
 	}
 	return tok
 }
@@ -123,7 +200,9 @@ func (s *Scanner) scanPlaceholder(tok *token.Token) {
 	case dollar:
 		s.Read()
 		s.scanNumber(tok)
-		tok.Type = token.PositionHolder
+		if tok.Type == token.Number {
+			tok.Type = token.PositionHolder
+		}
 	default:
 		tok.Type = token.Invalid
 	}
@@ -169,8 +248,10 @@ func (s *Scanner) scanQuotedIdent(tok *token.Token) {
 	}
 	tok.Type = token.Ident
 	tok.Literal = s.Literal()
+	tok.Quoted = true
 	if !IsIdentQ(s.char) {
 		tok.Type = token.Invalid
+		tok.Reason = "unterminated quoted identifier"
 	}
 	if tok.Type == token.Ident {
 		s.Read()
@@ -238,27 +319,155 @@ func (s *Scanner) scanString(tok *token.Token) {
 	tok.Type = token.Literal
 	if !IsLiteralQ(s.char) {
 		tok.Type = token.Invalid
+		tok.Reason = "unterminated string literal"
 	}
 	if tok.Type == token.Literal {
 		s.Read()
 	}
 }
 
+func (s *Scanner) scanPrefixedString(tok *token.Token) {
+	s.Save()
+	prefix := string(unicode.ToUpper(s.char))
+	s.Read()
+	if prefix == "U" {
+		s.Read()
+		if !IsLiteralQ(s.char) {
+			s.Restore()
+			s.scanIdent(tok)
+			return
+		}
+		prefix += "&"
+	}
+	s.scanString(tok)
+	if tok.Type == token.Invalid {
+		return
+	}
+	tok.Prefix = prefix
+	switch prefix {
+	case "X":
+		if r, ok := findInvalid(tok.Literal, IsHex); ok {
+			tok.Type = token.Invalid
+			tok.Reason = fmt.Sprintf("invalid hexadecimal digit %q in X'' literal", r)
+		}
+	case "B":
+		if r, ok := findInvalid(tok.Literal, IsBinary); ok {
+			tok.Type = token.Invalid
+			tok.Reason = fmt.Sprintf("invalid binary digit %q in B'' literal", r)
+		}
+	case "U&":
+		if err := checkUnicodeEscapes(tok.Literal); err != "" {
+			tok.Type = token.Invalid
+			tok.Reason = err
+		}
+	default:
+	}
+}
+
+func findInvalid(str string, accept func(rune) bool) (rune, bool) {
+	for _, r := range str {
+		if !accept(r) {
+			return r, true
+		}
+	}
+	return 0, false
+}
+
+// checkUnicodeEscapes verifies the escape sequences of a U&” literal: \XXXX
+// or \+XXXXXX with hexadecimal digits, or \\ for the backslash itself
+func checkUnicodeEscapes(str string) string {
+	for i := 0; i < len(str); i++ {
+		if str[i] != backslash {
+			continue
+		}
+		i++
+		size := 4
+		switch {
+		case i < len(str) && str[i] == backslash:
+			continue
+		case i < len(str) && str[i] == plus:
+			i++
+			size = 6
+		}
+		if i+size > len(str) {
+			return "incomplete unicode escape sequence"
+		}
+		for _, r := range str[i : i+size] {
+			if !IsHex(r) {
+				return fmt.Sprintf("invalid hexadecimal digit %q in unicode escape sequence", r)
+			}
+		}
+		i += size - 1
+	}
+	return ""
+}
+
 func (s *Scanner) scanNumber(tok *token.Token) {
-	for IsDigit(s.char) && !s.Done() {
+	tok.Type = token.Number
+	if k := unicode.ToLower(s.Peek()); s.char == '0' && (k == 'x' || k == 'b' || k == 'o') {
 		s.Write()
 		s.Read()
-	}
-	if s.char == dot {
 		s.Write()
 		s.Read()
-		for IsDigit(s.char) && !s.Done() {
+		accept := IsHex
+		switch k {
+		case 'b':
+			accept = IsBinary
+		case 'o':
+			accept = IsOctal
+		}
+		if !accept(s.char) {
+			tok.Type = token.Invalid
+			tok.Reason = "missing digits after base prefix"
+		}
+		s.scanDigits(tok, accept)
+	} else {
+		s.scanDigits(tok, IsDigit)
+		if s.char == dot {
+			s.Write()
+			s.Read()
+			s.scanDigits(tok, IsDigit)
+		}
+		if s.char == 'e' || s.char == 'E' {
+			s.Write()
+			s.Read()
+			if s.char == plus || s.char == minus {
+				s.Write()
+				s.Read()
+			}
+			if !IsDigit(s.char) && tok.Type != token.Invalid {
+				tok.Type = token.Invalid
+				tok.Reason = "missing digits in exponent"
+			}
+			s.scanDigits(tok, IsDigit)
+		}
+	}
+	if IsIdentStart(s.char) {
+		if tok.Type != token.Invalid {
+			tok.Type = token.Invalid
+			tok.Reason = fmt.Sprintf("invalid character %q in number", s.char)
+		}
+		for IsIdentStart(s.char) || IsDigit(s.char) {
 			s.Write()
 			s.Read()
 		}
 	}
 	tok.Literal = s.Literal()
-	tok.Type = token.Number
+}
+
+// scanDigits reads the digits accepted by the given function. Underscores can
+// be used to separate groups of digits but only between two digits
+func (s *Scanner) scanDigits(tok *token.Token, accept func(rune) bool) {
+	var prev rune
+	for !s.Done() && (accept(s.char) || s.char == underscore) {
+		if s.char == underscore && (!accept(prev) || !accept(s.Peek())) && tok.Type != token.Invalid {
+			tok.Type = token.Invalid
+			tok.Reason = "digit separator must be placed between two digits"
+		}
+		prev = s.char
+		s.Write()
+		s.Read()
+	}
 }
 
 func (s *Scanner) scanPunct(tok *token.Token) {
@@ -367,25 +576,25 @@ func (s *Scanner) Done() bool {
 }
 
 func (s *Scanner) Read() {
-	if s.curr >= len(s.input) {
+	if !s.Done() {
+		s.end = s.Position
+		s.end.Column++
+		s.end.Offset = s.next
+	}
+	s.fill()
+	if s.next-s.base >= len(s.input) {
 		s.char = utf8.RuneError
 		return
 	}
-	if s.old.char == semicolon {
-		s.query.Reset()
-	}
-	r, n := utf8.DecodeRune(s.input[s.next:])
+	r, n := utf8.DecodeRune(s.input[s.next-s.base:])
 	if r == utf8.RuneError {
 		s.char = r
-		s.next = len(s.input)
+		s.next = s.base + len(s.input)
+		s.eof = true
 		return
 	}
-
-	if r != space || s.char != r {
-		s.query.WriteRune(r)
-	}
-
 	s.char, s.curr, s.next = r, s.next, s.next+n
+	s.Position.Offset = s.curr
 	if s.char == nl {
 		s.Position.Line++
 		s.Position.Column = -1
@@ -393,12 +602,116 @@ func (s *Scanner) Read() {
 	s.Position.Column++
 }
 
+// Text gives the input found between the given offsets. The part of the
+// input that is no longer kept by the scanner is left out.
+func (s *Scanner) Text(start, end int) string {
+	size := len(s.input)
+	start = max(0, min(start-s.base, size))
+	end = max(start, min(end-s.base, size))
+	return string(s.input[start:end])
+}
+
+// Source gives the input kept by the scanner up to the given offset and the
+// line number of its first line
+func (s *Scanner) Source(end int) (string, int) {
+	return s.Text(s.base, end), s.line
+}
+
+// Mark tells the scanner that the input found before the given offset is not
+// needed anymore. It is usually called at the start of each statement.
+func (s *Scanner) Mark(offset int) {
+	s.mark = max(s.mark, offset)
+}
+
+// SkipLines skips the rest of the current line and all the following lines
+// up to and including the first line equal to end. It is used to skip the
+// data given inline after a statement such as COPY FROM STDIN.
+func (s *Scanner) SkipLines(end string) {
+	s.readLines(end, false)
+}
+
+// ReadLines is like SkipLines but gives the lines found before the one equal
+// to end
+func (s *Scanner) ReadLines(end string) []string {
+	return s.readLines(end, true)
+}
+
+func (s *Scanner) readLines(end string, keep bool) []string {
+	var list []string
+	for !s.Done() && !IsNL(s.char) {
+		s.Read()
+	}
+	for !s.Done() {
+		s.Read()
+		s.Reset()
+		for !s.Done() && !IsNL(s.char) {
+			s.Write()
+			s.Read()
+		}
+		line := strings.TrimRight(s.Literal(), "\r")
+		if line == end {
+			break
+		}
+		if keep {
+			list = append(list, line)
+		}
+	}
+	s.Reset()
+	s.Read()
+	return list
+}
+
+func (s *Scanner) fill() error {
+	if s.eof || len(s.input)-(s.next-s.base) >= utf8.UTFMax {
+		return nil
+	}
+	s.compact()
+	for !s.eof && len(s.input)-(s.next-s.base) < utf8.UTFMax {
+		s.input = slices.Grow(s.input, chunkSize)
+		n, err := s.reader.Read(s.input[len(s.input):cap(s.input)])
+		s.input = s.input[:len(s.input)+n]
+		if err != nil {
+			s.eof = true
+			if !errors.Is(err, io.EOF) {
+				s.err = err
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// compact discards the part of the input that is not needed anymore: the
+// bytes before the mark, the token being scanned and, when trivia are kept,
+// the end of the last recorded token. The input is cut at a line boundary
+// when possible.
+func (s *Scanner) compact() {
+	keep := s.mark
+	if s.trivia {
+		keep = min(keep, s.last)
+	} else {
+		keep = max(keep, s.curr-maxKeep)
+	}
+	keep = min(keep, s.old.curr, s.curr)
+	if keep-s.base < chunkSize {
+		return
+	}
+	drop := s.input[:keep-s.base]
+	if i := bytes.LastIndexByte(drop, '\n'); i >= 0 && len(drop)-i < maxKeep {
+		drop = drop[:i+1]
+	}
+	s.line += bytes.Count(drop, []byte{'\n'})
+	s.base += len(drop)
+	s.input = s.input[:copy(s.input, s.input[len(drop):])]
+}
+
 func (s *Scanner) Curr() rune {
 	return s.char
 }
 
 func (s *Scanner) Peek() rune {
-	r, _ := utf8.DecodeRune(s.input[s.next:])
+	s.fill()
+	r, _ := utf8.DecodeRune(s.input[s.next-s.base:])
 	return r
 }
 
@@ -428,4 +741,6 @@ type cursor struct {
 	curr int
 	next int
 	token.Position
+	// end is the position right after the last character read
+	end token.Position
 }
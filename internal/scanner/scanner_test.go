@@ -0,0 +1,160 @@
+package scanner_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/midbel/sweet/internal/keywords"
+	"github.com/midbel/sweet/internal/scanner"
+	"github.com/midbel/sweet/internal/token"
+)
+
+func TestScanLiterals(t *testing.T) {
+	tests := []struct {
+		Input   string
+		Type    rune
+		Literal string
+		Prefix  string
+		Reason  string
+	}{
+		{Input: "prénom", Type: token.Ident, Literal: "prénom"},
+		{Input: "_tmp", Type: token.Ident, Literal: "_tmp"},
+		{Input: "col$1", Type: token.Ident, Literal: "col$1"},
+		{Input: "1e-5", Type: token.Number, Literal: "1e-5"},
+		{Input: "2.5E10", Type: token.Number, Literal: "2.5E10"},
+		{Input: "0xFF", Type: token.Number, Literal: "0xFF"},
+		{Input: "0b1010", Type: token.Number, Literal: "0b1010"},
+		{Input: "1_000_000", Type: token.Number, Literal: "1_000_000"},
+		{Input: "X'0A'", Type: token.Literal, Literal: "0A", Prefix: "X"},
+		{Input: "N'été'", Type: token.Literal, Literal: "été", Prefix: "N"},
+		{Input: "U&'d\\0061t'", Type: token.Literal, Literal: "d\\0061t", Prefix: "U&"},
+		{Input: "1e+", Type: token.Invalid, Reason: "missing digits in exponent"},
+		{Input: "1__0", Type: token.Invalid, Reason: "digit separator must be placed between two digits"},
+		{Input: "0x", Type: token.Invalid, Reason: "missing digits after base prefix"},
+		{Input: "12abc", Type: token.Invalid, Reason: "invalid character 'a' in number"},
+		{Input: "X'0G'", Type: token.Invalid, Reason: "invalid hexadecimal digit 'G' in X'' literal"},
+		{Input: "B'102'", Type: token.Invalid, Reason: "invalid binary digit '2' in B'' literal"},
+		{Input: "U&'\\00zz'", Type: token.Invalid, Reason: "invalid hexadecimal digit 'z' in unicode escape sequence"},
+		{Input: "'abc", Type: token.Invalid, Reason: "unterminated string literal"},
+	}
+	for _, c := range tests {
+		tok := scanFirst(t, c.Input)
+		if tok.Type != c.Type {
+			t.Errorf("%s: token type mismatched! want %s, got %s", c.Input, token.Token{Symbol: token.Symbol{Type: c.Type}}, tok)
+			continue
+		}
+		if c.Type == token.Invalid {
+			if tok.Reason != c.Reason {
+				t.Errorf("%s: reason mismatched! want %q, got %q", c.Input, c.Reason, tok.Reason)
+			}
+			continue
+		}
+		if tok.Literal != c.Literal {
+			t.Errorf("%s: literal mismatched! want %s, got %s", c.Input, c.Literal, tok.Literal)
+		}
+		if tok.Prefix != c.Prefix {
+			t.Errorf("%s: prefix mismatched! want %s, got %s", c.Input, c.Prefix, tok.Prefix)
+		}
+	}
+}
+
+func TestScanEscapedString(t *testing.T) {
+	tests := []struct {
+		Input   string
+		Prefix  rune
+		Type    rune
+		Literal string
+	}{
+		{Input: `'it\'s'`, Type: token.Literal, Literal: `it\'s`},
+		{Input: `'a''b'`, Type: token.Literal, Literal: `a''b`},
+		{Input: `E'\x41\n'`, Prefix: 'E', Type: token.Literal, Literal: `\x41\n`},
+		{Input: `E'\u00'`, Prefix: 'E', Type: token.Invalid},
+		{Input: `'abc\'`, Type: token.Invalid},
+	}
+	for _, c := range tests {
+		tok := scanFirst(t, c.Input, scanner.EscapedString(c.Prefix))
+		if tok.Type != c.Type {
+			t.Errorf("%s: token type mismatched! got %s", c.Input, tok)
+			continue
+		}
+		if c.Type == token.Literal && tok.Literal != c.Literal {
+			t.Errorf("%s: literal mismatched! want %s, got %s", c.Input, c.Literal, tok.Literal)
+		}
+	}
+}
+
+func TestScanStream(t *testing.T) {
+	var (
+		str   strings.Builder
+		lines = 100000
+	)
+	for i := 0; i < lines; i++ {
+		fmt.Fprintf(&str, "select 'é%d', col from t;\n", i)
+	}
+	scan, err := scanner.Scan(iotest.HalfReader(strings.NewReader(str.String())), keywords.Set{})
+	if err != nil {
+		t.Fatalf("fail to create scanner: %s", err)
+	}
+	var (
+		count int
+		start int
+		last  token.Token
+	)
+	for {
+		tok := scan.Scan()
+		if tok.Type == token.EOF {
+			break
+		}
+		if tok.Type == token.EOL {
+			count++
+			scan.Mark(start)
+			start = tok.End.Offset
+		}
+		last = tok
+	}
+	if count != lines {
+		t.Fatalf("statements count mismatched! want %d, got %d", lines, count)
+	}
+	if last.Line != lines || last.Column != 28 {
+		t.Errorf("position mismatched! got %s", last.Position)
+	}
+	want := fmt.Sprintf("select 'é%d', col from t;", lines-1)
+	if got := scan.Text(last.End.Offset-len(want), last.End.Offset); got != want {
+		t.Errorf("text mismatched! want %q, got %q", want, got)
+	}
+	if src, line := scan.Source(last.End.Offset); line == 1 || len(src) >= str.Len() {
+		t.Errorf("input fully kept in memory (%d bytes from line %d)", len(src), line)
+	}
+}
+
+func TestScanReadError(t *testing.T) {
+	var (
+		errRead = errors.New("read error")
+		input   = io.MultiReader(strings.NewReader("select a from t;\nselect b"), iotest.ErrReader(errRead))
+	)
+	scan, err := scanner.Scan(input, keywords.Set{})
+	if err != nil {
+		t.Fatalf("fail to create scanner: %s", err)
+	}
+	for tok := scan.Scan(); tok.Type != token.EOF; tok = scan.Scan() {
+	}
+	if err := scan.Err(); !errors.Is(err, errRead) {
+		t.Errorf("read error expected! got %v", err)
+	}
+}
+
+func scanFirst(t *testing.T, str string, tokenizers ...scanner.Tokenizer) token.Token {
+	t.Helper()
+	scan, err := scanner.Scan(strings.NewReader(str), keywords.Set{})
+	if err != nil {
+		t.Fatalf("fail to create scanner: %s", err)
+	}
+	for _, tz := range tokenizers {
+		scan.Register(tz)
+	}
+	return scan.Scan()
+}
@@ -6,8 +6,10 @@ import (
 )
 
 type Position struct {
-	Line   int
-	Column int
+	Line   int `json:"line"`
+	Column int `json:"column"`
+	// Offset is the position in bytes from the start of the input
+	Offset int `json:"offset"`
 }
 
 func (p Position) String() string {
@@ -17,6 +19,19 @@ func (p Position) String() string {
 type Token struct {
 	Symbol
 	Position
+	// End is the position right after the last character of the token
+	End Position
+	// Quoted is set when the token is an identifier written between quotes
+	Quoted bool
+	// Prefix is the prefix of a string literal (N, X, B, U&, E)
+	Prefix string
+	// Reason explains why the scanner produced an invalid token
+	Reason string
+	// Leading is the text found between the previous token and this one and
+	// Raw the token as written in the input. Both are only set when the
+	// scanner keeps the trivia.
+	Leading string
+	Raw     string
 }
 
 func (t Token) IsJoin() bool {
@@ -101,6 +116,8 @@ func (t Token) String() string {
 		return "<concat>"
 	case Arrow:
 		return "<arrow>"
+	case Assign:
+		return "<assign>"
 	case Eq:
 		return "<equal>"
 	case Ne:
@@ -132,6 +149,9 @@ func (t Token) String() string {
 	case Comment:
 		prefix = "comment"
 	case Invalid:
+		if t.Reason != "" {
+			return fmt.Sprintf("<invalid>(%s)", t.Reason)
+		}
 		return "<invalid>"
 	default:
 		prefix = "unknown"
@@ -39,5 +39,6 @@ const (
 	ModAssign
 	Concat
 	Arrow
+	Assign
 	Invalid
 )